@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// findAllowedFile returns the real path of the allow_files_paths file a requested
+// name refers to, matching its name or trailing path components the same way as
+// files in the configured directories. Only the listed files themselves can be
+// found, never other files next to them, and symlinks are resolved so the file
+// read is the one the listed path points to.
+func findAllowedFile(filename string) (string, bool) {
+	candidates := nameCandidates(filename)
+	for _, allowed := range config.AllowFilesPaths {
+		slashPath := filepath.ToSlash(allowed)
+		if !slices.ContainsFunc(candidates, func(candidate string) bool {
+			return hasPathSuffix(slashPath, candidate)
+		}) {
+			continue
+		}
+
+		realPath, err := filepath.EvalSymlinks(allowed)
+		if err != nil {
+			logger.Debug("could not resolve allowed file", "path", allowed, "error", err)
+			continue
+		}
+		if info, err := os.Stat(realPath); err != nil || !info.Mode().IsRegular() {
+			logger.Debug("allowed file is not a regular file", "path", allowed)
+			continue
+		}
+		if !config.isMarkdownFile(realPath) {
+			logger.Debug("allowed file does not resolve to a markdown file", "path", allowed, "real_path", realPath)
+			continue
+		}
+		return realPath, true
+	}
+	return "", false
+}
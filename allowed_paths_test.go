@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleReadMarkdownFileResourceAllowFilesPaths(t *testing.T) {
+	outside := t.TempDir()
+	appDir := filepath.Join(outside, "app")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		"README.md":  "# App\n",
+		"private.md": "# Private\n",
+		"notes.txt":  "not markdown\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(appDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	link := filepath.Join(outside, "linked.md")
+	if err := os.Symlink(filepath.Join(appDir, "README.md"), link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	badLink := filepath.Join(outside, "disguised.md")
+	if err := os.Symlink(filepath.Join(appDir, "notes.txt"), badLink); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	setupFileIndexTest(t, Config{
+		Directories:     []string{"test/dir2"},
+		AllowFilesPaths: []string{filepath.Join(appDir, "README.md"), link, badLink},
+	})
+
+	tests := []struct {
+		name        string
+		uri         string
+		wantContent string
+		wantCode    string
+	}{
+		{name: "allowed file by name", uri: "file://README.md", wantContent: "# App\n"},
+		{name: "allowed file without extension", uri: "file://README", wantContent: "# App\n"},
+		{name: "allowed file by path suffix", uri: "file://app/README.md", wantContent: "# App\n"},
+		{name: "allowed symlink resolved", uri: "file://linked.md", wantContent: "# App\n"},
+		{name: "configured directory still read", uri: "file://cat.md", wantContent: "# Cat\n"},
+		{name: "sibling of allowed file", uri: "file://private.md", wantCode: ErrorCodeFileNotFound},
+		{name: "symlink to non-markdown file", uri: "file://disguised.md", wantCode: ErrorCodeFileNotFound},
+		{name: "labelled directory excludes allowed files", uri: "markdown://dir2/README.md", wantCode: ErrorCodeFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}})
+
+			if tt.wantCode != "" {
+				if err == nil {
+					t.Fatalf("Expected error but got %v", result)
+				}
+				if !strings.Contains(err.Error(), tt.wantCode) {
+					t.Errorf("Expected error code %s, got %v", tt.wantCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if text := result[0].(mcp.TextResourceContents).Text; !strings.HasPrefix(text, tt.wantContent) {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, text)
+			}
+		})
+	}
+}
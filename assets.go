@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultHeavyNoteThreshold is the total image size in bytes above which a note is heavy
+const DefaultHeavyNoteThreshold = 1024 * 1024
+
+type localAsset struct {
+	Target string
+	Path   string
+}
+
+type heavyNote struct {
+	Name       string `json:"name"`
+	TotalBytes int64  `json:"total_bytes"`
+	Images     int    `json:"images"`
+}
+
+func handleHeavyNotes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pageSize := extractPageSizeParam(req.Params.Arguments)
+	threshold, err := extractThresholdParam(req.Params.Arguments)
+	if err != nil {
+		return toolErrorResult(err), nil
+	}
+
+	logger.Debug("heavy_notes called", "threshold_bytes", threshold, "page_size", pageSize)
+
+	if pageSize <= 0 || pageSize > config.MaxPageSize {
+		pageSize = defaultPageSize()
+	}
+
+	notes := []heavyNote{}
+	for _, file := range collectAllMarkdownFiles() {
+		content, err := readFileContent(file)
+		if err != nil {
+			logger.Debug("heavy_notes could not read file", "file", file, "error", err)
+			continue
+		}
+
+		note := heavyNote{Name: markdownName(file)}
+		for _, asset := range extractLocalImages(file, string(content)) {
+			info, err := os.Stat(asset.Path)
+			if err != nil {
+				logger.Debug("heavy_notes could not stat image", "image", asset.Target, "error", err)
+				continue
+			}
+			note.TotalBytes += info.Size()
+			note.Images++
+		}
+
+		if note.TotalBytes > threshold {
+			notes = append(notes, note)
+		}
+	}
+
+	// Heaviest first, ties broken by name for a stable order
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].TotalBytes != notes[j].TotalBytes {
+			return notes[i].TotalBytes > notes[j].TotalBytes
+		}
+		return notes[i].Name < notes[j].Name
+	})
+
+	if len(notes) > pageSize {
+		notes = notes[:pageSize]
+	}
+
+	result := map[string]any{
+		"notes":           notes,
+		"count":           len(notes),
+		"threshold_bytes": threshold,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("heavy_notes failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal heavy notes: %w", err)), nil
+	}
+
+	logger.Debug("heavy_notes completed successfully", "notes", len(notes))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func extractThresholdParam(arguments any) (int64, error) {
+	argsMap, ok := arguments.(map[string]any)
+	if !ok {
+		return DefaultHeavyNoteThreshold, nil
+	}
+
+	thresholdParam, exists := argsMap["threshold_bytes"]
+	if !exists || thresholdParam == nil {
+		return DefaultHeavyNoteThreshold, nil
+	}
+
+	var threshold int64
+	switch value := thresholdParam.(type) {
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid threshold_bytes %q: must be a non-negative integer", value)
+		}
+		threshold = parsed
+	case float64:
+		if value != math.Trunc(value) || value >= math.MaxInt64 {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid threshold_bytes %v: must be a non-negative integer", value)
+		}
+		threshold = int64(value)
+	case int:
+		threshold = int64(value)
+	case int64:
+		threshold = value
+	case json.Number:
+		parsed, err := strconv.ParseInt(value.String(), 10, 64)
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid threshold_bytes %s: must be a non-negative integer", value)
+		}
+		threshold = parsed
+	default:
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid threshold_bytes %v: must be a non-negative integer", value)
+	}
+
+	if threshold < 0 {
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid threshold_bytes %d: must be a non-negative integer", threshold)
+	}
+	return threshold, nil
+}
+
+// extractLocalImages returns the images embedded in a note that refer to local
+// files, resolved relative to the note's directory
+func extractLocalImages(notePath, content string) []localAsset {
+	var assets []localAsset
+	for _, link := range extractLinks(content) {
+		if !link.Image || link.Type != linkTypeInternal {
+			continue
+		}
+		path, ok := resolveLinkTarget(notePath, link.Target)
+		if !ok {
+			continue
+		}
+		assets = append(assets, localAsset{Target: link.Target, Path: path})
+	}
+	return assets
+}
+
+// resolveLinkTarget resolves a relative link target in a note to a file path. Targets
+// that are absolute, anchors only or resolve outside the configured directories,
+// including through symlinked directories, are rejected.
+func resolveLinkTarget(notePath, target string) (string, bool) {
+	target, _, _ = strings.Cut(target, "#")
+	target, _, _ = strings.Cut(target, "?")
+	if target == "" {
+		return "", false
+	}
+
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+
+	if filepath.IsAbs(target) || filepath.VolumeName(target) != "" {
+		logger.Debug("rejected absolute link target", "target", target)
+		return "", false
+	}
+
+	path := filepath.Join(filepath.Dir(notePath), filepath.FromSlash(target))
+	if !isWithinConfiguredDirs(path) || !resolvesWithinConfiguredDirs(path) {
+		logger.Debug("rejected link target outside configured directories", "target", target)
+		return "", false
+	}
+
+	return path, true
+}
+
+// isWithinConfiguredDirs reports whether the path lies under one of the configured directories
+func isWithinConfiguredDirs(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	for _, dir := range config.Directories {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, absPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
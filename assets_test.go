@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleHeavyNotes(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/heavy"}, MaxPageSize: DefaultMaxPageSize}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantError bool
+		wantNotes []heavyNote
+	}{
+		{
+			name:      "notes above threshold",
+			arguments: map[string]any{"threshold_bytes": float64(2000)},
+			wantNotes: []heavyNote{
+				{Name: "gallery.md", TotalBytes: 4500, Images: 2},
+				{Name: "diagram.md", TotalBytes: 3000, Images: 2},
+			},
+		},
+		{
+			name:      "threshold as string",
+			arguments: map[string]any{"threshold_bytes": "3000"},
+			wantNotes: []heavyNote{
+				{Name: "gallery.md", TotalBytes: 4500, Images: 2},
+			},
+		},
+		{
+			name:      "default threshold",
+			arguments: map[string]any{},
+			wantNotes: []heavyNote{},
+		},
+		{
+			name:      "threshold as integer",
+			arguments: map[string]any{"threshold_bytes": 3000},
+			wantNotes: []heavyNote{
+				{Name: "gallery.md", TotalBytes: 4500, Images: 2},
+			},
+		},
+		{
+			name:      "threshold as int64",
+			arguments: map[string]any{"threshold_bytes": int64(3000)},
+			wantNotes: []heavyNote{
+				{Name: "gallery.md", TotalBytes: 4500, Images: 2},
+			},
+		},
+		{
+			name:      "invalid threshold",
+			arguments: map[string]any{"threshold_bytes": "lots"},
+			wantError: true,
+		},
+		{
+			name:      "negative threshold",
+			arguments: map[string]any{"threshold_bytes": float64(-1)},
+			wantError: true,
+		},
+		{
+			name:      "fractional threshold",
+			arguments: map[string]any{"threshold_bytes": 2000.5},
+			wantError: true,
+		},
+		{
+			name:      "negative threshold as string",
+			arguments: map[string]any{"threshold_bytes": "-1"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "heavy_notes",
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handleHeavyNotes(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+
+			if result.IsError {
+				t.Fatalf("Tool returned error: %v", result.Content)
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", result.Content[0])
+			}
+
+			var response struct {
+				Notes []heavyNote `json:"notes"`
+			}
+			if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+
+			if !reflect.DeepEqual(response.Notes, tt.wantNotes) {
+				t.Errorf("Expected notes %+v, got %+v", tt.wantNotes, response.Notes)
+			}
+		})
+	}
+}
+
+func TestResolveLinkTarget(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/heavy"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		target string
+		wantOK bool
+	}{
+		{"images/large.png", true},
+		{"./images/large.png#fragment", true},
+		{"images/my%20image.png", true},
+		{"../../README.md", false},
+		{"/etc/passwd", false},
+		{"#heading", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			_, ok := resolveLinkTarget("test/heavy/gallery.md", tt.target)
+			if ok != tt.wantOK {
+				t.Errorf("resolveLinkTarget(%q) ok = %v, want %v", tt.target, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExtractLocalImagesSymlinkedDirectory(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "photo.png"), make([]byte, 2048), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "assets")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	setupFileIndexTest(t, Config{Directories: []string{root}})
+
+	images := extractLocalImages(filepath.Join(root, "note.md"), "![Photo](assets/photo.png)\n")
+	if len(images) != 0 {
+		t.Errorf("Expected images behind a symlinked directory outside the root to be skipped, got %+v", images)
+	}
+}
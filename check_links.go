@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at
+const sniffLength = 512
+
+type linkCheck struct {
+	Text     string `json:"text"`
+	Target   string `json:"target"`
+	Image    bool   `json:"image,omitempty"`
+	Line     int    `json:"line"`
+	Exists   bool   `json:"exists"`
+	MimeType string `json:"mime_type,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func handleCheckLinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("check_links called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	notePath, err := resolveMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("check_links failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	content, err := readFileContent(notePath)
+	if err != nil {
+		logger.Debug("check_links failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	checks := []linkCheck{}
+	missing := 0
+	for _, link := range extractLinks(string(content)) {
+		// Anchors within the note aren't files
+		if link.Type != linkTypeInternal || strings.HasPrefix(link.Target, "#") {
+			continue
+		}
+
+		check := checkLinkTarget(notePath, link.Target)
+		check.Text = link.Text
+		check.Image = link.Image
+		check.Line = link.Line
+		if !check.Exists {
+			missing++
+		}
+		checks = append(checks, check)
+	}
+
+	result := map[string]any{
+		"links":   checks,
+		"count":   len(checks),
+		"missing": missing,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("check_links failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal links: %w", err)), nil
+	}
+
+	logger.Debug("check_links completed successfully", "links_checked", len(checks), "missing", missing)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// checkLinkTarget reports whether a link target in a note exists as a file within
+// the configured directories, and its MIME type detected from its first bytes
+func checkLinkTarget(notePath, target string) linkCheck {
+	check := linkCheck{Target: target}
+
+	path, ok := resolveLinkTarget(notePath, target)
+	if !ok {
+		check.Error = "link target is outside the configured directories"
+		return check
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return check
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if err := config.checkSymlink(path); err != nil {
+			check.Error = err.Error()
+			return check
+		}
+	} else if !info.Mode().IsRegular() {
+		check.Error = "link target is not a file"
+		return check
+	}
+
+	mimeType, err := detectMimeType(path)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	check.Exists = true
+	check.MimeType = mimeType
+	return check
+}
+
+// detectMimeType sniffs the MIME type of a file from its first bytes without
+// reading the rest of it
+func detectMimeType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
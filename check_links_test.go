@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleCheckLinks(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/check_links"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"filename": "note"}
+	result, err := handleCheckLinks(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	var response struct {
+		Links   []linkCheck `json:"links"`
+		Count   int         `json:"count"`
+		Missing int         `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	want := []linkCheck{
+		{Text: "Diagram", Target: "assets/diagram.png", Image: true, Line: 3, Exists: true, MimeType: "image/png"},
+		{Text: "paper", Target: "assets/paper.pdf", Line: 5, Exists: true, MimeType: "application/pdf"},
+		{Text: "summary", Target: "summary.md", Line: 5, Exists: true, MimeType: "text/plain; charset=utf-8"},
+		{Text: "Missing chart", Target: "assets/chart.png", Image: true, Line: 7},
+		{Text: "Escape", Target: "../../go.mod", Line: 11, Error: "link target is outside the configured directories"},
+	}
+	if !reflect.DeepEqual(response.Links, want) {
+		t.Errorf("Expected links %+v, got %+v", want, response.Links)
+	}
+	if response.Count != len(want) || response.Missing != 2 {
+		t.Errorf("Expected count %d and 2 missing, got %d and %d", len(want), response.Count, response.Missing)
+	}
+
+	req.Params.Arguments = map[string]any{"filename": "../note.md"}
+	result, err = handleCheckLinks(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error for a directory traversal attempt")
+	}
+}
+
+func TestCheckLinkTargetSymlinkedDirectory(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "hostname"), []byte("secret-host\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "images"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "images", "chart.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "assets")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "images"), filepath.Join(root, "pictures")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	setupFileIndexTest(t, Config{Directories: []string{root}, FollowSymlinks: true})
+
+	notePath := filepath.Join(root, "note.md")
+	tests := []struct {
+		target     string
+		wantExists bool
+		wantError  string
+	}{
+		{target: "assets/hostname", wantError: "link target is outside the configured directories"},
+		{target: "assets/missing.png", wantError: "link target is outside the configured directories"},
+		{target: "pictures/chart.png", wantExists: true},
+		{target: "images/chart.png", wantExists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			check := checkLinkTarget(notePath, tt.target)
+			if check.Exists != tt.wantExists || check.Error != tt.wantError {
+				t.Errorf("Expected exists %v and error %q, got %+v", tt.wantExists, tt.wantError, check)
+			}
+			if check.MimeType != "" && !tt.wantExists {
+				t.Errorf("Expected no MIME type for a rejected target, got %s", check.MimeType)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+)
+
+// ChecksumMaxPageSize caps the page size of find_markdown_files when checksums
+// are included, as every file on the page is read to hash it
+const ChecksumMaxPageSize = 100
+
+// addChecksums adds the sha256 checksum of each file to its find result, or the
+// error reading it, hashing the files on a pool of search_concurrency workers.
+// Files are left without a checksum once the context is done.
+func addChecksums(ctx context.Context, fileInfos []map[string]any, files []indexedFile) {
+	checksums := make([]string, len(files))
+	errs := make([]error, len(files))
+
+	workers := min(searchConcurrency(), len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() == nil {
+					checksums[i], errs[i] = fileChecksum(files[i].Path)
+				}
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, fileInfo := range fileInfos {
+		switch {
+		case errs[i] != nil:
+			fileInfo["error"] = errs[i].Error()
+		case checksums[i] != "":
+			fileInfo["checksum"] = checksums[i]
+		}
+	}
+}
+
+// fileChecksum returns the hex encoded sha256 of the bytes of the file as stored,
+// so a gzip compressed file is hashed without decompressing it
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MinCompressSize is the smallest response body in bytes that is gzip compressed,
+// smaller bodies are sent as is as compressing them isn't worth the overhead
+const MinCompressSize = 1024
+
+// compressResponses reports whether HTTP and SSE responses are gzip compressed,
+// which they are unless compress_responses is set to false
+func compressResponses() bool {
+	return config.CompressResponses == nil || *config.CompressResponses
+}
+
+// withCompression gzip compresses responses for clients advertising gzip support
+// in Accept-Encoding. Responses are buffered until MinCompressSize bytes are
+// written, except event streams which are compressed from the start and flushed
+// event by event.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		// q=0 means not acceptable
+		if _, quality, ok := strings.Cut(params, "q="); ok {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(quality), 64); err == nil && q == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter decides whether to compress once enough of the body is
+// written to know it's worth it, or when the handler flushes or finishes
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     []byte
+	gz      *gzip.Writer
+	started bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if !w.started {
+		w.status = status
+	}
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		if !w.isEventStream() {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) < MinCompressSize {
+				return len(p), nil
+			}
+			p, w.buf = w.buf, nil
+		}
+		w.start(true)
+	}
+
+	if w.gz != nil {
+		if _, err := w.gz.Write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what has been written so far, so event streams keep working
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		w.start(w.isEventStream())
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close sends a body too small to compress as is, or finishes the compressed body
+func (w *gzipResponseWriter) Close() error {
+	if !w.started {
+		w.start(false)
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the headers, compressing the body from here on when compress is
+// set and the response can have a body that isn't encoded already
+func (w *gzipResponseWriter) start(compress bool) {
+	w.started = true
+
+	header := w.Header()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(w.status) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *gzipResponseWriter) isEventStream() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+// bodyAllowed reports whether a response with the status may have a body
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
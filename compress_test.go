@@ -0,0 +1,115 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	large := strings.Repeat("# Notes\n\nSome markdown content.\n", 100)
+	small := "# Small\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown")
+		io.WriteString(w, large)
+	})
+	mux.HandleFunc("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown")
+		io.WriteString(w, small)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 3 {
+			fmt.Fprintf(w, "data: event %d\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+	})
+	ts := httptest.NewServer(withCompression(mux))
+	defer ts.Close()
+
+	// Disable transparent decompression to see the encoding sent
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantGzip       bool
+		wantBody       string
+	}{
+		{name: "large body compressed", path: "/large", acceptEncoding: "gzip, deflate", wantGzip: true, wantBody: large},
+		{name: "small body not compressed", path: "/small", acceptEncoding: "gzip", wantBody: small},
+		{name: "gzip not accepted", path: "/large", acceptEncoding: "deflate", wantBody: large},
+		{name: "gzip refused with q=0", path: "/large", acceptEncoding: "gzip;q=0, identity", wantBody: large},
+		{name: "no accept encoding", path: "/large", wantBody: large},
+		{name: "event stream compressed", path: "/events", acceptEncoding: "gzip", wantGzip: true, wantBody: "data: event 0\n\ndata: event 1\n\ndata: event 2\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body := resp.Body
+			gotGzip := resp.Header.Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Fatalf("Expected gzip encoding %v, got Content-Encoding %q", tt.wantGzip, resp.Header.Get("Content-Encoding"))
+			}
+			if gotGzip {
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("Failed to read gzip body: %v", err)
+				}
+				body = gz
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if string(got) != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, got)
+			}
+			if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("Expected Vary: Accept-Encoding, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestCompressResponses(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	enabled, disabled := true, false
+
+	config = Config{}
+	if !compressResponses() {
+		t.Error("Expected responses to be compressed by default")
+	}
+	config = Config{CompressResponses: &enabled}
+	if !compressResponses() {
+		t.Error("Expected responses to be compressed when enabled")
+	}
+	config = Config{CompressResponses: &disabled}
+	if compressResponses() {
+		t.Error("Expected responses not to be compressed when disabled")
+	}
+}
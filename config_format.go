@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigExtensions are the config file formats, in the order each config file
+// location is tried, JSON first so existing setups keep their config
+var ConfigExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// decodeConfig parses a config file in the format named by its extension, with
+// files of any other extension read as JSON as they always have been. YAML and
+// TOML are converted to JSON before decoding, so every format uses the same
+// field names and accepts directories as paths or objects with ignore_dirs.
+func decodeConfig(configPath string, data []byte, cfg *Config) error {
+	var values map[string]any
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to parse YAML config %s: %w", configPath, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to parse TOML config %s: %w", configPath, err)
+		}
+	default:
+		return json.Unmarshal(data, cfg)
+	}
+
+	if values == nil {
+		values = map[string]any{}
+	}
+	jsonData, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to convert config %s: %w", configPath, err)
+	}
+	return json.Unmarshal(jsonData, cfg)
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -164,6 +168,70 @@ func TestPaginationLimits(t *testing.T) {
 	}
 }
 
+func TestDefaultPageSize(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	tests := []struct {
+		name            string
+		maxPageSize     int
+		defaultPageSize int
+		arguments       any
+		want            int
+	}{
+		{
+			name:        "built-in default",
+			maxPageSize: 100,
+			arguments:   map[string]any{},
+			want:        DefaultPageSize,
+		},
+		{
+			name:            "configured default applied",
+			maxPageSize:     500,
+			defaultPageSize: 200,
+			arguments:       map[string]any{},
+			want:            200,
+		},
+		{
+			name:            "configured default without arguments",
+			maxPageSize:     500,
+			defaultPageSize: 200,
+			arguments:       nil,
+			want:            200,
+		},
+		{
+			name:            "requested page size wins",
+			maxPageSize:     500,
+			defaultPageSize: 200,
+			arguments:       map[string]any{"page_size": "10"},
+			want:            10,
+		},
+		{
+			name:            "configured default clamped to max",
+			maxPageSize:     20,
+			defaultPageSize: 200,
+			arguments:       map[string]any{},
+			want:            20,
+		},
+		{
+			name:        "built-in default clamped to max",
+			maxPageSize: 5,
+			arguments:   map[string]any{},
+			want:        5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{MaxPageSize: tt.maxPageSize, DefaultPageSize: tt.defaultPageSize}
+
+			if got := extractPageSizeParam(tt.arguments); got != tt.want {
+				t.Errorf("Expected page size %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestDebugLoggingConfiguration(t *testing.T) {
 	// Setup test environment
 	oldConfig := config
@@ -346,3 +414,415 @@ func TestLogFileConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigFilePrecedence(t *testing.T) {
+	writeConfig := func(t *testing.T, path string, maxPageSize int) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create config dir: %v", err)
+		}
+		configData, err := json.Marshal(Config{Directories: []string{"docs"}, MaxPageSize: maxPageSize})
+		if err != nil {
+			t.Fatalf("Failed to marshal test config: %v", err)
+		}
+		if err := os.WriteFile(path, configData, 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+	}
+
+	tempDir := t.TempDir()
+	envPath := filepath.Join(tempDir, "env", "custom.json")
+	localPath := filepath.Join(tempDir, "project", ".markdown-reader-mcp.json")
+	xdgPath := filepath.Join(tempDir, "xdg", "markdown-reader-mcp", "markdown-reader-mcp.json")
+	homePath := filepath.Join(tempDir, "home", ".config", "markdown-reader-mcp", "markdown-reader-mcp.json")
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		files           map[string]int
+		setEnv          bool
+		wantMaxPageSize int
+	}{
+		{
+			name:            "environment path wins",
+			files:           map[string]int{envPath: 1, localPath: 2, xdgPath: 3, homePath: 4},
+			setEnv:          true,
+			wantMaxPageSize: 1,
+		},
+		{
+			name:            "working directory before XDG",
+			files:           map[string]int{localPath: 2, xdgPath: 3, homePath: 4},
+			wantMaxPageSize: 2,
+		},
+		{
+			name:            "XDG before home",
+			files:           map[string]int{xdgPath: 3, homePath: 4},
+			wantMaxPageSize: 3,
+		},
+		{
+			name:            "home as last fallback",
+			files:           map[string]int{homePath: 4},
+			wantMaxPageSize: 4,
+		},
+		{
+			name:            "missing environment path falls through",
+			files:           map[string]int{homePath: 4},
+			setEnv:          true,
+			wantMaxPageSize: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{envPath, localPath, xdgPath, homePath} {
+				os.Remove(path)
+			}
+			for path, maxPageSize := range tt.files {
+				writeConfig(t, path, maxPageSize)
+			}
+
+			t.Setenv("HOME", filepath.Join(tempDir, "home"))
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "xdg"))
+			if tt.setEnv {
+				t.Setenv(ConfigEnvVar, envPath)
+			} else {
+				t.Setenv(ConfigEnvVar, "")
+			}
+			t.Chdir(filepath.Join(tempDir, "project"))
+
+			cfg, err := loadConfigFromFile()
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			if cfg.MaxPageSize != tt.wantMaxPageSize {
+				t.Errorf("Expected MaxPageSize %d from the chosen file, got %d", tt.wantMaxPageSize, cfg.MaxPageSize)
+			}
+		})
+	}
+}
+
+func TestConfigFileNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(ConfigEnvVar, "")
+	t.Chdir(tempDir)
+
+	if _, err := loadConfigFromFile(); err == nil {
+		t.Error("Expected error when no config file exists")
+	}
+}
+
+func TestResolveConfigPathFromFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "vaults", "work.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	configData, err := json.Marshal(Config{Directories: []string{"work"}, MaxPageSize: 42})
+	if err != nil {
+		t.Fatalf("Failed to marshal test config: %v", err)
+	}
+	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	// A config in the home directory must not be used when a path is given
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(ConfigEnvVar, "")
+
+	tests := []struct {
+		name      string
+		flagPath  string
+		wantError bool
+	}{
+		{name: "absolute path", flagPath: configPath},
+		{name: "tilde path", flagPath: "~/vaults/work.json"},
+		{name: "missing file does not fall back", flagPath: filepath.Join(tempDir, "missing.json"), wantError: true},
+		{name: "directory", flagPath: tempDir, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := resolveConfigPath(tt.flagPath)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error but got path %s", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cfg, err := loadConfig(path)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.MaxPageSize != 42 || len(cfg.Directories) != 1 || cfg.Directories[0] != "work" {
+				t.Errorf("Expected config from %s, got %+v", configPath, cfg)
+			}
+		})
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home dir: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantError bool
+		want      Config
+	}{
+		{
+			name: "no variables keeps the file settings",
+			want: Config{Directories: []string{"file"}, MaxPageSize: 100, SSEPort: 9090, LogFile: "file.log"},
+		},
+		{
+			name: "all variables override the file",
+			env: map[string]string{
+				EnvDirectories: "/notes:~/docs",
+				EnvMaxPageSize: "25",
+				EnvSSEPort:     "3000",
+				EnvLogFile:     "/tmp/env.log",
+				EnvAuthToken:   "s3cret",
+			},
+			want: Config{Directories: []string{"/notes", filepath.Join(home, "docs")}, MaxPageSize: 25, SSEPort: 3000, LogFile: "/tmp/env.log", AuthToken: "s3cret", DirectoryNames: map[string]string{filepath.Join(home, "docs"): "~/docs"}},
+		},
+		{
+			name:      "invalid page size",
+			env:       map[string]string{EnvMaxPageSize: "lots"},
+			wantError: true,
+		},
+		{
+			name:      "invalid port",
+			env:       map[string]string{EnvSSEPort: "http"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{EnvDirectories, EnvMaxPageSize, EnvSSEPort, EnvSSEHost, EnvLogFile, EnvAuthToken} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg := Config{Directories: []string{"file"}, MaxPageSize: 100, SSEPort: 9090, LogFile: "file.log"}
+			err := applyEnvOverrides(&cfg)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, cfg)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() { logger = oldLogger }()
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantErrors []string
+	}{
+		{
+			name: "valid config",
+			cfg:  Config{Directories: []string{"test/dir1"}, SSEPort: 8080, IgnoreDirs: []string{`\.git$`}},
+		},
+		{
+			name: "missing directory is only a warning",
+			cfg:  Config{Directories: []string{"test/does-not-exist"}},
+		},
+		{
+			name:       "bad port",
+			cfg:        Config{SSEPort: -1},
+			wantErrors: []string{"sse_port -1"},
+		},
+		{
+			name:       "port too large",
+			cfg:        Config{SSEPort: 70000},
+			wantErrors: []string{"sse_port 70000"},
+		},
+		{
+			name:       "broken regex",
+			cfg:        Config{IgnoreDirs: []string{`node_modules$`, `[unclosed`}},
+			wantErrors: []string{`ignore_dirs pattern "[unclosed"`},
+		},
+		{
+			name:       "negative max depth",
+			cfg:        Config{MaxDepth: -1},
+			wantErrors: []string{"max_depth -1"},
+		},
+		{
+			name:       "default page size over max",
+			cfg:        Config{MaxPageSize: 20, DefaultPageSize: 30},
+			wantErrors: []string{"default_page_size 30 must not exceed max_page_size 20"},
+		},
+		{
+			name:       "negative rate limit",
+			cfg:        Config{RateLimitPerSec: -1, RateLimitBurst: -2},
+			wantErrors: []string{"rate_limit_per_sec -1", "rate_limit_burst -2"},
+		},
+		{
+			name:       "negative default page size",
+			cfg:        Config{DefaultPageSize: -5},
+			wantErrors: []string{"default_page_size -5"},
+		},
+		{
+			name:       "broken allow_files regex",
+			cfg:        Config{AllowFiles: []string{`^pub-`, `(`}},
+			wantErrors: []string{`allow_files pattern "("`},
+		},
+		{
+			name:       "relative allowed file path",
+			cfg:        Config{AllowFilesPaths: []string{"notes/README.md"}},
+			wantErrors: []string{`allow_files_paths entry "notes/README.md" must be an absolute path`},
+		},
+		{
+			name:       "invalid resource scheme",
+			cfg:        Config{ResourceScheme: "my notes"},
+			wantErrors: []string{`resource_scheme "my notes" is not a valid URI scheme`},
+		},
+		{
+			name:       "resource scheme of another resource",
+			cfg:        Config{ResourceScheme: "file"},
+			wantErrors: []string{`resource_scheme "file" is used by another resource`},
+		},
+		{
+			name:       "max suggestions too large",
+			cfg:        Config{MaxSuggestions: 6},
+			wantErrors: []string{"max_suggestions 6 is out of range"},
+		},
+		{
+			name: "max suggestions disabled",
+			cfg:  Config{MaxSuggestions: -1},
+		},
+		{
+			name:       "negative find timeout",
+			cfg:        Config{FindTimeoutSeconds: -5},
+			wantErrors: []string{"find_timeout_seconds -5"},
+		},
+		{
+			name:       "index filename with a path",
+			cfg:        Config{IndexFilenames: []string{"docs/README.md"}},
+			wantErrors: []string{`index_filenames entry "docs/README.md"`},
+		},
+		{
+			name:       "all problems reported at once",
+			cfg:        Config{SSEPort: 0x10000, IgnoreDirs: []string{`(`}, IgnoreFiles: []string{`*.md`}, AmbiguousRead: "last"},
+			wantErrors: []string{"sse_port 65536", `ignore_dirs pattern "("`, `ignore_files pattern "*.md"`, `ambiguous_read "last"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(&tt.cfg)
+
+			if len(tt.wantErrors) == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			for _, want := range tt.wantErrors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got %q", want, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestConfigMixedDirectoryEntries(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	configData := `{
+		"directories": [
+			{"path": "test/per_dir/vault_a", "ignore_dirs": ["^archive$"]},
+			"test/per_dir/vault_b"
+		],
+		"ignore_dirs": ["\\.git$"]
+	}`
+	if err := os.WriteFile(configPath, []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	wantDirectories := []string{"test/per_dir/vault_a", "test/per_dir/vault_b"}
+	if !reflect.DeepEqual(cfg.Directories, wantDirectories) {
+		t.Errorf("Expected directories %v, got %v", wantDirectories, cfg.Directories)
+	}
+	if !reflect.DeepEqual(cfg.IgnoreDirs, []string{`\.git$`}) {
+		t.Errorf("Expected global ignore_dirs to be kept, got %v", cfg.IgnoreDirs)
+	}
+
+	cfg.MaxPageSize = DefaultMaxPageSize
+	config = *cfg
+
+	files, err := findMarkdownFiles("", DefaultMaxPageSize)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	sort.Strings(names)
+
+	// archive is only ignored in vault_a
+	wantNames := []string{"note_a.md", "note_b.md", "old_b.md"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Expected files %v, got %v", wantNames, names)
+	}
+}
+
+func TestConfigInvalidDirectoryEntry(t *testing.T) {
+	tests := []string{
+		`{"directories": [{"ignore_dirs": ["^archive$"]}]}`,
+		`{"directories": [42]}`,
+	}
+
+	for _, data := range tests {
+		var cfg Config
+		if err := json.Unmarshal([]byte(data), &cfg); err == nil {
+			t.Errorf("Expected error unmarshalling %s", data)
+		}
+	}
+}
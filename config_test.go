@@ -3,7 +3,9 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -216,3 +218,194 @@ func TestLoadConfigFromFileWithTilde(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configData := `{
+  "directories": ["test/dir1", {"path": "test/dir2", "ignore_dirs": ["^drafts$"]}],
+  "max_page_size": 80,
+  "ignore_files": ["^draft-"],
+  "find_timeout_seconds": 5
+}`
+	if err := os.WriteFile(configPath, []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// The config file, environment and command line are merged
+	cmd := exec.Command("./markdown-reader-mcp", "-config", configPath, "-print-config", "-sse", "-debug")
+	cmd.Env = append(os.Environ(), EnvSSEPort+"=9090")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Expected -print-config to exit zero, got %v", err)
+	}
+
+	var printed Config
+	if err := json.Unmarshal(output, &printed); err != nil {
+		t.Fatalf("Expected the output to be a config, got %q: %v", output, err)
+	}
+
+	want := Config{
+		Directories:         []string{"test/dir1", "test/dir2"},
+		DirectoryIgnoreDirs: map[string][]string{"test/dir2": {"^drafts$"}},
+		MaxPageSize:         80,
+		IgnoreDirs:          []string{`\.git$`, `node_modules$`},
+		IgnoreFiles:         []string{"^draft-"},
+		Extensions:          DefaultExtensions,
+		SSEPort:             9090,
+		FindTimeoutSeconds:  5,
+		Transport:           TransportSSE,
+		DebugLogging:        true,
+	}
+	if !reflect.DeepEqual(printed, want) {
+		t.Errorf("Expected %+v, got %+v", want, printed)
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	configs := map[string]string{
+		"config.json": `{
+			"directories": ["~/notes", {"path": "docs", "ignore_dirs": ["^drafts$"]}],
+			"max_page_size": 25,
+			"debug_logging": true,
+			"allow_files_paths": ["~/shared"],
+			"extensions": [".md", ".markdown"]
+		}`,
+		"config.yaml": `# Notes and docs
+directories:
+  - ~/notes
+  - path: docs
+    ignore_dirs: ["^drafts$"]
+max_page_size: 25
+debug_logging: true
+allow_files_paths:
+  - ~/shared
+extensions: [.md, .markdown]
+`,
+		"config.yml": `directories: [~/notes, {path: docs, ignore_dirs: ["^drafts$"]}]
+max_page_size: 25
+debug_logging: true
+allow_files_paths: [~/shared]
+extensions: [.md, .markdown]
+`,
+		"config.toml": `# Notes and docs
+directories = ["~/notes", { path = "docs", ignore_dirs = ["^drafts$"] }]
+max_page_size = 25
+debug_logging = true
+allow_files_paths = ["~/shared"]
+extensions = [".md", ".markdown"]
+`,
+	}
+
+	want := &Config{
+		Directories:         []string{filepath.Join(tempDir, "notes"), "docs"},
+		DirectoryIgnoreDirs: map[string][]string{"docs": {"^drafts$"}},
+		DirectoryNames:      map[string]string{filepath.Join(tempDir, "notes"): "~/notes"},
+		MaxPageSize:         25,
+		DebugLogging:        true,
+		AllowFilesPaths:     []string{filepath.Join(tempDir, "shared")},
+		Extensions:          []string{".md", ".markdown"},
+		IgnoreDirs:          []string{`\.git$`, `node_modules$`},
+	}
+
+	for name, data := range configs {
+		t.Run(name, func(t *testing.T) {
+			configPath := filepath.Join(tempDir, name)
+			if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := loadConfig(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("Expected config %+v, got %+v", want, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFormatsDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, name := range []string{"empty.yaml", "empty.toml"} {
+		t.Run(name, func(t *testing.T) {
+			configPath := filepath.Join(tempDir, name)
+			if err := os.WriteFile(configPath, nil, 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			cfg, err := loadConfig(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if cfg.MaxPageSize != DefaultMaxPageSize {
+				t.Errorf("Expected default MaxPageSize %d, got %d", DefaultMaxPageSize, cfg.MaxPageSize)
+			}
+			if !reflect.DeepEqual(cfg.Extensions, DefaultExtensions) {
+				t.Errorf("Expected default extensions %v, got %v", DefaultExtensions, cfg.Extensions)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFormatsInvalid(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configs := map[string]string{
+		"invalid.yaml":    "directories: [docs\n",
+		"invalid.toml":    "directories = [\"docs\"\n",
+		"wrong_type.yaml": "max_page_size: lots\n",
+	}
+	for name, data := range configs {
+		t.Run(name, func(t *testing.T) {
+			configPath := filepath.Join(tempDir, name)
+			if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+			if _, err := loadConfig(configPath); err == nil {
+				t.Errorf("Expected error loading %s", name)
+			}
+		})
+	}
+}
+
+func TestConfigSearchFormats(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".config", "markdown-reader-mcp")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create temp config dir: %v", err)
+	}
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(ConfigEnvVar, "")
+	t.Chdir(tempDir)
+
+	files := map[string]string{
+		"markdown-reader-mcp.toml": "max_page_size = 3\n",
+		"markdown-reader-mcp.yml":  "max_page_size: 2\n",
+		"markdown-reader-mcp.json": `{"max_page_size": 1}`,
+	}
+	// Each file is added in turn, and the most recently added one is the one
+	// found, from the last format tried to JSON
+	for _, name := range []string{"markdown-reader-mcp.toml", "markdown-reader-mcp.yml", "markdown-reader-mcp.json"} {
+		if err := os.WriteFile(filepath.Join(configDir, name), []byte(files[name]), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+
+		cfg, err := loadConfigFromFile()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		var want Config
+		if err := decodeConfig(name, []byte(files[name]), &want); err != nil {
+			t.Fatalf("Failed to decode %s: %v", name, err)
+		}
+		if cfg.MaxPageSize != want.MaxPageSize {
+			t.Errorf("Expected config from %s with MaxPageSize %d, got %d", name, want.MaxPageSize, cfg.MaxPageSize)
+		}
+	}
+}
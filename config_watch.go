@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ConfigPollInterval is how often the config file is checked for changes
+const ConfigPollInterval = 2 * time.Second
+
+// withConfigReadLock holds the config read lock for the duration of a tool call
+// so a reload can't swap the config while the call is using it
+func withConfigReadLock(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		configMu.RLock()
+		defer configMu.RUnlock()
+		return next(ctx, req)
+	}
+}
+
+// withConfigReadLockResource holds the config read lock for the duration of a resource read
+func withConfigReadLockResource(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		configMu.RLock()
+		defer configMu.RUnlock()
+		return next(ctx, req)
+	}
+}
+
+// watchConfig starts polling the config file in the background and reloads it
+// whenever its modification time or size changes. Polling stops when the context
+// is cancelled, after which the returned channel is closed.
+func watchConfig(ctx context.Context, configPath string, args []string, interval time.Duration) <-chan struct{} {
+	// Stat before returning so changes made after the call are always seen
+	lastInfo, err := os.Stat(configPath)
+	if err != nil {
+		logger.Warn("Could not stat config file", "path", configPath, "error", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(configPath)
+			if err != nil {
+				logger.Debug("Could not stat config file", "path", configPath, "error", err)
+				continue
+			}
+			if lastInfo != nil && info.ModTime().Equal(lastInfo.ModTime()) && info.Size() == lastInfo.Size() {
+				continue
+			}
+			lastInfo = info
+
+			if err := reloadConfig(configPath, args); err != nil {
+				logger.Error("Could not reload config file, keeping the current config", "path", configPath, "error", err)
+			}
+		}
+	}()
+
+	return done
+}
+
+// reloadConfig loads and validates the config file and swaps it in. The environment
+// and command line directories still override the file, as they do at startup.
+func reloadConfig(configPath string, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if err := applyEnvOverrides(cfg); err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		cfg.Directories = args
+	}
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+
+	configMu.Lock()
+	config = *cfg
+	configMu.Unlock()
+
+	// Cached files and content may belong to directories that are no longer configured
+	markdownIndex.invalidate()
+	searchCache.clear()
+	fileReadCache.clear()
+
+	logger.Info("Reloaded config file", "path", configPath, "directories", cfg.Directories)
+	return nil
+}
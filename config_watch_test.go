@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfigReloadsDirectories(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	t.Setenv(EnvDirectories, "")
+	t.Setenv(EnvMaxPageSize, "")
+	t.Setenv(EnvSSEPort, "")
+	t.Setenv(EnvLogFile, "")
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	writeConfig := func(directories []string, modTime time.Time) {
+		t.Helper()
+		configData, err := json.Marshal(Config{Directories: directories})
+		if err != nil {
+			t.Fatalf("Failed to marshal test config: %v", err)
+		}
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+		// Set the modification time explicitly so the change is seen on coarse clocks
+		if err := os.Chtimes(configPath, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set config file time: %v", err)
+		}
+	}
+
+	writeConfig([]string{"test/dir1"}, time.Now().Add(-time.Hour))
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	config = *cfg
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := watchConfig(ctx, configPath, nil, 10*time.Millisecond)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	findFiles := func() []string {
+		configMu.RLock()
+		defer configMu.RUnlock()
+		files, err := findMarkdownFiles("", DefaultMaxPageSize)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return files
+	}
+
+	if files := findFiles(); len(files) == 0 || !hasFileInDir(files, "test/dir1") {
+		t.Fatalf("Expected files from test/dir1 before reload, got %v", files)
+	}
+
+	writeConfig([]string{"test/dir2"}, time.Now())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		files := findFiles()
+		if hasFileInDir(files, "test/dir2") && !hasFileInDir(files, "test/dir1") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected files from test/dir2 after reload, got %v", files)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloadConfigKeepsCurrentConfigWhenInvalid(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	if err := os.WriteFile(configPath, []byte(`{"directories": ["test/dir2"], "sse_port": -1}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	config = Config{Directories: []string{"test/dir1"}}
+	if err := reloadConfig(configPath, nil); err == nil {
+		t.Fatal("Expected error reloading an invalid config")
+	}
+
+	if len(config.Directories) != 1 || config.Directories[0] != "test/dir1" {
+		t.Errorf("Expected current config to be kept, got directories %v", config.Directories)
+	}
+}
+
+func hasFileInDir(files []string, dir string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		if rel, err := filepath.Rel(absDir, file); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
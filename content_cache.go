@@ -0,0 +1,292 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPrewarmMaxMB caps the memory used by the pre-warmed content cache
+const DefaultPrewarmMaxMB = 64
+
+// contentReader reads file content for content search, replaceable in tests
+var contentReader = readFileContentContext
+
+type cachedContent struct {
+	modTime time.Time
+	size    int64
+	text    string
+}
+
+// contentCache holds file contents read at startup so content searches don't hit
+// the disk. Entries are validated against the file's modification time and size.
+type contentCache struct {
+	mu         sync.RWMutex
+	entries    map[string]cachedContent
+	totalBytes int64
+}
+
+var searchCache = &contentCache{entries: map[string]cachedContent{}}
+
+// prewarmContentCache reads the content of all markdown files into the search cache,
+// stopping once the configured memory cap is reached
+func prewarmContentCache() {
+	maxBytes := int64(config.PrewarmMaxMB) * 1024 * 1024
+	if maxBytes <= 0 {
+		maxBytes = DefaultPrewarmMaxMB * 1024 * 1024
+	}
+
+	start := time.Now()
+	files := collectAllMarkdownFiles()
+	cached := 0
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Debug("Could not stat file for content cache", "file", file, "error", err)
+			continue
+		}
+
+		if searchCache.size()+info.Size() > maxBytes {
+			logger.Info("Content cache limit reached, remaining files will be read on demand", "limit_mb", maxBytes/1024/1024, "cached_files", cached)
+			break
+		}
+
+		content, err := contentReader(context.Background(), file)
+		if err != nil {
+			logger.Debug("Could not read file for content cache", "file", file, "error", err)
+			continue
+		}
+
+		searchCache.put(file, info, string(content))
+		cached++
+	}
+
+	logger.Info("Content cache pre-warmed", "files", cached, "bytes", searchCache.size(), "duration", time.Since(start))
+}
+
+func (c *contentCache) put(path string, info os.FileInfo, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.entries[path]; ok {
+		c.totalBytes -= existing.size
+	}
+	c.entries[path] = cachedContent{modTime: info.ModTime(), size: info.Size(), text: text}
+	c.totalBytes += info.Size()
+}
+
+// get returns the cached content if the file has not changed since it was cached
+func (c *contentCache) get(path string) (string, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[path]
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.ModTime().Equal(entry.modTime) || info.Size() != entry.size {
+		c.mu.Lock()
+		if current, ok := c.entries[path]; ok && current == entry {
+			delete(c.entries, path)
+			c.totalBytes -= entry.size
+		}
+		c.mu.Unlock()
+		return "", false
+	}
+
+	return entry.text, true
+}
+
+func (c *contentCache) size() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.totalBytes
+}
+
+func (c *contentCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]cachedContent{}
+	c.totalBytes = 0
+}
+
+// fileContent returns a file's content for searching, from the cache when possible
+func fileContent(path string) (string, error) {
+	if text, ok := searchCache.get(path); ok {
+		return text, nil
+	}
+
+	content, err := contentReader(context.Background(), path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// contentContains reports whether the file content contains the query. Unless
+// caseSensitive is set the query must already be lowercased.
+func contentContains(path, query string, caseSensitive bool) bool {
+	content, err := fileContent(path)
+	if err != nil {
+		logger.Debug("Could not read file for content search", "file", path, "error", err)
+		return false
+	}
+	if !caseSensitive {
+		content = strings.ToLower(content)
+	}
+	return strings.Contains(content, query)
+}
+
+// firstMatchLine returns the 1-based number of the first line of the file
+// containing the query, or 0 when no line does. Lines may end with \n or \r\n.
+func firstMatchLine(path, query string, caseSensitive bool) int {
+	content, err := fileContent(path)
+	if err != nil {
+		logger.Debug("Could not read file for content search", "file", path, "error", err)
+		return 0
+	}
+	if !caseSensitive {
+		query = strings.ToLower(query)
+	}
+
+	lineNumber := 1
+	for content != "" {
+		line, rest, _ := strings.Cut(content, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		if !caseSensitive {
+			line = strings.ToLower(line)
+		}
+		if strings.Contains(line, query) {
+			return lineNumber
+		}
+		content = rest
+		lineNumber++
+	}
+	return 0
+}
+
+// lruEntry is a file held in the read cache
+type lruEntry struct {
+	path    string
+	modTime time.Time
+	size    int64
+	content []byte
+}
+
+// readCache is a least recently used cache of file contents returned by resource
+// reads, bounded by the total bytes held. Entries are checked against the file's
+// modification time and size on every hit.
+type readCache struct {
+	mu         sync.Mutex
+	order      *list.List
+	entries    map[string]*list.Element
+	totalBytes int64
+}
+
+var fileReadCache = newReadCache()
+
+func newReadCache() *readCache {
+	return &readCache{order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// contentCacheBytes returns the configured read cache size, 0 when disabled
+func contentCacheBytes() int64 {
+	if config.ContentCacheMB <= 0 {
+		return 0
+	}
+	return int64(config.ContentCacheMB) * 1024 * 1024
+}
+
+// readFileCached reads a file through the read cache when it is enabled. A read
+// from disk stops when the context is done.
+func readFileCached(ctx context.Context, path string) ([]byte, error) {
+	capacity := contentCacheBytes()
+	if capacity == 0 {
+		return readFileContentContext(ctx, path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filepath.Base(path), err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filepath.Base(path), err)
+	}
+
+	limit := maxFileSize()
+	if content, ok := fileReadCache.get(absPath, info); ok && (limit == 0 || info.Size() <= limit) {
+		return content, nil
+	}
+
+	content, err := contentReader(ctx, absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileReadCache.put(absPath, info, content, capacity)
+	return content, nil
+}
+
+func (c *readCache) get(path string, info os.FileInfo) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.modTime.Equal(info.ModTime()) || entry.size != info.Size() {
+		c.removeLocked(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.content, true
+}
+
+func (c *readCache) put(path string, info os.FileInfo, content []byte, capacity int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[path]; ok {
+		c.removeLocked(element)
+	}
+
+	// Files larger than the whole cache are not cached
+	if int64(len(content)) > capacity {
+		return
+	}
+
+	entry := &lruEntry{path: path, modTime: info.ModTime(), size: info.Size(), content: content}
+	c.entries[path] = c.order.PushFront(entry)
+	c.totalBytes += int64(len(content))
+
+	for c.totalBytes > capacity {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *readCache) removeLocked(element *list.Element) {
+	entry := c.order.Remove(element).(*lruEntry)
+	delete(c.entries, entry.path)
+	c.totalBytes -= int64(len(entry.content))
+}
+
+func (c *readCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = map[string]*list.Element{}
+	c.totalBytes = 0
+}
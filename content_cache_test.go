@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupContentSearchTest(t *testing.T, cfg Config) *int {
+	t.Helper()
+
+	oldConfig := config
+	oldLogger := logger
+	oldReader := contentReader
+	t.Cleanup(func() {
+		config = oldConfig
+		logger = oldLogger
+		contentReader = oldReader
+		searchCache.clear()
+	})
+
+	config = cfg
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	searchCache.clear()
+
+	// Content searches read files concurrently
+	var mu sync.Mutex
+	reads := 0
+	contentReader = func(ctx context.Context, path string) ([]byte, error) {
+		mu.Lock()
+		reads++
+		mu.Unlock()
+		return readFileContentContext(ctx, path)
+	}
+	return &reads
+}
+
+func TestFindMarkdownFilesSearchContent(t *testing.T) {
+	setupContentSearchTest(t, Config{Directories: []string{"test/content_search"}, MaxPageSize: DefaultMaxPageSize})
+
+	files, err := findMarkdownFilesWithOptions(findOptions{Query: "tomatoes", SearchContent: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files mentioning tomatoes, got %d: %v", len(files), files)
+	}
+
+	files, err = findMarkdownFilesWithOptions(findOptions{Query: "tomatoes"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no filename matches without search_content, got %v", files)
+	}
+}
+
+func TestPrewarmContentCacheAvoidsReads(t *testing.T) {
+	reads := setupContentSearchTest(t, Config{Directories: []string{"test/content_search"}, MaxPageSize: DefaultMaxPageSize})
+
+	prewarmContentCache()
+	if *reads != 3 {
+		t.Fatalf("Expected 3 reads while pre-warming, got %d", *reads)
+	}
+
+	*reads = 0
+	files, err := findMarkdownFilesWithOptions(findOptions{Query: "trains", SearchContent: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "travel.md" {
+		t.Errorf("Expected travel.md, got %v", files)
+	}
+	if *reads != 0 {
+		t.Errorf("Expected no reads after pre-warming, got %d", *reads)
+	}
+}
+
+func TestPrewarmContentCacheDefaultLimit(t *testing.T) {
+	reads := setupContentSearchTest(t, Config{Directories: []string{"test/content_search"}, MaxPageSize: DefaultMaxPageSize})
+
+	// A non-positive limit falls back to the default cap
+	config.PrewarmMaxMB = -1
+	searchCache.clear()
+	prewarmContentCache()
+	if searchCache.size() == 0 {
+		t.Fatalf("Expected non-positive limit to fall back to the default cap")
+	}
+
+	searchCache.clear()
+	*reads = 0
+	if _, err := findMarkdownFilesWithOptions(findOptions{Query: "trains", SearchContent: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *reads != 3 {
+		t.Errorf("Expected 3 on-demand reads with an empty cache, got %d", *reads)
+	}
+}
+
+func TestContentCacheInvalidatesChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(path, []byte("before"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reads := setupContentSearchTest(t, Config{Directories: []string{dir}, MaxPageSize: DefaultMaxPageSize})
+	prewarmContentCache()
+
+	if err := os.WriteFile(path, []byte("after and longer"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite file: %v", err)
+	}
+
+	*reads = 0
+	if !contentContains(path, "after", false) {
+		t.Errorf("Expected changed content to be re-read")
+	}
+	if *reads != 1 {
+		t.Errorf("Expected 1 read for the changed file, got %d", *reads)
+	}
+}
+
+func TestReadFileCached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(path, []byte("# Original\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reads := setupContentSearchTest(t, Config{Directories: []string{dir}, ContentCacheMB: 1})
+	fileReadCache.clear()
+	t.Cleanup(fileReadCache.clear)
+
+	first, err := readFileCached(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := readFileCached(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(first) != string(second) || string(second) != "# Original\n" {
+		t.Errorf("Expected identical cached content, got %q and %q", first, second)
+	}
+	if *reads != 1 {
+		t.Errorf("Expected 1 read with a cache hit, got %d", *reads)
+	}
+
+	// Editing the file busts the cache
+	if err := os.WriteFile(path, []byte("# Edited\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite file: %v", err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+
+	edited, err := readFileCached(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(edited) != "# Edited\n" {
+		t.Errorf("Expected edited content, got %q", edited)
+	}
+	if *reads != 2 {
+		t.Errorf("Expected the edit to cause a second read, got %d reads", *reads)
+	}
+}
+
+func TestReadFileCachedDisabled(t *testing.T) {
+	reads := setupContentSearchTest(t, Config{Directories: []string{"test/content_search"}})
+	fileReadCache.clear()
+
+	for range 3 {
+		if _, err := readFileCached(context.Background(), "test/content_search/travel.md"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if *reads != 0 {
+		t.Errorf("Expected the disabled cache to read directly, got %d cached reads", *reads)
+	}
+	if len(fileReadCache.entries) != 0 {
+		t.Errorf("Expected nothing cached when disabled, got %d entries", len(fileReadCache.entries))
+	}
+}
+
+func TestReadCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newReadCache()
+
+	info := func(name string) os.FileInfo {
+		fileInfo, err := os.Stat(filepath.Join("test/content_search", name))
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		return fileInfo
+	}
+
+	cache.put("a", info("travel.md"), []byte("aaaa"), 10)
+	cache.put("b", info("travel.md"), []byte("bbbb"), 10)
+	cache.get("a", info("travel.md"))
+	cache.put("c", info("travel.md"), []byte("cccc"), 10)
+
+	if _, ok := cache.entries["b"]; ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.entries["a"]; !ok {
+		t.Error("Expected recently used entry to be kept")
+	}
+	if cache.totalBytes != 8 {
+		t.Errorf("Expected 8 cached bytes, got %d", cache.totalBytes)
+	}
+}
+
+func TestSearchContentConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 200 {
+		content := "# Note\n"
+		if i%3 == 0 {
+			content += "Mentions tomatoes.\n"
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note-%03d.md", i)), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	var want []string
+	for _, concurrency := range []int{1, 2, 8, 64} {
+		setupContentSearchTest(t, Config{Directories: []string{dir}, MaxPageSize: DefaultMaxPageSize, SearchConcurrency: concurrency})
+
+		files, err := findMarkdownFilesWithOptions(findOptions{Query: "tomatoes", SearchContent: true, PageSize: DefaultMaxPageSize})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(files) != 67 {
+			t.Fatalf("Expected 67 files mentioning tomatoes with search_concurrency %d, got %d", concurrency, len(files))
+		}
+		if want == nil {
+			want = files
+		} else if !reflect.DeepEqual(files, want) {
+			t.Errorf("Expected the same results with search_concurrency %d\ngot:  %v\nwant: %v", concurrency, files, want)
+		}
+	}
+}
+
+func BenchmarkSearchContent(b *testing.B) {
+	dir := b.TempDir()
+	for i := range 2000 {
+		content := strings.Repeat("Some words about nothing in particular.\n", 50)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note-%04d.md", i)), []byte(content), 0644); err != nil {
+			b.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	for _, concurrency := range []int{1, DefaultSearchConcurrency, 32} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			setupFileIndexTest(b, Config{Directories: []string{dir}, Extensions: DefaultExtensions, MaxPageSize: DefaultMaxPageSize, SearchConcurrency: concurrency})
+			searchCache.clear()
+
+			for b.Loop() {
+				if _, err := findMarkdownFilesWithOptions(findOptions{Query: "tomatoes", SearchContent: true}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DirectoryResourceScheme is the URI scheme of the resource listing a configured directory
+const DirectoryResourceScheme = "markdown-dir://"
+
+func handleListDirectoryResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	contents, err := listDirectoryResource(ctx, req)
+	if err != nil {
+		return nil, &resourceError{err: err}
+	}
+	return contents, nil
+}
+
+func listDirectoryResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log := requestLogger()
+	log.Debug("listing directory", "uri", req.Params.URI)
+
+	// Extract the directory from template parameters (markdown-dir://{+dir})
+	dir := ""
+	if req.Params.Arguments != nil {
+		if dirArg, ok := req.Params.Arguments["dir"].(string); ok {
+			dir = dirArg
+		}
+	}
+
+	// Fallback: Extract from URI path for direct URI calls
+	if dir == "" && strings.HasPrefix(req.Params.URI, DirectoryResourceScheme) {
+		unescaped, err := url.PathUnescape(strings.TrimPrefix(req.Params.URI, DirectoryResourceScheme))
+		if err != nil {
+			return nil, errorWithCode(ErrorCodeInvalidArgument, "invalid directory in URI %s: %v", req.Params.URI, err)
+		}
+		dir = unescaped
+	}
+
+	if dir == "" {
+		log.Debug("list_directory_resource missing dir parameter")
+		return nil, errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: dir")
+	}
+
+	// Only configured directories can be listed, never arbitrary paths, picked
+	// as written in the config or by label as in markdown:// URIs
+	configuredDir, ok := findConfiguredDirectory(dir)
+	if !ok {
+		labelled, err := findDirectoryByLabel(dir)
+		if err != nil {
+			log.Debug("list_directory_resource rejected unknown directory", "dir", dir, "error", err)
+			return nil, err
+		}
+		configuredDir = labelled
+	}
+
+	// Names only, no absolute paths
+	directory := config.configuredDirectoryName(configuredDir)
+	files, truncated := collectMarkdownFilesFromDir(ctx, configuredDir)
+	if truncated {
+		log.Debug("list_directory_resource cancelled", "dir", configuredDir, "error", ctx.Err())
+		return nil, fmt.Errorf("listing directory %s stopped early: %w", directory, ctx.Err())
+	}
+	names := []string{}
+	for _, file := range files {
+		names = append(names, markdownName(file))
+	}
+
+	result := map[string]any{
+		"directory": directory,
+		"files":     names,
+		"count":     len(names),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("list_directory_resource failed to marshal JSON", "error", err)
+		return nil, fmt.Errorf("failed to marshal file list: %v", err)
+	}
+
+	log.Debug("list_directory_resource completed successfully", "directory", configuredDir, "files", len(names))
+
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      req.Params.URI,
+		MIMEType: "application/json",
+		Text:     string(jsonData),
+	}}, nil
+}
+
+// configuredDirectoryName returns a configured directory as written in the config
+// or environment, before a leading ~ was expanded, so results name the directory
+// without revealing the home directory
+func (c *Config) configuredDirectoryName(dir string) string {
+	if name, ok := c.DirectoryNames[dir]; ok {
+		return name
+	}
+	return dir
+}
+
+// directoryLabel returns the label picking a configured directory in markdown://
+// URIs, the last element of its path, e.g. notes for ~/notes
+func directoryLabel(dir string) string {
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+	return filepath.Base(dir)
+}
+
+// findDirectoryByLabel returns the configured directory with the label, failing
+// when no directory or more than one has it
+func findDirectoryByLabel(label string) (string, error) {
+	var matches, labels []string
+	for _, dir := range config.Directories {
+		labels = append(labels, directoryLabel(dir))
+		if directoryLabel(dir) == label {
+			matches = append(matches, dir)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", errorWithCode(ErrorCodeInvalidArgument, "unknown directory label %q, configured directories are labelled: %s", label, strings.Join(labels, ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", errorWithCode(ErrorCodeInvalidArgument, "directory label %q is shared by %d configured directories, configured directories are labelled: %s", label, len(matches), strings.Join(labels, ", "))
+	}
+}
+
+// findConfiguredDirectory returns the configured directory with the label, as
+// written in the config, ignoring a trailing separator or leading ./ and
+// expanding a leading ~ as the config does
+func findConfiguredDirectory(label string) (string, bool) {
+	if i := slices.Index(config.Directories, label); i >= 0 {
+		return config.Directories[i], true
+	}
+
+	if expanded, err := expandTilde(label); err == nil {
+		label = expanded
+	}
+	for _, dir := range config.Directories {
+		if filepath.Clean(dir) == filepath.Clean(label) {
+			return dir, true
+		}
+	}
+	return "", false
+}
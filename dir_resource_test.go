@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleListDirectoryResource(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/dir1", "./test/dir2"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		req       mcp.ReadResourceRequest
+		wantDir   string
+		wantFiles []string
+		wantCode  string
+	}{
+		{
+			name: "configured directory from template arguments",
+			req: mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{
+				URI:       "markdown-dir://test/dir1",
+				Arguments: map[string]any{"dir": "test/dir1"},
+			}},
+			wantDir:   "test/dir1",
+			wantFiles: []string{"README.md", "bar.md", "baz.md", "foo.md"},
+		},
+		{
+			name:      "configured directory from URI",
+			req:       mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://test/dir2/"}},
+			wantDir:   "./test/dir2",
+			wantFiles: []string{"cat.md"},
+		},
+		{
+			name:      "configured directory by label",
+			req:       mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://dir2"}},
+			wantDir:   "./test/dir2",
+			wantFiles: []string{"cat.md"},
+		},
+		{
+			name:     "unknown label",
+			req:      mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://dir3"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "subdirectory of a configured directory",
+			req:      mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://test/dir1/child"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "arbitrary path",
+			req:      mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir:///etc"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "traversal out of a configured directory",
+			req:      mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://test/dir1/../ignore_test"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "missing directory",
+			req:      mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handleListDirectoryResource(context.Background(), tt.req)
+			if tt.wantCode != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				var payload errorPayload
+				if err := json.Unmarshal([]byte(err.Error()), &payload); err != nil {
+					t.Fatalf("Expected a JSON error payload, got %q: %v", err.Error(), err)
+				}
+				if payload.Error.Code != tt.wantCode {
+					t.Errorf("Expected code %s, got %s: %s", tt.wantCode, payload.Error.Code, payload.Error.Message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content := result[0].(mcp.TextResourceContents)
+			if content.MIMEType != "application/json" {
+				t.Errorf("Expected MIME type application/json, got %q", content.MIMEType)
+			}
+
+			var listing struct {
+				Directory string   `json:"directory"`
+				Files     []string `json:"files"`
+				Count     int      `json:"count"`
+			}
+			if err := json.Unmarshal([]byte(content.Text), &listing); err != nil {
+				t.Fatalf("Failed to parse listing: %v", err)
+			}
+			slices.Sort(listing.Files)
+
+			if listing.Directory != tt.wantDir {
+				t.Errorf("Expected directory %q, got %q", tt.wantDir, listing.Directory)
+			}
+			if !slices.Equal(listing.Files, tt.wantFiles) || listing.Count != len(tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v (count %d)", tt.wantFiles, listing.Files, listing.Count)
+			}
+		})
+	}
+}
+
+func TestHandleListDirectoryResourceTildeDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	notes := filepath.Join(home, "notes")
+	if err := os.MkdirAll(notes, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(notes, "note.md"), []byte("# Note\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	setupFileIndexTest(t, Config{
+		Directories:    []string{notes},
+		DirectoryNames: map[string]string{notes: "~/notes"},
+		MaxPageSize:    DefaultMaxPageSize,
+	})
+
+	req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://~/notes"}}
+	result, err := handleListDirectoryResource(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	text := result[0].(mcp.TextResourceContents).Text
+	if strings.Contains(text, home) {
+		t.Errorf("Expected no home directory path in the listing, got %s", text)
+	}
+	var listing struct {
+		Directory string `json:"directory"`
+	}
+	if err := json.Unmarshal([]byte(text), &listing); err != nil {
+		t.Fatalf("Failed to parse listing: %v", err)
+	}
+	if listing.Directory != "~/notes" {
+		t.Errorf("Expected directory ~/notes, got %q", listing.Directory)
+	}
+}
+
+func TestHandleListDirectoryResourceSharedLabel(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/collisions/archive", "test/tags/archive"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://archive"}}
+	_, err := handleListDirectoryResource(context.Background(), req)
+	if err == nil {
+		t.Fatal("Expected an error for a label shared by two directories")
+	}
+	if !strings.Contains(err.Error(), "labelled: archive, archive") {
+		t.Errorf("Expected the error to list the labels, got %v", err)
+	}
+	absDir, _ := filepath.Abs("test")
+	if strings.Contains(err.Error(), absDir) {
+		t.Errorf("Expected no absolute paths in the error, got %v", err)
+	}
+}
+
+func TestHandleListDirectoryResourceCancelled(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "markdown-dir://test/dir1"}}
+	_, err := handleListDirectoryResource(ctx, req)
+	if err == nil {
+		t.Fatal("Expected error but got none")
+	}
+	var payload errorPayload
+	if err := json.Unmarshal([]byte(err.Error()), &payload); err != nil {
+		t.Fatalf("Expected a JSON error payload, got %q: %v", err.Error(), err)
+	}
+	if payload.Error.Code != ErrorCodeCancelled {
+		t.Errorf("Expected code %s, got %s: %s", ErrorCodeCancelled, payload.Error.Code, payload.Error.Message)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultIndexFilenames are the files a directory read returns, first match
+// first, when index_filenames is not configured
+var DefaultIndexFilenames = []string{"README.md", "index.md"}
+
+func handleReadDirectoryIndex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	directory := extractStringParam(req.Params.Arguments, "directory")
+
+	log := requestLogger()
+	log.Debug("read_directory_index called", "directory", directory)
+
+	if directory == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: directory")), nil
+	}
+
+	dir, path, err := findDirectoryIndex(directory)
+	if err != nil {
+		log.Debug("read_directory_index could not find index file", "directory", directory, "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	content, err := readFileContent(path)
+	if err != nil {
+		log.Debug("read_directory_index failed to read file", "file", path, "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	result := map[string]any{
+		"directory": directory,
+		"name":      relativeToDirectory(dir, path),
+		"content":   string(content),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("read_directory_index failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal index file: %w", err)), nil
+	}
+
+	log.Debug("read_directory_index completed successfully", "file", path, "bytes_read", len(content))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// indexFilenames returns the names of the files standing for their directory
+func indexFilenames() []string {
+	if len(config.IndexFilenames) == 0 {
+		return DefaultIndexFilenames
+	}
+	return config.IndexFilenames
+}
+
+// findDirectoryIndex returns the configured directory and the path of the index
+// file of a folder, given as a directory label optionally followed by a path
+// within it, e.g. notes or notes/projects/acme. The first of index_filenames
+// present in the folder is its index, the way static site generators serve a
+// directory URL. The folder path is checked like a requested filename, and
+// folders that are ignored or resolve outside the configured directories through
+// symlinks have no index.
+func findDirectoryIndex(directory string) (string, string, error) {
+	label, folder, _ := strings.Cut(strings.TrimSuffix(directory, "/"), "/")
+	dir, err := findDirectoryByLabel(label)
+	if err != nil {
+		return "", "", err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve directory %s: %v", dir, err)
+	}
+	if folder != "" {
+		if err := validateRequestedFilename(folder); err != nil {
+			return "", "", err
+		}
+		for _, part := range strings.Split(folder, "/") {
+			if config.shouldIgnoreDir(part) || matchesAnyPattern(config.DirectoryIgnoreDirs[dir], part) {
+				return "", "", fmt.Errorf("%w: %s is ignored", errFileNotFound, directory)
+			}
+		}
+	}
+	folderPath := filepath.Join(absDir, filepath.FromSlash(folder))
+
+	if info, err := os.Stat(folderPath); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("%w: no directory %s", errFileNotFound, directory)
+	}
+
+	for _, name := range indexFilenames() {
+		if !config.isMarkdownFile(name) || config.shouldIgnoreFile(name) {
+			continue
+		}
+		path := filepath.Join(folderPath, name)
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := config.checkSymlink(path); err != nil {
+			logger.Debug("Skipping index file", "path", path, "error", err)
+			continue
+		}
+		// The folder itself may be reached through a symlinked directory
+		if realPath, err := filepath.EvalSymlinks(path); err != nil || !config.isRealPathWithinConfiguredDirs(realPath) {
+			logger.Debug("Skipping index file outside the configured directories", "path", path)
+			continue
+		}
+		return dir, path, nil
+	}
+
+	return "", "", fmt.Errorf("%w: no index file %s in %s", errFileNotFound, strings.Join(indexFilenames(), " or "), directory)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleReadDirectoryIndex(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		directory   string
+		wantName    string
+		wantContent string
+		wantCode    string
+	}{
+		{
+			name:        "folder with README.md",
+			config:      Config{Directories: []string{"test/dir_index"}},
+			directory:   "dir_index/projects",
+			wantName:    "projects/README.md",
+			wantContent: "# Projects\n\nEverything in progress.\n",
+		},
+		{
+			name:        "folder with index.md only",
+			config:      Config{Directories: []string{"test/dir_index"}},
+			directory:   "dir_index/journal/",
+			wantName:    "journal/index.md",
+			wantContent: "# Journal\n",
+		},
+		{
+			name:        "configured directory itself",
+			config:      Config{Directories: []string{"test/dir_index"}},
+			directory:   "dir_index",
+			wantName:    "README.md",
+			wantContent: "# Dir index\n",
+		},
+		{
+			name:        "configured index filenames in order",
+			config:      Config{Directories: []string{"test/dir_index"}, IndexFilenames: []string{"index.md", "README.md"}},
+			directory:   "dir_index/projects",
+			wantName:    "projects/index.md",
+			wantContent: "# Projects index\n",
+		},
+		{
+			name:      "folder with neither",
+			config:    Config{Directories: []string{"test/dir_index"}},
+			directory: "dir_index/archive",
+			wantCode:  ErrorCodeFileNotFound,
+		},
+		{
+			name:      "missing folder",
+			config:    Config{Directories: []string{"test/dir_index"}},
+			directory: "dir_index/missing",
+			wantCode:  ErrorCodeFileNotFound,
+		},
+		{
+			name:      "ignored folder",
+			config:    Config{Directories: []string{"test/dir_index"}, IgnoreDirs: []string{"^projects$"}},
+			directory: "dir_index/projects",
+			wantCode:  ErrorCodeFileNotFound,
+		},
+		{
+			name:      "traversal",
+			config:    Config{Directories: []string{"test/dir_index"}},
+			directory: "dir_index/../dir1",
+			wantCode:  ErrorCodeTraversalBlocked,
+		},
+		{
+			name:      "unknown label",
+			config:    Config{Directories: []string{"test/dir_index"}},
+			directory: "elsewhere",
+			wantCode:  ErrorCodeInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, tt.config)
+
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"directory": tt.directory}}}
+			result, err := handleReadDirectoryIndex(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+
+			if tt.wantCode != "" {
+				if !result.IsError || !strings.Contains(text, tt.wantCode) {
+					t.Errorf("Expected error code %s, got %s", tt.wantCode, text)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("Unexpected tool error: %s", text)
+			}
+
+			var response struct {
+				Name    string `json:"name"`
+				Content string `json:"content"`
+			}
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+			if response.Name != tt.wantName {
+				t.Errorf("Expected name %q, got %q", tt.wantName, response.Name)
+			}
+			if response.Content != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, response.Content)
+			}
+		})
+	}
+}
@@ -56,7 +56,7 @@ func NewMCPTestClient(t *testing.T) *MCPTestClient {
 		stdin:  stdin,
 		stdout: stdout,
 		stderr: stderr,
-		reader: bufio.NewReader(stdout),
+		reader: bufio.NewReaderSize(stdout, 1024*1024),
 	}
 
 	return client
@@ -346,15 +346,45 @@ func TestToolsList(t *testing.T) {
 	}
 
 	expectedTools := map[string]bool{
-		"find_markdown_files": false,
-	}
-
+		"find_markdown_files":  false,
+		"get_file_outline":     false,
+		"generate_toc":         false,
+		"read_section":         false,
+		"list_tasks":           false,
+		"extract_tags":         false,
+		"open_tasks":           false,
+		"get_slides":           false,
+		"extract_links":        false,
+		"find_files_by_name":   false,
+		"recent_files":         false,
+		"file_exists":          false,
+		"check_links":          false,
+		"resolve_wikilinks":    false,
+		"find_backlinks":       false,
+		"dump_frontmatter":     false,
+		"tag_cloud":            false,
+		"link_density":         false,
+		"heavy_notes":          false,
+		"fuzzy_search":         false,
+		"search_markdown":      false,
+		"file_stats":           false,
+		"vault_stats":          false,
+		"read_directory_index": false,
+		"rebuild_index":        false,
+		"server_info":          false,
+		"read_files":           false,
+	}
+
+	var findSchema map[string]any
 	for _, tool := range tools {
 		toolData := tool.(map[string]any)
 		name := toolData["name"].(string)
 		if _, exists := expectedTools[name]; exists {
 			expectedTools[name] = true
 		}
+		if name == "find_markdown_files" {
+			findSchema, _ = toolData["inputSchema"].(map[string]any)
+		}
 	}
 
 	for name, found := range expectedTools {
@@ -362,6 +392,26 @@ func TestToolsList(t *testing.T) {
 			t.Errorf("Expected tool %s not found", name)
 		}
 	}
+
+	// Parameters are typed so clients can generate forms from the schema
+	properties, _ := findSchema["properties"].(map[string]any)
+	pageSize, _ := properties["page_size"].(map[string]any)
+	if pageSize["type"] != "integer" || pageSize["minimum"] != float64(1) || pageSize["maximum"] != float64(DefaultMaxPageSize) {
+		t.Errorf("Expected page_size to be an integer from 1 to %d, got %v", DefaultMaxPageSize, pageSize)
+	}
+	if query, _ := properties["query"].(map[string]any); query["type"] != "string" {
+		t.Errorf("Expected query to be a string, got %v", query)
+	}
+	for name, want := range map[string][]any{
+		"match_mode":    {MatchModeSubstring, MatchModeFuzzy},
+		"sort":          {SortFrontmatterDate},
+		"output_format": {OutputFormatJSON, OutputFormatJSONL},
+	} {
+		property, _ := properties[name].(map[string]any)
+		if enum, _ := property["enum"].([]any); !slices.Equal(enum, want) {
+			t.Errorf("Expected %s to allow %v, got %v", name, want, property["enum"])
+		}
+	}
 }
 
 func TestErrorHandling(t *testing.T) {
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Source encodings of markdown files told apart when detect_encoding is set
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingLatin1  = "iso-8859-1"
+)
+
+// ContentEncodingBase64 returns the content of a resource read base64 encoded, as
+// a blob rather than text, for clients that mangle unusual characters in text
+const ContentEncodingBase64 = "base64"
+
+// resourceContentEncoding returns the encoding requested for the content of a
+// resource read, empty for plain text when absent
+func resourceContentEncoding(value string) (string, error) {
+	switch value {
+	case "", ContentEncodingBase64:
+		return value, nil
+	default:
+		return "", errorWithCode(ErrorCodeInvalidArgument, "invalid encoding parameter %q: must be %q", value, ContentEncodingBase64)
+	}
+}
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeText returns content as UTF-8 text together with the encoding it was
+// detected to be in. A UTF-8 byte order mark is dropped, and content that isn't
+// valid UTF-8 is taken to be Latin-1, the usual encoding of older notes, where
+// every byte is the code point of the same value.
+func decodeText(content []byte) (string, string) {
+	if bytes.HasPrefix(content, utf8BOM) {
+		return string(content[len(utf8BOM):]), EncodingUTF8BOM
+	}
+	if utf8.Valid(content) {
+		return string(content), EncodingUTF8
+	}
+
+	var b strings.Builder
+	b.Grow(len(content) * 2)
+	for _, c := range content {
+		b.WriteRune(rune(c))
+	}
+	return b.String(), EncodingLatin1
+}
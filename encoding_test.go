@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleReadMarkdownFileResourceDetectEncoding(t *testing.T) {
+	const want = "# Café\n\nCrème brûlée.\n"
+
+	tests := []struct {
+		name         string
+		filename     string
+		wantEncoding string
+	}{
+		{name: "utf-8", filename: "utf8.md", wantEncoding: EncodingUTF8},
+		{name: "utf-8 with byte order mark", filename: "bom.md", wantEncoding: EncodingUTF8BOM},
+		{name: "latin-1", filename: "latin1.md", wantEncoding: EncodingLatin1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{Directories: []string{"test/encoding"}, DetectEncoding: true})
+
+			result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "file://" + tt.filename}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content := result[0].(mcp.TextResourceContents)
+			if content.Text != want {
+				t.Errorf("Expected content %q, got %q", want, content.Text)
+			}
+			if content.Meta == nil || content.Meta.AdditionalFields["encoding"] != tt.wantEncoding {
+				t.Errorf("Expected encoding %s in meta, got %+v", tt.wantEncoding, content.Meta)
+			}
+		})
+	}
+
+	t.Run("not converted by default", func(t *testing.T) {
+		setupFileIndexTest(t, Config{Directories: []string{"test/encoding"}})
+
+		result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "file://latin1.md"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		content := result[0].(mcp.TextResourceContents)
+		if content.Text == want {
+			t.Error("Expected Latin-1 content to be returned as is")
+		}
+		if _, ok := content.Meta.AdditionalFields["encoding"]; ok {
+			t.Errorf("Expected no encoding in meta, got %+v", content.Meta)
+		}
+	})
+}
+
+func TestHandleReadMarkdownFileResourceBase64(t *testing.T) {
+	original, err := os.ReadFile("test/encoding/utf8.md")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantBlob bool
+		wantCode string
+	}{
+		{name: "base64 blob", uri: "file://utf8.md?encoding=base64", wantBlob: true},
+		{name: "plain text by default", uri: "file://utf8.md"},
+		{name: "unknown encoding", uri: "file://utf8.md?encoding=hex", wantCode: ErrorCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{Directories: []string{"test/encoding"}})
+
+			result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}})
+			if tt.wantCode != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantCode) {
+					t.Fatalf("Expected error code %s, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !tt.wantBlob {
+				text, ok := result[0].(mcp.TextResourceContents)
+				if !ok || text.Text != string(original) || text.MIMEType != "text/markdown" {
+					t.Errorf("Expected plain markdown text, got %+v", result[0])
+				}
+				return
+			}
+
+			blob, ok := result[0].(mcp.BlobResourceContents)
+			if !ok {
+				t.Fatalf("Expected BlobResourceContents, got %T", result[0])
+			}
+			if blob.MIMEType != "text/markdown" {
+				t.Errorf("Expected MIME type text/markdown, got %q", blob.MIMEType)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(blob.Blob)
+			if err != nil {
+				t.Fatalf("Expected valid base64, got %q: %v", blob.Blob, err)
+			}
+			if !bytes.Equal(decoded, original) {
+				t.Errorf("Expected round trip to %q, got %q", original, decoded)
+			}
+			if blob.Meta == nil || blob.Meta.AdditionalFields["etag"] == nil {
+				t.Errorf("Expected etag in _meta, got %+v", blob.Meta)
+			}
+		})
+	}
+}
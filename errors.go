@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Error codes sent with tool and resource errors so clients can tell failures
+// apart without parsing the message
+const (
+	ErrorCodeFileNotFound     = "FILE_NOT_FOUND"
+	ErrorCodeNotMarkdown      = "NOT_MARKDOWN"
+	ErrorCodeHeadingNotFound  = "HEADING_NOT_FOUND"
+	ErrorCodeTraversalBlocked = "TRAVERSAL_BLOCKED"
+	ErrorCodeTooLarge         = "TOO_LARGE"
+	ErrorCodeAmbiguous        = "AMBIGUOUS_FILENAME"
+	ErrorCodeInvalidArgument  = "INVALID_ARGUMENT"
+	ErrorCodeCancelled        = "CANCELLED"
+	ErrorCodeInternal         = "INTERNAL_ERROR"
+)
+
+// codedError is an error carrying one of the error codes, keeping the message of
+// the error it wraps
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// withErrorCode attaches an error code to err
+func withErrorCode(code string, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// errorWithCode formats an error with an error code attached
+func errorWithCode(code, format string, args ...any) error {
+	return withErrorCode(code, fmt.Errorf(format, args...))
+}
+
+// errorCode returns the code of an error, INTERNAL_ERROR when it has none
+func errorCode(err error) string {
+	var coded *codedError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, errFileNotFound), errors.Is(err, fs.ErrNotExist):
+		return ErrorCodeFileNotFound
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeCancelled
+	default:
+		return ErrorCodeInternal
+	}
+}
+
+// errorPayload is the JSON sent for a failed tool call or resource read
+type errorPayload struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// errorJSON renders an error as an errorPayload
+func errorJSON(err error) string {
+	var payload errorPayload
+	payload.Error.Code = errorCode(err)
+	payload.Error.Message = err.Error()
+
+	jsonData, marshalErr := json.MarshalIndent(payload, "", "  ")
+	if marshalErr != nil {
+		return err.Error()
+	}
+	return string(jsonData)
+}
+
+// toolErrorResult is the result of a failed tool call, its text the errorPayload
+func toolErrorResult(err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(errorJSON(err))
+}
+
+// resourceError is the error of a failed resource read, its message the
+// errorPayload as resource reads can only fail with a message
+type resourceError struct {
+	err error
+}
+
+func (e *resourceError) Error() string {
+	return errorJSON(e.err)
+}
+
+func (e *resourceError) Unwrap() error {
+	return e.err
+}
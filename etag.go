@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MaxConditionalBodySize is the largest request body in bytes inspected for a
+// resource read to answer conditionally, larger requests are passed on as is
+const MaxConditionalBodySize = 64 * 1024
+
+// resourceVersion identifies the version of a file served as a resource, so HTTP
+// clients can tell whether a resource they cached has changed
+type resourceVersion struct {
+	ETag    string
+	ModTime time.Time
+}
+
+// fileVersion returns the version of a file read through a resource URI. The ETag
+// hashes the URI together with the path, modification time and size of the file
+// it resolved to, as the URI options change what is served for the same file and
+// the same URI may resolve to another file, e.g. after a config reload. The
+// config settings changing what is served are hashed too.
+func fileVersion(uri, path string) (resourceVersion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return resourceVersion{}, err
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00%t", uri, path, info.ModTime().UnixNano(), info.Size(), config.DetectEncoding)
+	return resourceVersion{
+		ETag:    fmt.Sprintf(`"%016x"`, h.Sum64()),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// resolvedResourceKey is the context key of the resolvedResource of a read
+type resolvedResourceKey struct{}
+
+// resolvedResource is the file a resource URI resolved to when its read was
+// checked for being conditional, passed on to the read so it doesn't search the
+// directories for the file again
+type resolvedResource struct {
+	uri  string
+	path string
+
+	// configKey is the indexConfigKey the file was found with
+	configKey string
+}
+
+// resourceFileVersion resolves a markdown file resource URI the same way a read
+// does and returns the file it reads with its version
+func resourceFileVersion(uri string) (resolvedResource, resourceVersion, error) {
+	filename, dirs, err := resourceFilename(mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		return resolvedResource{}, resourceVersion{}, err
+	}
+	if filename == "" {
+		return resolvedResource{}, resourceVersion{}, fmt.Errorf("not a markdown file resource: %s", uri)
+	}
+
+	path, err := resolveMarkdownFileIn(dirs, filename)
+	if err != nil {
+		return resolvedResource{}, resourceVersion{}, err
+	}
+	version, err := fileVersion(uri, path)
+	if err != nil {
+		return resolvedResource{}, resourceVersion{}, err
+	}
+	return resolvedResource{uri: uri, path: path, configKey: indexConfigKey()}, version, nil
+}
+
+// resolveResourceFile resolves the file a resource read serves like
+// resolveMarkdownFileIn, taking the file the read's conditional check resolved
+// for the URI when the settings deciding which files are found haven't changed
+func resolveResourceFile(ctx context.Context, uri string, dirs []string, filename string) (string, error) {
+	if resolved, ok := ctx.Value(resolvedResourceKey{}).(resolvedResource); ok && resolved.uri == uri && resolved.configKey == indexConfigKey() {
+		return resolved.path, nil
+	}
+	return resolveMarkdownFileIn(dirs, filename)
+}
+
+// withConditionalRead answers resource reads sent over the streamable HTTP
+// transport with ETag and Last-Modified headers, and with 304 Not Modified when
+// the request's If-None-Match or If-Modified-Since shows the client already has
+// the current version. Reads whose session sessions doesn't accept, and other
+// requests, are passed on untouched.
+func withConditionalRead(sessions server.SessionIdManager, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, MaxConditionalBodySize+1))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if err != nil || len(body) > MaxConditionalBodySize {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		uri, ok := resourceReadURI(body)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Let the transport reject a read without a valid session
+		if terminated, err := sessions.Validate(r.Header.Get(server.HeaderKeySessionID)); err != nil || terminated {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		configMu.RLock()
+		resolved, version, err := resourceFileVersion(uri)
+		configMu.RUnlock()
+		if err != nil {
+			// Let the read report the error
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("ETag", version.ETag)
+		w.Header().Set("Last-Modified", version.ModTime.UTC().Format(http.TimeFormat))
+		if notModified(r, version) {
+			logger.Debug("resource not modified", "uri", uri, "etag", version.ETag)
+			resourceReadsTotal.inc()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), resolvedResourceKey{}, resolved)))
+	})
+}
+
+// resourceReadURI returns the URI of a JSON-RPC resources/read request body
+func resourceReadURI(body []byte) (string, bool) {
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return "", false
+	}
+	if request.Method != "resources/read" || request.Params.URI == "" {
+		return "", false
+	}
+	return request.Params.URI, true
+}
+
+// notModified reports whether a conditional request matches the version, with
+// If-None-Match taking precedence over If-Modified-Since as in RFC 9110
+func notModified(r *http.Request, version resourceVersion) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, etag := range strings.Split(ifNoneMatch, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || etag == version.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		// HTTP dates have a resolution of a second
+		return !version.ModTime.Truncate(time.Second).After(since)
+	}
+	return false
+}
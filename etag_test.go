@@ -0,0 +1,172 @@
+package main
+
+import (
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConditionalResourceRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{dir}, IndexTTLSeconds: -1}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	ts := httptest.NewServer(newHTTPHandler(TransportHTTP, newServer()))
+	defer ts.Close()
+
+	post := func(body, sessionID string, headers map[string]string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, ts.URL+HTTPEndpointPath, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	initialize := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`, "", nil)
+	sessionID := initialize.Header.Get("Mcp-Session-Id")
+
+	read := `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"file://notes.md"}}`
+
+	// Count the walks of the directory searching for the file
+	var walks atomic.Int64
+	oldWalkDir := walkDir
+	defer func() { walkDir = oldWalkDir }()
+	walkDir = func(root string, fn fs.WalkDirFunc) error {
+		walks.Add(1)
+		return oldWalkDir(root, fn)
+	}
+
+	first := post(read, sessionID, nil)
+	etag := first.Header.Get("ETag")
+	if first.StatusCode != http.StatusOK || etag == "" || first.Header.Get("Last-Modified") == "" {
+		t.Fatalf("Expected 200 with ETag and Last-Modified, got %d with ETag %q and Last-Modified %q",
+			first.StatusCode, etag, first.Header.Get("Last-Modified"))
+	}
+	if n := walks.Load(); n != 1 {
+		t.Errorf("Expected the read to search for the file once, walked %d times", n)
+	}
+
+	reads := resourceReadsTotal.value.Load()
+	if resp := post(read, sessionID, map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 for an unchanged file, got %d", resp.StatusCode)
+	}
+	if n := resourceReadsTotal.value.Load() - reads; n != 1 {
+		t.Errorf("Expected a read answered with 304 to be counted, counted %d", n)
+	}
+
+	// A read without a valid session is left to the transport to reject
+	if resp := post(read, "", map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a read without a session, got %d", resp.StatusCode)
+	}
+	if resp := post(read, "mcp-session-invalid", map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a read with an invalid session, got %d", resp.StatusCode)
+	}
+	if resp := post(read, sessionID, map[string]string{"If-Modified-Since": first.Header.Get("Last-Modified")}); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 when not modified since, got %d", resp.StatusCode)
+	}
+
+	// Other URI options serve different content, so have a different ETag
+	trimmed := `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"file://notes.md?trim_content=true"}}`
+	if resp := post(trimmed, sessionID, map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for different URI options, got %d", resp.StatusCode)
+	}
+
+	if err := os.WriteFile(path, []byte("# Notes\n\nChanged.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+
+	changed := post(read, sessionID, map[string]string{"If-None-Match": etag})
+	if changed.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for a changed file, got %d", changed.StatusCode)
+	}
+	if changed.Header.Get("ETag") == etag {
+		t.Errorf("Expected a new ETag for a changed file, got %q", etag)
+	}
+
+	// Requests other than resource reads are passed on untouched
+	list := post(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`, sessionID, map[string]string{"If-None-Match": "*"})
+	if list.StatusCode != http.StatusOK || list.Header.Get("ETag") != "" {
+		t.Errorf("Expected 200 without an ETag for a tool list, got %d with ETag %q", list.StatusCode, list.Header.Get("ETag"))
+	}
+}
+
+func TestFileVersion(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = Config{}
+
+	dir := t.TempDir()
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var paths []string
+	for _, sub := range []string{"a", "b"} {
+		path := filepath.Join(dir, sub, "notes.md")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# Notes\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set file time: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	etag := func(path string) string {
+		t.Helper()
+		version, err := fileVersion("file://notes.md", path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return version.ETag
+	}
+
+	first := etag(paths[0])
+	if first != etag(paths[0]) {
+		t.Error("Expected the same ETag for the same file")
+	}
+	if first == etag(paths[1]) {
+		t.Error("Expected another file of the same time and size to have a different ETag")
+	}
+
+	config.DetectEncoding = true
+	if first == etag(paths[0]) {
+		t.Error("Expected detect_encoding to change the ETag")
+	}
+}
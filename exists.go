@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func handleFileExists(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("file_exists called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	exists, err := markdownFileExists(filename)
+	if err != nil {
+		logger.Debug("file_exists failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]any{"exists": exists}, "", "  ")
+	if err != nil {
+		logger.Debug("file_exists failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal result: %w", err)), nil
+	}
+
+	logger.Debug("file_exists completed successfully", "exists", exists)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// markdownFileExists reports whether a requested filename resolves to a markdown
+// file, the same way a read would but without reading it. A name matching more
+// than one file exists even when ambiguous_read would refuse to read it, while
+// names failing the security checks are an error rather than not existing.
+func markdownFileExists(filename string) (bool, error) {
+	_, err := resolveMarkdownFile(filename)
+	if err == nil {
+		return true, nil
+	}
+	switch errorCode(err) {
+	case ErrorCodeFileNotFound:
+		return false, nil
+	case ErrorCodeAmbiguous:
+		return true, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleFileExists(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		filename   string
+		wantExists bool
+		wantCode   string
+	}{
+		{
+			name:       "existing file",
+			config:     Config{Directories: []string{"test/dir1", "test/dir2"}},
+			filename:   "foo.md",
+			wantExists: true,
+		},
+		{
+			name:       "existing file without extension",
+			config:     Config{Directories: []string{"test/dir1", "test/dir2"}},
+			filename:   "cat",
+			wantExists: true,
+		},
+		{
+			name:     "missing file",
+			config:   Config{Directories: []string{"test/dir1", "test/dir2"}},
+			filename: "missing.md",
+		},
+		{
+			name:       "ambiguous file exists when reads would fail",
+			config:     Config{Directories: []string{"test/ambiguous"}, AmbiguousRead: AmbiguousReadError},
+			filename:   "notes.md",
+			wantExists: true,
+		},
+		{
+			name:     "directory traversal",
+			config:   Config{Directories: []string{"test/dir1"}},
+			filename: "../dir2/cat.md",
+			wantCode: ErrorCodeTraversalBlocked,
+		},
+		{
+			name:     "missing filename",
+			config:   Config{Directories: []string{"test/dir1"}},
+			wantCode: ErrorCodeInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, tt.config)
+
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"filename": tt.filename}
+			result, err := handleFileExists(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+
+			if tt.wantCode != "" {
+				if !result.IsError {
+					t.Fatalf("Expected error result, got %s", text)
+				}
+				if !strings.Contains(text, tt.wantCode) {
+					t.Errorf("Expected error code %s, got %s", tt.wantCode, text)
+				}
+				return
+			}
+
+			if result.IsError {
+				t.Fatalf("Unexpected error result: %s", text)
+			}
+			var response struct {
+				Exists bool `json:"exists"`
+			}
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Exists != tt.wantExists {
+				t.Errorf("Expected exists %v, got %v", tt.wantExists, response.Exists)
+			}
+		})
+	}
+}
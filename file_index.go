@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultIndexTTL is how long the file index is used before the directories are walked again
+const DefaultIndexTTL = 30 * time.Second
+
+type indexedFile struct {
+	Path    string
+	ModTime time.Time
+
+	// Size is the size of the file on disk in bytes, compressed for gzip files
+	Size int64
+
+	// Dir is the configured directory the file was found in, as configured
+	Dir string
+}
+
+// fileIndex caches the markdown files found in the configured directories so
+// that tools don't walk every directory on every call. The index is rebuilt when
+// it is older than the TTL, when the settings that decide which files are found
+// change, or when rebuilt explicitly.
+type fileIndex struct {
+	mu        sync.RWMutex
+	files     []indexedFile
+	builtAt   time.Time
+	configKey string
+
+	// warnings describe the paths skipped when the index was built
+	warnings []string
+
+	// watchedKey is the config key of the directories a file watcher keeps the
+	// index up to date for, the TTL doesn't apply while they are configured
+	watchedKey string
+
+	// rebuilds shares a walk of the configured directories between the calls
+	// finding the index stale
+	rebuilds singleflight.Group
+
+	// generation changes when the index is invalidated, so a walk started before
+	// doesn't replace the index after
+	generation int
+
+	// changes are the file watcher changes made while walks are running, replayed
+	// onto the files they find so a walk started earlier doesn't undo them
+	changes []indexChange
+	walking int
+}
+
+// indexSnapshot is the files and warnings of a walk shared by the calls waiting
+// for it, which must copy them before making changes
+type indexSnapshot struct {
+	files    []indexedFile
+	warnings []string
+}
+
+// indexChange is a change the file watcher made to the index, adding or
+// refreshing a file or removing it
+type indexChange struct {
+	file    indexedFile
+	removed bool
+}
+
+var markdownIndex = &fileIndex{}
+
+// indexTTL returns the configured index TTL, 0 when the index is disabled
+func indexTTL() time.Duration {
+	switch {
+	case config.IndexTTLSeconds < 0:
+		return 0
+	case config.IndexTTLSeconds == 0:
+		return DefaultIndexTTL
+	default:
+		return time.Duration(config.IndexTTLSeconds) * time.Second
+	}
+}
+
+// indexConfigKey identifies the settings that decide which files are found
+func indexConfigKey() string {
+	key, err := json.Marshal(struct {
+		Directories         []string
+		IgnoreDirs          []string
+		IgnoreFiles         []string
+		AllowFiles          []string
+		Extensions          []string
+		RespectGitignore    bool
+		FollowSymlinks      bool
+		MaxDepth            int
+		ReadGzip            bool
+		DirectoryIgnoreDirs map[string][]string
+	}{config.Directories, config.IgnoreDirs, config.IgnoreFiles, config.AllowFiles, config.Extensions, config.RespectGitignore, config.FollowSymlinks, config.MaxDepth, config.ReadGzip, config.DirectoryIgnoreDirs})
+	if err != nil {
+		return ""
+	}
+	return string(key)
+}
+
+// paths returns the indexed markdown file paths, rebuilding the index first when
+// it is stale
+func (idx *fileIndex) paths() []string {
+	entries := idx.entries()
+	paths := make([]string, len(entries))
+	for i, file := range entries {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// entries returns a copy of the indexed markdown files, rebuilding the index
+// first when it is stale
+func (idx *fileIndex) entries() []indexedFile {
+	files, _ := idx.entriesContext(context.Background())
+	return files
+}
+
+// entriesContext is entries giving up when the context is done, reporting that
+// it did with no files. Without an index the walk itself stops early, returning
+// the files found so far.
+//
+// Concurrent calls finding the index stale share a single rebuild, walking in the
+// background until it finishes even when the calls waiting for it give up, so a
+// cancelled call neither leaves the index stale nor makes the next call walk again.
+func (idx *fileIndex) entriesContext(ctx context.Context) ([]indexedFile, walkStatus) {
+	ttl := indexTTL()
+	if ttl == 0 {
+		return walkAllMarkdownEntriesContext(ctx, &config)
+	}
+
+	key := indexConfigKey()
+
+	idx.mu.RLock()
+	if idx.isFresh(key, ttl) {
+		defer idx.mu.RUnlock()
+		return slices.Clone(idx.files), walkStatus{Warnings: slices.Clone(idx.warnings)}
+	}
+	idx.mu.RUnlock()
+
+	// The walk may outlive the call, and with it the config read lock, so it
+	// finds the files with a copy of the config
+	cfg := config
+	result := idx.rebuilds.DoChan(key, func() (any, error) {
+		// Another call may have rebuilt the index since this one found it stale
+		idx.mu.RLock()
+		if idx.isFresh(key, ttl) {
+			defer idx.mu.RUnlock()
+			return indexSnapshot{files: slices.Clone(idx.files), warnings: idx.warnings}, nil
+		}
+		idx.mu.RUnlock()
+		return idx.build(&cfg, key), nil
+	})
+
+	select {
+	case res := <-result:
+		snapshot := res.Val.(indexSnapshot)
+		return slices.Clone(snapshot.files), walkStatus{Warnings: slices.Clone(snapshot.warnings)}
+	case <-ctx.Done():
+		return nil, walkStatus{Truncated: true}
+	}
+}
+
+func (idx *fileIndex) isFresh(key string, ttl time.Duration) bool {
+	if idx.builtAt.IsZero() || idx.configKey != key {
+		return false
+	}
+	return key == idx.watchedKey || time.Since(idx.builtAt) < ttl
+}
+
+// rebuild walks the configured directories and replaces the index, returning the
+// number of files indexed. A walk already running may have passed files changed
+// since, so a new one is started rather than sharing it.
+func (idx *fileIndex) rebuild() int {
+	key := indexConfigKey()
+	cfg := config
+
+	idx.rebuilds.Forget(key)
+	result, _, _ := idx.rebuilds.Do(key, func() (any, error) {
+		return idx.build(&cfg, key), nil
+	})
+	return len(result.(indexSnapshot).files)
+}
+
+// build walks the directories with the settings of cfg and replaces the index
+// with the files found, replaying the file watcher changes made meanwhile, unless
+// the index was invalidated since the walk started
+func (idx *fileIndex) build(cfg *Config, key string) indexSnapshot {
+	idx.mu.Lock()
+	generation := idx.generation
+	start := len(idx.changes)
+	idx.walking++
+	idx.mu.Unlock()
+
+	files, status := walkAllMarkdownEntriesContext(context.Background(), cfg)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, change := range idx.changes[start:] {
+		files = change.apply(files)
+	}
+	idx.walking--
+	if idx.walking == 0 {
+		idx.changes = nil
+	}
+	if idx.generation == generation {
+		idx.setLocked(files, status.Warnings, key)
+	}
+	return indexSnapshot{files: slices.Clone(files), warnings: status.Warnings}
+}
+
+func (idx *fileIndex) setLocked(files []indexedFile, warnings []string, key string) {
+	idx.files = files
+	idx.warnings = warnings
+	idx.builtAt = time.Now()
+	idx.configKey = key
+}
+
+// walkAllMarkdownEntries walks each configured directory for its markdown files,
+// recording the directory each file was found in. A file reachable through
+// overlapping directories, or through symlinks, is listed once under the first
+// configured directory it was found in.
+func walkAllMarkdownEntries() []indexedFile {
+	files, _ := walkAllMarkdownEntriesContext(context.Background(), &config)
+	return files
+}
+
+// walkAllMarkdownEntriesContext is walkAllMarkdownEntries stopping early when the
+// context is done, walking the directories of cfg with its settings. It reports
+// whether it stopped early and the paths that couldn't be read.
+func walkAllMarkdownEntriesContext(ctx context.Context, cfg *Config) ([]indexedFile, walkStatus) {
+	directoryWalksTotal.inc()
+
+	type dirWalk struct {
+		files  []indexedFile
+		status walkStatus
+	}
+	walks := collectFromDirectories(cfg.Directories, func(dir string) []dirWalk {
+		var walk dirWalk
+		walk.status = walkMarkdownFilesContext(ctx, cfg, dir, func(path string, d fs.DirEntry) error {
+			file := indexedFile{Path: path, Dir: dir}
+			if info, err := d.Info(); err == nil {
+				file.ModTime = info.ModTime()
+				file.Size = info.Size()
+			}
+			walk.files = append(walk.files, file)
+			return nil
+		})
+		return []dirWalk{walk}
+	})
+
+	var files []indexedFile
+	var status walkStatus
+	for _, walk := range walks {
+		files = append(files, walk.files...)
+		status.Warnings = append(status.Warnings, walk.status.Warnings...)
+	}
+	status.Truncated = ctx.Err() != nil
+	return dedupeFiles(files), status
+}
+
+// dedupeFiles drops the files whose resolved path was already seen, keeping the
+// first occurrence. The file itself is preferred over a symlink to it.
+func dedupeFiles(files []indexedFile) []indexedFile {
+	seen := make(map[string]int, len(files))
+	isLink := make([]bool, 0, len(files))
+	unique := files[:0]
+	for _, file := range files {
+		key := file.Path
+		if resolved, err := filepath.EvalSymlinks(file.Path); err == nil {
+			key = resolved
+		}
+		info, err := os.Lstat(file.Path)
+		link := err == nil && info.Mode()&fs.ModeSymlink != 0
+		if i, ok := seen[key]; ok {
+			if isLink[i] && !link {
+				unique[i] = file
+				isLink[i] = false
+			}
+			continue
+		}
+		seen[key] = len(unique)
+		unique = append(unique, file)
+		isLink = append(isLink, link)
+	}
+	return unique
+}
+
+// setWatched records whether a file watcher is keeping the index up to date for
+// the current config
+func (idx *fileIndex) setWatched(watched bool) {
+	key := ""
+	if watched {
+		key = indexConfigKey()
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.watchedKey = key
+}
+
+// update adds or refreshes the file at path
+func (idx *fileIndex) update(path string, info fs.FileInfo) {
+	idx.change(indexChange{file: indexedFile{Path: path, ModTime: info.ModTime(), Size: info.Size(), Dir: configuredDirectory(path)}})
+}
+
+// remove drops the file at path, or every file under path when it was a directory
+func (idx *fileIndex) remove(path string) {
+	idx.change(indexChange{file: indexedFile{Path: path}, removed: true})
+}
+
+// change makes a file watcher change to the index, recording it for the walks
+// running to replay
+func (idx *fileIndex) change(change indexChange) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.files = change.apply(idx.files)
+	if idx.walking > 0 {
+		idx.changes = append(idx.changes, change)
+	}
+}
+
+// apply makes the change to files, returning the changed files
+func (change indexChange) apply(files []indexedFile) []indexedFile {
+	if change.removed {
+		prefix := change.file.Path + string(filepath.Separator)
+		kept := files[:0]
+		for _, file := range files {
+			if file.Path != change.file.Path && !strings.HasPrefix(file.Path, prefix) {
+				kept = append(kept, file)
+			}
+		}
+		return kept
+	}
+
+	for i, file := range files {
+		if file.Path == change.file.Path {
+			files[i].ModTime = change.file.ModTime
+			files[i].Size = change.file.Size
+			return files
+		}
+	}
+	return append(files, change.file)
+}
+
+// buildStartupIndex builds the index before the server starts serving, returning
+// the number of files indexed, unless the index is disabled. An index the file
+// watcher built when it started watching is kept rather than built again.
+func buildStartupIndex() (int, bool) {
+	if indexTTL() == 0 {
+		return 0, false
+	}
+	if count, built := markdownIndex.count(); built {
+		return count, true
+	}
+	return markdownIndex.rebuild(), true
+}
+
+// count returns the number of indexed files without rebuilding a stale index,
+// and whether the index has been built
+func (idx *fileIndex) count() (int, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.files), !idx.builtAt.IsZero()
+}
+
+// invalidate drops the index so the next call walks the directories again
+func (idx *fileIndex) invalidate() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.files = nil
+	idx.builtAt = time.Time{}
+	idx.configKey = ""
+	idx.watchedKey = ""
+	idx.generation++
+}
+
+func handleRebuildIndex(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("rebuild_index called")
+
+	count := markdownIndex.rebuild()
+
+	result := map[string]any{
+		"count": count,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("rebuild_index failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal result: %w", err)), nil
+	}
+
+	logger.Debug("rebuild_index completed successfully", "files_indexed", count)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupFileIndexTest(t testing.TB, cfg Config) {
+	t.Helper()
+
+	oldConfig := config
+	oldLogger := logger
+	t.Cleanup(func() {
+		config = oldConfig
+		logger = oldLogger
+		markdownIndex.invalidate()
+	})
+
+	config = cfg
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	markdownIndex.invalidate()
+}
+
+func collectMarkdownFiles(dir string) []string {
+	files, _ := collectMarkdownFilesFromDir(context.Background(), dir)
+	return files
+}
+
+func TestFileIndexMatchesWalk(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1", "test/dir2"},
+		IgnoreDirs:  []string{`\.git$`, `node_modules$`},
+		Extensions:  DefaultExtensions,
+	})
+
+	indexed := collectAllMarkdownFiles()
+	walked := walkAllMarkdownFiles()
+
+	if len(indexed) == 0 {
+		t.Fatal("Expected indexed files, got none")
+	}
+	if !reflect.DeepEqual(indexed, walked) {
+		t.Errorf("Expected indexed files to match a fresh walk\nindexed: %v\nwalked:  %v", indexed, walked)
+	}
+}
+
+func TestFileIndexRebuild(t *testing.T) {
+	dir := t.TempDir()
+	setupFileIndexTest(t, Config{Directories: []string{dir}, Extensions: DefaultExtensions})
+
+	writeNote := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Note\n"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+	}
+
+	writeNote("first.md")
+	if files := collectAllMarkdownFiles(); len(files) != 1 {
+		t.Fatalf("Expected 1 indexed file, got %v", files)
+	}
+
+	// A new file is not seen until the index is rebuilt
+	writeNote("second.md")
+	if files := collectAllMarkdownFiles(); len(files) != 1 {
+		t.Errorf("Expected cached index with 1 file, got %v", files)
+	}
+
+	if count := markdownIndex.rebuild(); count != 2 {
+		t.Errorf("Expected rebuild to index 2 files, got %d", count)
+	}
+	if files := collectAllMarkdownFiles(); len(files) != 2 {
+		t.Errorf("Expected 2 indexed files after rebuild, got %v", files)
+	}
+
+	// Changing the settings that decide which files are found rebuilds the index
+	writeNote("third.md")
+	config.IgnoreFiles = []string{`^first\.md$`}
+	if files := collectAllMarkdownFiles(); len(files) != 2 {
+		t.Errorf("Expected index rebuilt for new ignore_files, got %v", files)
+	}
+
+	// With the index disabled every call walks the directories
+	config.IndexTTLSeconds = -1
+	writeNote("fourth.md")
+	if files := collectAllMarkdownFiles(); len(files) != 3 {
+		t.Errorf("Expected a fresh walk with the index disabled, got %v", files)
+	}
+}
+
+func createBenchmarkTree(b *testing.B, files int) string {
+	b.Helper()
+
+	dir := b.TempDir()
+	for i := range files {
+		subdir := filepath.Join(dir, fmt.Sprintf("section%02d", i%20), fmt.Sprintf("topic%02d", i%7))
+		if err := os.MkdirAll(subdir, 0755); err != nil {
+			b.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(subdir, fmt.Sprintf("note%04d.md", i)), []byte("# Note\n"), 0644); err != nil {
+			b.Fatalf("Failed to write note: %v", err)
+		}
+	}
+	return dir
+}
+
+func BenchmarkFindMarkdownFilesIndexed(b *testing.B) {
+	dir := createBenchmarkTree(b, 2000)
+	setupFileIndexTest(b, Config{Directories: []string{dir}, MaxPageSize: DefaultMaxPageSize, Extensions: DefaultExtensions})
+
+	for b.Loop() {
+		if _, err := findMarkdownFiles("note1", DefaultPageSize); err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkFindMarkdownFilesWalking(b *testing.B) {
+	dir := createBenchmarkTree(b, 2000)
+	setupFileIndexTest(b, Config{Directories: []string{dir}, MaxPageSize: DefaultMaxPageSize, Extensions: DefaultExtensions, IndexTTLSeconds: -1})
+
+	for b.Loop() {
+		if _, err := findMarkdownFiles("note1", DefaultPageSize); err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestWalkAllMarkdownFilesMatchesSequentialWalk(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1", "test/dir2", "test/links", "test/missing", "test/frontmatter"},
+		IgnoreDirs:  []string{`\.git$`, `node_modules$`},
+		Extensions:  DefaultExtensions,
+	})
+
+	var sequential []string
+	for _, dir := range config.Directories {
+		sequential = append(sequential, collectMarkdownFiles(dir)...)
+	}
+
+	if parallel := walkAllMarkdownFiles(); !reflect.DeepEqual(parallel, sequential) {
+		t.Errorf("Expected the parallel walk to match a sequential walk\nparallel:   %v\nsequential: %v", parallel, sequential)
+	}
+}
+
+func BenchmarkWalkDirectoriesSequential(b *testing.B) {
+	var dirs []string
+	for range 4 {
+		dirs = append(dirs, createBenchmarkTree(b, 1000))
+	}
+	setupFileIndexTest(b, Config{Directories: dirs, Extensions: DefaultExtensions})
+
+	for b.Loop() {
+		var files []string
+		for _, dir := range dirs {
+			files = append(files, collectMarkdownFiles(dir)...)
+		}
+	}
+}
+
+func BenchmarkWalkDirectoriesParallel(b *testing.B) {
+	var dirs []string
+	for range 4 {
+		dirs = append(dirs, createBenchmarkTree(b, 1000))
+	}
+	setupFileIndexTest(b, Config{Directories: dirs, Extensions: DefaultExtensions})
+
+	for b.Loop() {
+		walkAllMarkdownFiles()
+	}
+}
+
+func TestFileIndexDeduplicatesOverlappingDirectories(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1", "test/dir1/child", "./test/dir1/nested"},
+		Extensions:  DefaultExtensions,
+	})
+
+	entries := markdownIndex.entries()
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if seen[entry.Path] {
+			t.Errorf("Expected %s to be listed once", entry.Path)
+		}
+		seen[entry.Path] = true
+		if entry.Dir != "test/dir1" {
+			t.Errorf("Expected %s to be found in the first configured directory, got %q", entry.Path, entry.Dir)
+		}
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected 4 files, got %d", len(entries))
+	}
+}
+
+func TestFileIndexDeduplicatesSymlinks(t *testing.T) {
+	notes := t.TempDir()
+	links := t.TempDir()
+	if err := os.WriteFile(filepath.Join(notes, "note.md"), []byte("# Note\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(notes, "note.md"), filepath.Join(links, "link.md")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	// The symlink is found first, but the file itself is kept
+	setupFileIndexTest(t, Config{
+		Directories:    []string{links, notes},
+		Extensions:     DefaultExtensions,
+		FollowSymlinks: true,
+	})
+
+	entries := markdownIndex.entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected the file and its symlink to be listed once, got %+v", entries)
+	}
+	if filepath.Base(entries[0].Path) != "note.md" || entries[0].Dir != notes {
+		t.Errorf("Expected note.md in %s, got %+v", notes, entries[0])
+	}
+}
+
+func TestFindStopsWhenContextCancelled(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 1000 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note-%04d.md", i)), []byte("# Note"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}, Extensions: DefaultExtensions, MaxPageSize: 2000})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	page, err := findMarkdownFilePage(ctx, findOptions{PageSize: 2000})
+	if err != nil {
+		t.Fatalf("Expected a partial result rather than an error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a cancelled find to return promptly, took %v", elapsed)
+	}
+	if !page.Truncated {
+		t.Error("Expected a cancelled find to be truncated")
+	}
+	if len(page.Files) == 1000 {
+		t.Error("Expected a cancelled find to stop before walking every file")
+	}
+
+	// The partial walk must not be cached as the index
+	if files := markdownIndex.entries(); len(files) != 1000 {
+		t.Errorf("Expected the next find to walk all 1000 files, got %d", len(files))
+	}
+}
+
+func TestFileIndexConcurrentCallsShareRebuild(t *testing.T) {
+	const callers = 50
+
+	tests := []struct {
+		name      string
+		ttl       int
+		wantWalks int64
+	}{
+		{name: "index enabled", wantWalks: 1},
+		{name: "index disabled", ttl: -1, wantWalks: callers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{
+				Directories:     []string{"test/dir1", "test/dir2"},
+				Extensions:      DefaultExtensions,
+				IndexTTLSeconds: tt.ttl,
+			})
+
+			before := directoryWalksTotal.value.Load()
+
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			counts := make([]int, callers)
+			for i := range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					counts[i] = len(markdownIndex.entries())
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if walks := directoryWalksTotal.value.Load() - before; walks != tt.wantWalks {
+				t.Errorf("Expected %d walks for %d concurrent calls, got %d", tt.wantWalks, callers, walks)
+			}
+			for i, count := range counts {
+				if count != counts[0] {
+					t.Errorf("Expected every caller to get %d files, caller %d got %d", counts[0], i, count)
+				}
+			}
+		})
+	}
+}
+
+func TestFileIndexCancelledCallSharesRebuild(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 1000 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note-%04d.md", i)), []byte("# Note"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}, Extensions: DefaultExtensions})
+
+	before := directoryWalksTotal.value.Load()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files, status := markdownIndex.entriesContext(ctx)
+	if !status.Truncated || len(files) != 0 {
+		t.Errorf("Expected a cancelled call to give up with no files, got %d files (truncated %v)", len(files), status.Truncated)
+	}
+
+	// The rebuild started by the cancelled call keeps walking and is shared
+	if files := markdownIndex.entries(); len(files) != 1000 {
+		t.Errorf("Expected the rebuild to find all 1000 files, got %d", len(files))
+	}
+	if walks := directoryWalksTotal.value.Load() - before; walks != 1 {
+		t.Errorf("Expected the cancelled call and the next one to share 1 walk, got %d", walks)
+	}
+}
+
+func TestFileIndexRebuildDuringConfigReload(t *testing.T) {
+	oldDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(oldDir, "old.md"), []byte("# Old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(newDir, "new.md"), []byte("# New"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setupFileIndexTest(t, Config{Directories: []string{oldDir}, Extensions: DefaultExtensions, MaxPageSize: DefaultMaxPageSize})
+	t.Setenv(EnvDirectories, "")
+	t.Setenv(EnvMaxPageSize, "")
+	t.Setenv(EnvSSEPort, "")
+	t.Setenv(EnvLogFile, "")
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	configData, err := json.Marshal(Config{Directories: []string{newDir}, IgnoreFiles: []string{`^old\.md$`}})
+	if err != nil {
+		t.Fatalf("Failed to marshal test config: %v", err)
+	}
+	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	// Hold the walk of the old directory until the config has been reloaded
+	walking := make(chan struct{})
+	release := make(chan struct{})
+	oldWalkDir := walkDir
+	t.Cleanup(func() { walkDir = oldWalkDir })
+	walkDir = func(root string, fn fs.WalkDirFunc) error {
+		if root == oldDir {
+			close(walking)
+			<-release
+		}
+		return oldWalkDir(root, fn)
+	}
+
+	// A rebuild of the old directory outlives the cancelled call starting it,
+	// and the config read lock that call held
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	configMu.RLock()
+	markdownIndex.entriesContext(ctx)
+	configMu.RUnlock()
+	<-walking
+
+	reloaded := make(chan error)
+	go func() { reloaded <- reloadConfig(configPath, nil) }()
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("Failed to reload config: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the reload not to wait for the running rebuild")
+	}
+
+	// Finishing after the reload, the rebuild for the old config doesn't replace
+	// the index
+	close(release)
+
+	files := markdownIndex.entries()
+	if len(files) != 1 || files[0].Path != filepath.Join(newDir, "new.md") {
+		t.Errorf("Expected only the file of the reloaded directory, got %v", files)
+	}
+}
+
+func TestFileIndexRebuildUsesConfigAtStart(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Note"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}, Extensions: DefaultExtensions})
+
+	walking := make(chan struct{})
+	release := make(chan struct{})
+	oldWalkDir := walkDir
+	t.Cleanup(func() { walkDir = oldWalkDir })
+	walkDir = func(root string, fn fs.WalkDirFunc) error {
+		close(walking)
+		<-release
+		return oldWalkDir(root, fn)
+	}
+
+	rebuilt := make(chan int)
+	go func() { rebuilt <- markdownIndex.rebuild() }()
+	<-walking
+
+	// A config swapped in while the walk runs doesn't change what it finds
+	config.IgnoreFiles = []string{`^a\.md$`}
+	close(release)
+
+	if count := <-rebuilt; count != 2 {
+		t.Errorf("Expected the rebuild to find 2 files with the config it started with, got %d", count)
+	}
+}
+
+func TestFileIndexKeepsChangesDuringRebuild(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"kept.md", "removed.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Note"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}, Extensions: DefaultExtensions})
+
+	// A walk that lists the directory, then waits for the file watcher changes
+	// before reporting what it listed
+	walking := make(chan struct{})
+	release := make(chan struct{})
+	oldWalkDir := walkDir
+	t.Cleanup(func() { walkDir = oldWalkDir })
+	walkDir = func(root string, fn fs.WalkDirFunc) error {
+		type entry struct {
+			path string
+			d    fs.DirEntry
+		}
+		var listed []entry
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			listed = append(listed, entry{path, d})
+			return err
+		})
+		close(walking)
+		<-release
+		for _, e := range listed {
+			if err := fn(e.path, e.d, nil); err != nil && err != filepath.SkipDir {
+				return err
+			}
+		}
+		return err
+	}
+
+	rebuilt := make(chan int)
+	go func() { rebuilt <- markdownIndex.rebuild() }()
+	<-walking
+
+	added := filepath.Join(dir, "added.md")
+	if err := os.WriteFile(added, []byte("# Added"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(added)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := filepath.Join(dir, "removed.md")
+	markdownIndex.update(added, info)
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	markdownIndex.remove(removed)
+
+	close(release)
+	<-rebuilt
+
+	var names []string
+	for _, file := range markdownIndex.entries() {
+		names = append(names, filepath.Base(file.Path))
+	}
+	slices.Sort(names)
+	if want := []string{"added.md", "kept.md"}; !slices.Equal(names, want) {
+		t.Errorf("Expected the changes made during the rebuild to be kept, got %v want %v", names, want)
+	}
+}
+
+func TestBuildStartupIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       int
+		watched   bool
+		wantOK    bool
+		wantWalks int64
+	}{
+		{name: "index enabled", wantOK: true, wantWalks: 1},
+		{name: "index disabled", ttl: -1, wantOK: false, wantWalks: 0},
+		{name: "index built by the file watcher", watched: true, wantOK: true, wantWalks: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{
+				Directories:     []string{"test/dir1"},
+				Extensions:      DefaultExtensions,
+				IndexTTLSeconds: tt.ttl,
+			})
+			if tt.watched {
+				markdownIndex.rebuild()
+			}
+
+			before := directoryWalksTotal.value.Load()
+			count, ok := buildStartupIndex()
+			if ok != tt.wantOK {
+				t.Errorf("Expected built %v, got %v", tt.wantOK, ok)
+			}
+			if ok && count != len(collectMarkdownFiles("test/dir1")) {
+				t.Errorf("Expected every file of test/dir1 to be indexed, got %d", count)
+			}
+			if walks := directoryWalksTotal.value.Load() - before; walks != tt.wantWalks {
+				t.Errorf("Expected %d walks, got %d", tt.wantWalks, walks)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileWatchDebounce is how long file events are collected before the index is
+// updated, so bursts of writes such as an editor saving via temp files are
+// applied once
+const FileWatchDebounce = 200 * time.Millisecond
+
+// watchFiles watches the configured directories and keeps the file index up to
+// date as markdown files are created, deleted and renamed. If the watcher fails
+// the index is instead rescanned periodically. The returned channel is closed once
+// the context is cancelled and watching has stopped.
+func watchFiles(ctx context.Context, debounce time.Duration) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	configMu.RLock()
+	for _, dir := range config.Directories {
+		addWatchTree(watcher, dir)
+	}
+	markdownIndex.rebuild()
+	markdownIndex.setWatched(true)
+	configMu.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer markdownIndex.setWatched(false)
+
+		if err := processFileEvents(ctx, watcher, debounce); err != nil {
+			logger.Warn("File watcher failed, falling back to periodic rescans", "error", err)
+			watcher.Close()
+			markdownIndex.setWatched(false)
+			rescanPeriodically(ctx)
+			return
+		}
+		watcher.Close()
+	}()
+
+	return done, nil
+}
+
+// processFileEvents applies file events to the index until the context is
+// cancelled, returning an error if the watcher fails. The config read lock is
+// held while an event is handled, as the ignore rules are read from the config
+// that a reload may be swapping.
+func processFileEvents(ctx context.Context, watcher *fsnotify.Watcher, debounce time.Duration) error {
+	pending := map[string]struct{}{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					configMu.RLock()
+					addWatchTree(watcher, event.Name)
+					configMu.RUnlock()
+				}
+			}
+			pending[event.Name] = struct{}{}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+
+		case <-timer.C:
+			configMu.RLock()
+			for path := range pending {
+				applyFileChange(path)
+			}
+			configMu.RUnlock()
+			clear(pending)
+		}
+	}
+}
+
+// applyFileChange updates the index for a path that was created, written,
+// deleted or renamed
+func applyFileChange(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Deleted or renamed away, drop it and anything that was under it
+		markdownIndex.remove(path)
+		return
+	}
+
+	if info.IsDir() {
+		// A directory moved into place, index the markdown files it contains
+		if isIndexablePath(path, true) {
+			filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if d.IsDir() && p != path && !isIndexablePath(p, true) {
+					return filepath.SkipDir
+				}
+				if !d.IsDir() && isIndexablePath(p, false) && config.checkSymlink(p) == nil {
+					if info, err := d.Info(); err == nil {
+						markdownIndex.update(p, info)
+					}
+				}
+				return nil
+			})
+		}
+		return
+	}
+
+	if isIndexablePath(path, false) && config.checkSymlink(path) == nil {
+		markdownIndex.update(path, info)
+	}
+}
+
+// addWatchTree watches a directory and its subdirectories, skipping ignored ones
+func addWatchTree(watcher *fsnotify.Watcher, dir string) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+
+	filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != absDir && !isIndexablePath(path, true) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			logger.Debug("Could not watch directory", "directory", path, "error", err)
+		}
+		return nil
+	})
+}
+
+// isIndexablePath reports whether a path within a configured directory would be
+// found by walking it, applying the same ignore rules as walkMarkdownFiles
+func isIndexablePath(path string, isDir bool) bool {
+	for _, dir := range config.Directories {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, path)
+		if err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+
+		parts := strings.Split(rel, string(filepath.Separator))
+		dirParts := parts
+		if !isDir {
+			dirParts = parts[:len(parts)-1]
+		}
+		if config.MaxDepth > 0 && len(dirParts) >= config.MaxDepth {
+			return false
+		}
+		for _, part := range dirParts {
+			if config.shouldIgnoreDir(part) || matchesAnyPattern(config.DirectoryIgnoreDirs[dir], part) {
+				return false
+			}
+		}
+
+		if config.RespectGitignore {
+			if gitignore := loadGitignore(absDir); gitignore != nil {
+				for i := range dirParts {
+					if gitignore.matches(filepath.Join(parts[:i+1]...), true) {
+						return false
+					}
+				}
+				if !isDir && gitignore.matches(rel, false) {
+					return false
+				}
+			}
+		}
+
+		if isDir {
+			return true
+		}
+		name := filepath.Base(path)
+		return config.isMarkdownFile(name) && !config.shouldIgnoreFile(name) && config.isAllowedFile(name)
+	}
+
+	return false
+}
+
+// rescanPeriodically rebuilds the file index every index TTL until the context is cancelled
+func rescanPeriodically(ctx context.Context) {
+	configMu.RLock()
+	interval := indexTTL()
+	configMu.RUnlock()
+	if interval == 0 {
+		interval = DefaultIndexTTL
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			configMu.RLock()
+			markdownIndex.rebuild()
+			configMu.RUnlock()
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWatchFilesUpdatesIndex(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing.md"), []byte("# Existing\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+		IgnoreDirs:  []string{`node_modules$`},
+		Extensions:  DefaultExtensions,
+		WatchFiles:  true,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err := watchFiles(ctx, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to watch files: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	findNames := func() []string {
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "find_markdown_files",
+				Arguments: map[string]any{},
+			},
+		}
+		result, err := handleFindMarkdownFiles(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("find_markdown_files failed: %v %v", err, result)
+		}
+
+		var response struct {
+			Files []struct {
+				Name string `json:"name"`
+			} `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("Failed to parse JSON response: %v", err)
+		}
+
+		var names []string
+		for _, file := range response.Files {
+			names = append(names, file.Name)
+		}
+		return names
+	}
+
+	waitFor := func(description string, condition func(names []string) bool) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			names := findNames()
+			if condition(names) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s, files: %v", description, names)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	if names := findNames(); !slices.Equal(names, []string{"existing.md"}) {
+		t.Fatalf("Expected only existing.md at start, got %v", names)
+	}
+
+	// Files created in ignored directories are not indexed
+	ignoredDir := filepath.Join(dir, "node_modules")
+	if err := os.MkdirAll(ignoredDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(ignoredDir, "hidden.md"), []byte("# Hidden\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+
+	newPath := filepath.Join(dir, "sub", "new.md")
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(newPath, []byte("# New\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+
+	waitFor("new.md to appear", func(names []string) bool {
+		return slices.Contains(names, "new.md")
+	})
+	if names := findNames(); slices.Contains(names, "hidden.md") {
+		t.Errorf("Expected file in ignored directory not to be indexed, got %v", names)
+	}
+
+	if err := os.Rename(newPath, filepath.Join(dir, "renamed.md")); err != nil {
+		t.Fatalf("Failed to rename note: %v", err)
+	}
+	waitFor("rename to be seen", func(names []string) bool {
+		return slices.Contains(names, "renamed.md") && !slices.Contains(names, "new.md")
+	})
+
+	if err := os.Remove(filepath.Join(dir, "existing.md")); err != nil {
+		t.Fatalf("Failed to remove note: %v", err)
+	}
+	waitFor("existing.md to be removed", func(names []string) bool {
+		return !slices.Contains(names, "existing.md")
+	})
+}
+
+// TestWatchFilesDuringConfigReload reloads the config while file events are
+// being applied, for the race detector to check the watcher reads the config
+// under the lock
+func TestWatchFilesDuringConfigReload(t *testing.T) {
+	dir := t.TempDir()
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+		Extensions:  DefaultExtensions,
+		WatchFiles:  true,
+	})
+	t.Setenv(EnvDirectories, "")
+	t.Setenv(EnvMaxPageSize, "")
+	t.Setenv(EnvSSEPort, "")
+	t.Setenv(EnvLogFile, "")
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	writeConfig := func(ignoreDirs []string) {
+		t.Helper()
+		configData, err := json.Marshal(Config{Directories: []string{dir}, IgnoreDirs: ignoreDirs, WatchFiles: true})
+		if err != nil {
+			t.Fatalf("Failed to marshal test config: %v", err)
+		}
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done, err := watchFiles(ctx, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to watch files: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	writing := make(chan struct{})
+	go func() {
+		defer close(writing)
+		for i := range 50 {
+			path := filepath.Join(dir, fmt.Sprintf("sub%d", i%5), fmt.Sprintf("note%d.md", i))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return
+			}
+			if err := os.WriteFile(path, []byte("# Note\n"), 0644); err != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	for i := 0; ; i++ {
+		select {
+		case <-writing:
+			return
+		default:
+		}
+		writeConfig([]string{fmt.Sprintf("^skip%d$", i)})
+		if err := reloadConfig(configPath, nil); err != nil {
+			t.Fatalf("Failed to reload config: %v", err)
+		}
+	}
+}
+
+func TestIsIndexablePath(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1"},
+		IgnoreDirs:  []string{`node_modules$`},
+		IgnoreFiles: []string{`^draft\.md$`},
+		Extensions:  DefaultExtensions,
+	})
+
+	root, err := filepath.Abs("test/dir1")
+	if err != nil {
+		t.Fatalf("Failed to resolve dir: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{filepath.Join(root, "note.md"), false, true},
+		{filepath.Join(root, "deep", "note.md"), false, true},
+		{filepath.Join(root, "node_modules", "note.md"), false, false},
+		{filepath.Join(root, "node_modules"), true, false},
+		{filepath.Join(root, "draft.md"), false, false},
+		{filepath.Join(root, "image.png"), false, false},
+		{filepath.Join(filepath.Dir(root), "outside.md"), false, false},
+	}
+
+	for _, tt := range tests {
+		if got := isIndexablePath(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("isIndexablePath(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
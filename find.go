@@ -1,15 +1,27 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -19,45 +31,323 @@ const (
 	DefaultMaxPageSize = 500
 )
 
+// InlineContentMaxPageSize caps the page size of find_markdown_files when file
+// content is included, as the payload grows with every file
+const InlineContentMaxPageSize = 20
+
+// InlineContentWarnFiles is the number of files with inlined content above which
+// a warning is logged
+const InlineContentWarnFiles = 10
+
+// DefaultMaxFileSize is the largest file in bytes that will be read when max_file_size is not configured
+const DefaultMaxFileSize = 10 * 1024 * 1024
+
+// DefaultSearchConcurrency is how many files a content search reads at once when
+// search_concurrency is not configured
+const DefaultSearchConcurrency = 8
+
+// ReadChunkSize is how much of a file is read at a time, checking between chunks
+// whether the request was cancelled
+const ReadChunkSize = 64 * 1024
+
+// DefaultExtensions are the markdown file extensions used when none are configured
+var DefaultExtensions = []string{".md"}
+
+// Modes of matching the find_markdown_files query against file names
+const (
+	MatchModeSubstring = "substring"
+	MatchModeFuzzy     = "fuzzy"
+)
+
+// SortFrontmatterDate orders find_markdown_files results by the frontmatter date
+// of each file, newest first
+const SortFrontmatterDate = "frontmatter_date"
+
+// Formats of the find_markdown_files result
+const (
+	OutputFormatJSON  = "json"
+	OutputFormatJSONL = "jsonl"
+)
+
+// errFindTimeout is the cause of a find stopped by find_timeout_seconds
+var errFindTimeout = errors.New("find timed out")
+
+// walkDir walks a directory tree, replaced in tests to simulate slow filesystems
+var walkDir = filepath.WalkDir
+
+// findOptions are the filters and pagination applied by findMarkdownFilesWithOptions
+type findOptions struct {
+	Query         string
+	Glob          string
+	PageSize      int
+	SearchContent bool
+	MatchMode     string
+	CaseSensitive bool
+
+	// MinSize and MaxSize bound the size of the files in bytes, 0 leaving the
+	// size unbounded
+	MinSize int64
+	MaxSize int64
+
+	// MatchPath matches the query against the path of each file relative to
+	// its configured directory rather than just its name
+	MatchPath bool
+
+	// IncludeMatchLocation reports the first line of each file matching the
+	// query when searching content
+	IncludeMatchLocation bool
+
+	// IncludeContent adds the content of each file to the results
+	IncludeContent bool
+
+	// IncludeChecksum adds the sha256 checksum of each file to the results
+	IncludeChecksum bool
+
+	// GroupByDir returns the files in groups by their subdirectory within the
+	// configured directory rather than as a flat list
+	GroupByDir bool
+
+	// Sort orders the files before pagination, by default files named exactly
+	// as the query come first and then files are ordered by name
+	Sort string
+
+	// Cursor resumes from the page after the one that returned it
+	Cursor string
+}
+
 func handleFindMarkdownFiles(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	query := extractQueryParam(req.Params.Arguments)
-	pageSize := extractPageSizeParam(req.Params.Arguments)
+	findCallsTotal.inc()
 
-	logger.Debug("find_markdown_files called", "query", query, "page_size", pageSize)
+	pageSize, err := parsePageSizeParam(req.Params.Arguments)
+	if err != nil {
+		return toolErrorResult(err), nil
+	}
 
-	files, err := findMarkdownFiles(query, pageSize)
+	minSize, err := parseSizeParam(req.Params.Arguments, "min_size")
 	if err != nil {
-		logger.Debug("find_markdown_files failed", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to find markdown files: %v", err)), nil
+		return toolErrorResult(err), nil
+	}
+	maxSize, err := parseSizeParam(req.Params.Arguments, "max_size")
+	if err != nil {
+		return toolErrorResult(err), nil
 	}
 
-	// Create file info objects with only filename (no absolute paths)
-	fileInfos := make([]map[string]any, 0, len(files))
-	for _, file := range files {
-		fileInfos = append(fileInfos, map[string]any{
-			"name": filepath.Base(file),
-		})
+	opts := findOptions{
+		Query:         extractQueryParam(req.Params.Arguments),
+		Glob:          extractStringParam(req.Params.Arguments, "glob"),
+		PageSize:      pageSize,
+		SearchContent: extractBoolParam(req.Params.Arguments, "search_content"),
+		MatchMode:     extractStringParam(req.Params.Arguments, "match_mode"),
+		CaseSensitive: extractBoolParam(req.Params.Arguments, "case_sensitive"),
+		MatchPath:     extractBoolParam(req.Params.Arguments, "match_path"),
+		MinSize:       minSize,
+		MaxSize:       maxSize,
+
+		IncludeMatchLocation: extractBoolParam(req.Params.Arguments, "include_match_location"),
+		IncludeContent:       extractBoolParam(req.Params.Arguments, "include_content"),
+		IncludeChecksum:      extractBoolParam(req.Params.Arguments, "include_checksum"),
+		GroupByDir:           extractBoolParam(req.Params.Arguments, "group_by_dir"),
+		Sort:                 extractStringParam(req.Params.Arguments, "sort"),
+		Cursor:               extractStringParam(req.Params.Arguments, "cursor"),
+	}
+	outputFormat := extractStringParam(req.Params.Arguments, "output_format")
+
+	log := requestLogger()
+	log.Debug("find_markdown_files called", "query", opts.Query, "glob", opts.Glob, "page_size", opts.PageSize, "search_content", opts.SearchContent, "match_mode", opts.MatchMode, "case_sensitive", opts.CaseSensitive, "match_path", opts.MatchPath, "min_size", opts.MinSize, "max_size", opts.MaxSize, "include_content", opts.IncludeContent, "include_checksum", opts.IncludeChecksum, "group_by_dir", opts.GroupByDir, "sort", opts.Sort, "output_format", outputFormat)
+
+	if outputFormat != "" && outputFormat != OutputFormatJSON && outputFormat != OutputFormatJSONL {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "invalid output_format %q: must be %q or %q", outputFormat, OutputFormatJSON, OutputFormatJSONL)), nil
+	}
+	if opts.Sort != "" && opts.Sort != SortFrontmatterDate {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "invalid sort %q: must be %q", opts.Sort, SortFrontmatterDate)), nil
+	}
+	if opts.Cursor != "" && (opts.Sort != "" || opts.MatchMode == MatchModeFuzzy) {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "cursor can't be combined with sort or fuzzy match_mode")), nil
+	}
+	if opts.GroupByDir && outputFormat == OutputFormatJSONL {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "group_by_dir can't be combined with output_format %q", OutputFormatJSONL)), nil
+	}
+	if opts.MatchPath && opts.MatchMode == MatchModeFuzzy {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "match_path can't be combined with fuzzy match_mode")), nil
+	}
+
+	if opts.MaxSize > 0 && opts.MinSize > opts.MaxSize {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "min_size %d must not exceed max_size %d", opts.MinSize, opts.MaxSize)), nil
+	}
+
+	// Bound the walk and content search, returning what was found when time runs out
+	if config.FindTimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, time.Duration(config.FindTimeoutSeconds)*time.Second, errFindTimeout)
+		defer cancel()
+	}
+
+	if opts.IncludeContent && (opts.PageSize <= 0 || opts.PageSize > InlineContentMaxPageSize) {
+		opts.PageSize = InlineContentMaxPageSize
+	}
+	if opts.IncludeChecksum {
+		if opts.PageSize <= 0 {
+			opts.PageSize = defaultPageSize()
+		}
+		opts.PageSize = min(opts.PageSize, ChecksumMaxPageSize)
+	}
+
+	var fileInfos []map[string]any
+	var pageFiles []indexedFile
+	var nextCursor string
+	var truncated bool
+	var warnings []string
+	var suggestions []string
+	switch opts.MatchMode {
+	case "", MatchModeSubstring:
+		page, err := findMarkdownFilePage(ctx, opts)
+		if err != nil {
+			log.Debug("find_markdown_files failed", "error", err)
+			return toolErrorResult(fmt.Errorf("failed to find markdown files: %w", err)), nil
+		}
+
+		// Create file info objects with only filename and configured directory (no absolute paths)
+		fileInfos = make([]map[string]any, 0, len(page.Files))
+		for _, file := range page.Files {
+			fileInfo := map[string]any{
+				"name":      markdownName(file.Path),
+				"directory": config.configuredDirectoryName(file.Dir),
+			}
+			if opts.SearchContent && opts.IncludeMatchLocation && opts.Query != "" {
+				if line := firstMatchLine(file.Path, opts.Query, opts.CaseSensitive); line > 0 {
+					fileInfo["match_line"] = line
+				}
+			}
+			if opts.IncludeContent {
+				addInlineContent(fileInfo, file.Path)
+			}
+			fileInfos = append(fileInfos, fileInfo)
+			pageFiles = append(pageFiles, indexedFile{Path: file.Path, Dir: file.Dir})
+		}
+		nextCursor = page.NextCursor
+		truncated = page.Truncated
+		warnings = page.Warnings
+		suggestions = page.Suggestions
+	case MatchModeFuzzy:
+		files, status, err := findMarkdownFilesFuzzy(ctx, opts)
+		if err != nil {
+			log.Debug("find_markdown_files failed", "error", err)
+			return toolErrorResult(fmt.Errorf("failed to find markdown files: %w", err)), nil
+		}
+		fileInfos = make([]map[string]any, 0, len(files))
+		for _, file := range files {
+			fileInfo := map[string]any{
+				"name":      markdownName(file.Path),
+				"directory": config.configuredDirectoryName(file.Dir),
+				"score":     file.Score,
+			}
+			if opts.IncludeContent {
+				addInlineContent(fileInfo, file.Path)
+			}
+			fileInfos = append(fileInfos, fileInfo)
+			pageFiles = append(pageFiles, indexedFile{Path: file.Path, Dir: file.Dir})
+		}
+		truncated = status.Truncated
+		warnings = status.Warnings
+	default:
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "invalid match_mode %q: must be %q or %q", opts.MatchMode, MatchModeSubstring, MatchModeFuzzy)), nil
+	}
+
+	if opts.IncludeChecksum {
+		addChecksums(ctx, fileInfos, pageFiles)
+	}
+
+	if opts.IncludeContent && len(fileInfos) > InlineContentWarnFiles {
+		log.Warn("find_markdown_files inlined the content of many files", "files", len(fileInfos))
 	}
 
 	result := map[string]any{
 		"files": fileInfos,
 		"count": len(fileInfos),
 	}
+	if nextCursor != "" {
+		result["next_cursor"] = nextCursor
+	}
+	if truncated {
+		// The request was cancelled or timed out before every file was searched
+		result["truncated"] = true
+		if errors.Is(context.Cause(ctx), errFindTimeout) {
+			result["timed_out"] = true
+		}
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if len(suggestions) > 0 {
+		result["suggestions"] = suggestions
+	}
+	if opts.GroupByDir {
+		delete(result, "files")
+		result["groups"] = groupByDirectory(fileInfos, pageFiles)
+	}
+
+	if outputFormat == OutputFormatJSONL {
+		return findResultLines(log, result, fileInfos)
+	}
 
 	jsonData, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		logger.Debug("find_markdown_files failed to marshal JSON", "error", err)
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal file list: %v", err)), nil
+		log.Debug("find_markdown_files failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal file list: %w", err)), nil
 	}
 
-	logger.Debug("find_markdown_files completed successfully", "files_found", len(files))
+	log.Debug("find_markdown_files completed successfully", "files_found", len(fileInfos), "truncated", truncated)
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
-func shouldIgnoreDir(dirName string) bool {
-	for _, pattern := range config.IgnoreDirs {
-		matched, err := regexp.MatchString(pattern, dirName)
+// findResultLines returns find results as JSON lines, one compact file object per
+// line, so a large result set can be parsed a file at a time without holding a
+// pretty-printed copy of it. The rest of the result, such as the count and the
+// next cursor, is returned in the _meta of the result.
+func findResultLines(log *slog.Logger, result map[string]any, fileInfos []map[string]any) (*mcp.CallToolResult, error) {
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, fileInfo := range fileInfos {
+		if err := encoder.Encode(fileInfo); err != nil {
+			log.Debug("find_markdown_files failed to marshal JSON line", "error", err)
+			return toolErrorResult(fmt.Errorf("failed to marshal file list: %w", err)), nil
+		}
+	}
+
+	meta := make(map[string]any, len(result))
+	for key, value := range result {
+		if key != "files" {
+			meta[key] = value
+		}
+	}
+
+	log.Debug("find_markdown_files completed successfully", "files_found", len(fileInfos), "output_format", OutputFormatJSONL)
+
+	toolResult := mcp.NewToolResultText(lines.String())
+	toolResult.Meta = &mcp.Meta{AdditionalFields: meta}
+	return toolResult, nil
+}
+
+func (c *Config) shouldIgnoreDir(dirName string) bool {
+	return matchesAnyPattern(c.IgnoreDirs, dirName)
+}
+
+func (c *Config) shouldIgnoreFile(fileName string) bool {
+	return matchesAnyPattern(c.IgnoreFiles, fileName)
+}
+
+// isAllowedFile reports whether a file name matches the allow_files patterns.
+// All files are allowed when no patterns are configured.
+func (c *Config) isAllowedFile(fileName string) bool {
+	return len(c.AllowFiles) == 0 || matchesAnyPattern(c.AllowFiles, fileName)
+}
+
+// matchesAnyPattern reports whether the name matches any of the regex patterns.
+// Invalid patterns are skipped.
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		matched, err := regexp.MatchString(pattern, name)
 		if err != nil {
 			logger.Debug("Invalid regex pattern", "pattern", pattern, "error", err)
 			continue
@@ -69,117 +359,779 @@ func shouldIgnoreDir(dirName string) bool {
 	return false
 }
 
+// markdownExtensions returns the configured markdown file extensions
+func (c *Config) markdownExtensions() []string {
+	if len(c.Extensions) == 0 {
+		return DefaultExtensions
+	}
+	return c.Extensions
+}
+
+// isMarkdownFile reports whether the name has one of the configured markdown
+// extensions, ignoring case, or is a gzip compressed markdown file when
+// read_gzip is set
+func (c *Config) isMarkdownFile(name string) bool {
+	return c.hasMarkdownExtension(name) || c.isGzipMarkdownFile(name)
+}
+
+// hasMarkdownExtension reports whether the final extension of the name is one of
+// the configured markdown extensions, ignoring case, so an extension earlier in
+// the name as in notes.md.bak doesn't count. Extensions configured without the
+// leading dot are matched as if they had one.
+func (c *Config) hasMarkdownExtension(name string) bool {
+	nameExt := filepath.Ext(name)
+	for _, ext := range c.markdownExtensions() {
+		if strings.EqualFold(nameExt, "."+strings.TrimPrefix(ext, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// findGroup is the find results in one subdirectory of the configured directories
+type findGroup struct {
+	Directory string           `json:"directory"`
+	Files     []map[string]any `json:"files"`
+}
+
+// groupByDirectory groups find results by the subdirectory each file is in,
+// relative to its configured directory and "." for files directly in it. Groups
+// are in the order their first file appears in the results, so the files keep
+// their order within each group.
+func groupByDirectory(fileInfos []map[string]any, files []indexedFile) []findGroup {
+	groups := []findGroup{}
+	groupIndexes := map[string]int{}
+	for i, fileInfo := range fileInfos {
+		directory := path.Dir(relativeToDirectory(files[i].Dir, files[i].Path))
+		index, ok := groupIndexes[directory]
+		if !ok {
+			index = len(groups)
+			groupIndexes[directory] = index
+			groups = append(groups, findGroup{Directory: directory})
+		}
+		groups[index].Files = append(groups[index].Files, fileInfo)
+	}
+	return groups
+}
+
+// addInlineContent adds the content of the file to its find result, or the error
+// reading it, e.g. when it is larger than max_file_size
+func addInlineContent(fileInfo map[string]any, path string) {
+	content, err := readFileContent(path)
+	if err != nil {
+		fileInfo["error"] = err.Error()
+		return
+	}
+	fileInfo["content"] = string(content)
+}
+
 func findMarkdownFiles(query string, pageSize int) ([]string, error) {
-	var allMarkdownFiles []string
+	return findMarkdownFilesWithOptions(findOptions{Query: query, PageSize: pageSize})
+}
 
-	// Collect all markdown files from each directory
-	for _, dir := range config.Directories {
-		files := collectMarkdownFilesFromDir(dir)
-		allMarkdownFiles = append(allMarkdownFiles, files...)
+func findMarkdownFilesWithOptions(opts findOptions) ([]string, error) {
+	entries, err := findMarkdownFileEntries(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(entries))
+	for i, entry := range entries {
+		files[i] = entry.Path
+	}
+	return files, nil
+}
+
+// findMarkdownFileEntries finds the markdown files matching the options, keeping
+// the configured directory each was found in
+func findMarkdownFileEntries(opts findOptions) ([]indexedFile, error) {
+	page, err := findMarkdownFilePage(context.Background(), opts)
+	return page.Files, err
+}
+
+// findPage is a page of find results
+type findPage struct {
+	Files []indexedFile
+
+	// NextCursor resumes from the next page, empty when this is the last page or
+	// the files are sorted by frontmatter date
+	NextCursor string
+
+	// Truncated is set when the context was done before every file was searched,
+	// in which case Files holds the matches found until then
+	Truncated bool
+
+	// Warnings describe the paths skipped as they couldn't be read
+	Warnings []string
+
+	// Suggestions are the names closest to a query that matched no files
+	Suggestions []string
+}
+
+// findMarkdownFilePage finds a page of the markdown files matching the options.
+// The walk and content search stop early when the context is done.
+func findMarkdownFilePage(ctx context.Context, opts findOptions) (findPage, error) {
+	query := opts.Query
+	pageSize := opts.PageSize
+
+	allMarkdownFiles, status := markdownIndex.entriesContext(ctx)
+	truncated := status.Truncated
+
+	// Filter by glob if provided
+	if opts.Glob != "" {
+		globbed, err := filterByGlob(allMarkdownFiles, opts.Glob, opts.CaseSensitive)
+		if err != nil {
+			return findPage{}, err
+		}
+		allMarkdownFiles = globbed
+	}
+
+	if opts.MinSize > 0 || opts.MaxSize > 0 {
+		allMarkdownFiles = filterBySize(allMarkdownFiles, opts.MinSize, opts.MaxSize)
 	}
 
 	// Filter by query if provided
-	var filteredFiles []string
+	var filteredFiles []indexedFile
+	var exactCount int
 	if query != "" {
-		queryLower := strings.ToLower(query)
-		for _, file := range allMarkdownFiles {
-			filename := strings.ToLower(filepath.Base(file))
-			if strings.Contains(filename, queryLower) {
+		if !opts.CaseSensitive {
+			query = strings.ToLower(query)
+		}
+		var exactFiles []indexedFile
+		matched := make([]bool, len(allMarkdownFiles))
+		var unmatched []indexedFile
+		var unmatchedIndexes []int
+		for i, file := range allMarkdownFiles {
+			filename := queryTarget(file, opts.MatchPath)
+			if !opts.CaseSensitive {
+				filename = strings.ToLower(filename)
+			}
+			if isExactMatch(filename, query) {
+				exactFiles = append(exactFiles, file)
+			} else if strings.Contains(filename, query) {
+				matched[i] = true
+			} else if opts.SearchContent {
+				unmatched = append(unmatched, file)
+				unmatchedIndexes = append(unmatchedIndexes, i)
+			}
+		}
+
+		// Only the files whose name doesn't match need their content read
+		if len(unmatched) > 0 {
+			for j, contains := range searchContents(ctx, unmatched, query, opts.CaseSensitive) {
+				matched[unmatchedIndexes[j]] = contains
+			}
+			truncated = truncated || ctx.Err() != nil
+		}
+
+		for i, file := range allMarkdownFiles {
+			if matched[i] {
 				filteredFiles = append(filteredFiles, file)
 			}
 		}
+
+		// Files named exactly as the query lead the results
+		exactCount = len(exactFiles)
+		if config.ExactMatch == ExactMatchOnly && len(exactFiles) > 0 {
+			filteredFiles = exactFiles
+		} else {
+			filteredFiles = append(exactFiles, filteredFiles...)
+		}
 	} else {
 		filteredFiles = allMarkdownFiles
 	}
 
-	// Apply pagination
+	// Only a query matching nothing pays for suggestions
+	var suggestions []string
+	if query != "" && len(filteredFiles) == 0 && !truncated {
+		suggestions = suggestNames(opts.Query, allMarkdownFiles, maxSuggestions())
+	}
+
 	if pageSize <= 0 || pageSize > config.MaxPageSize {
-		pageSize = DefaultPageSize
+		pageSize = defaultPageSize()
 	}
 
+	if opts.Sort == SortFrontmatterDate {
+		sortByFrontmatterDate(filteredFiles)
+		if len(filteredFiles) > pageSize {
+			filteredFiles = filteredFiles[:pageSize]
+		}
+		return findPage{Files: filteredFiles, Truncated: truncated, Warnings: status.Warnings, Suggestions: suggestions}, nil
+	}
+
+	// Order by a key unique to each file so a cursor resumes after the last file
+	// returned even when files are added or removed between pages
+	keys := make(map[string]string, len(filteredFiles))
+	for i, file := range filteredFiles {
+		keys[file.Path] = paginationKey(file, i < exactCount)
+	}
+	sort.SliceStable(filteredFiles, func(i, j int) bool {
+		return keys[filteredFiles[i].Path] < keys[filteredFiles[j].Path]
+	})
+
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return findPage{}, err
+		}
+		start := sort.Search(len(filteredFiles), func(i int) bool {
+			return keys[filteredFiles[i].Path] > after
+		})
+		filteredFiles = filteredFiles[start:]
+	}
+
+	// Apply pagination
 	if len(filteredFiles) <= pageSize {
-		return filteredFiles, nil
+		return findPage{Files: filteredFiles, Truncated: truncated, Warnings: status.Warnings, Suggestions: suggestions}, nil
+	}
+
+	files := filteredFiles[:pageSize]
+	return findPage{
+		Files:      files,
+		NextCursor: encodeCursor(keys[files[len(files)-1].Path]),
+		Truncated:  truncated,
+		Warnings:   status.Warnings,
+	}, nil
+}
+
+// queryTarget returns what the find query is matched against for a file, its
+// name, or with matchPath its slash separated path relative to the configured
+// directory it was found in, e.g. clients/acme/notes.md
+func queryTarget(file indexedFile, matchPath bool) string {
+	if !matchPath {
+		return markdownName(file.Path)
+	}
+	return relativeToDirectory(file.Dir, filepath.Join(filepath.Dir(file.Path), markdownName(file.Path)))
+}
+
+// paginationKey is the sort key of a file in find results. Files named exactly as
+// the query come first, then files are ordered by name ignoring case, with the
+// directory label and path relative to it breaking ties. Configured directories
+// can share a label, so a hash of the full path makes the key unique without
+// putting the path itself in cursors.
+func paginationKey(file indexedFile, exact bool) string {
+	rank := "1"
+	if exact {
+		rank = "0"
+	}
+	pathHash := sha256.Sum256([]byte(file.Path))
+	return rank + "\x00" + strings.ToLower(markdownName(file.Path)) + "\x00" + configuredRelativePath(file.Path) + "\x00" + hex.EncodeToString(pathHash[:8])
+}
+
+// encodeCursor makes an opaque cursor from the sort key of the last file returned
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor returns the sort key a cursor was made from
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.Contains(string(key), "\x00") {
+		return "", errorWithCode(ErrorCodeInvalidArgument, "invalid cursor %q", cursor)
+	}
+	return string(key), nil
+}
+
+// sortByFrontmatterDate orders the files by their frontmatter date, newest first.
+// Files without a parseable date are ordered by their modification time instead.
+func sortByFrontmatterDate(files []indexedFile) {
+	dates := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		date, ok := frontmatterDate(file.Path)
+		if !ok {
+			date = file.ModTime
+		}
+		dates[file.Path] = date
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return dates[files[i].Path].After(dates[files[j].Path])
+	})
+}
+
+// filterByGlob keeps the files whose name matches the shell glob pattern, ignoring
+// case unless caseSensitive is set
+func filterByGlob(files []indexedFile, pattern string, caseSensitive bool) ([]indexedFile, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, errorWithCode(ErrorCodeInvalidArgument, "invalid glob pattern %q: %v", pattern, err)
+	}
+	if !caseSensitive {
+		pattern = strings.ToLower(pattern)
+	}
+
+	var matched []indexedFile
+	for _, file := range files {
+		name := markdownName(file.Path)
+		if !caseSensitive {
+			name = strings.ToLower(name)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			matched = append(matched, file)
+		}
 	}
+	return matched, nil
+}
 
-	return filteredFiles[:pageSize], nil
+// filterBySize keeps the files of at least minSize and at most maxSize bytes, a
+// zero bound not limiting the size. Files are filtered by the size recorded when
+// the directories were walked, so no file is opened.
+func filterBySize(files []indexedFile, minSize, maxSize int64) []indexedFile {
+	var matched []indexedFile
+	for _, file := range files {
+		if file.Size < minSize || (maxSize > 0 && file.Size > maxSize) {
+			continue
+		}
+		matched = append(matched, file)
+	}
+	return matched
+}
+
+// findMarkdownFilesFuzzy scores the names of all markdown files matching the glob
+// and within the size bounds against the query and returns those scoring at
+// least MinFuzzyFilenameScore, best match first. The walk stops early when the
+// context is done, the status reporting whether it did.
+func findMarkdownFilesFuzzy(ctx context.Context, opts findOptions) ([]scoredFile, walkStatus, error) {
+	pageSize := opts.PageSize
+	if pageSize <= 0 || pageSize > config.MaxPageSize {
+		pageSize = defaultPageSize()
+	}
+
+	entries, status := markdownIndex.entriesContext(ctx)
+	if opts.Glob != "" {
+		globbed, err := filterByGlob(entries, opts.Glob, opts.CaseSensitive)
+		if err != nil {
+			return nil, status, err
+		}
+		entries = globbed
+	}
+	if opts.MinSize > 0 || opts.MaxSize > 0 {
+		entries = filterBySize(entries, opts.MinSize, opts.MaxSize)
+	}
+
+	files := []scoredFile{}
+	for _, file := range entries {
+		score := fuzzyFilenameScore(opts.Query, markdownName(file.Path))
+		if score >= MinFuzzyFilenameScore {
+			files = append(files, scoredFile{Path: file.Path, Dir: file.Dir, Score: score})
+		}
+	}
+
+	// Best score first, ties keep the directory order
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Score > files[j].Score
+	})
+
+	if len(files) > pageSize {
+		files = files[:pageSize]
+	}
+	return files, status, nil
+}
+
+// collectAllMarkdownFiles returns the markdown files of all configured directories
+// from the file index
+func collectAllMarkdownFiles() []string {
+	return markdownIndex.paths()
+}
+
+// walkAllMarkdownFiles collects the markdown files by walking each configured directory
+func walkAllMarkdownFiles() []string {
+	entries := walkAllMarkdownEntries()
+	files := make([]string, len(entries))
+	for i, entry := range entries {
+		files[i] = entry.Path
+	}
+	return files
+}
+
+// isBeyondMaxDepth reports whether the directory at path is deeper within the
+// configured directory at absDir than max_depth allows its files to be found
+func (c *Config) isBeyondMaxDepth(absDir, path string) bool {
+	if c.MaxDepth <= 0 {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, path)
+	if err != nil {
+		return false
+	}
+	return len(strings.Split(rel, string(filepath.Separator))) >= c.MaxDepth
+}
+
+// configuredDirectory returns the first configured directory, as configured, that
+// contains the path
+func configuredDirectory(path string) string {
+	for _, dir := range config.Directories {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(absDir, path); err == nil && filepath.IsLocal(rel) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// collectFromDirectories calls collect for each directory on a bounded pool of
+// workers and merges the results in the order of the directories, so the result
+// is the same as calling collect on each directory in turn
+func collectFromDirectories[T any](dirs []string, collect func(dir string) []T) []T {
+	results := make([][]T, len(dirs))
+
+	workers := min(runtime.GOMAXPROCS(0), len(dirs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = collect(dirs[i])
+			}
+		}()
+	}
+	for i := range dirs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var merged []T
+	for _, result := range results {
+		merged = append(merged, result...)
+	}
+	return merged
+}
+
+// searchConcurrency returns how many files a content search reads at once
+func searchConcurrency() int {
+	if config.SearchConcurrency > 0 {
+		return config.SearchConcurrency
+	}
+	return DefaultSearchConcurrency
+}
+
+// searchContents reports which of the files contain the query, reading them on a
+// pool of search_concurrency workers. Files are skipped once the context is done.
+// Unless caseSensitive is set the query must already be lowercased.
+func searchContents(ctx context.Context, files []indexedFile, query string, caseSensitive bool) []bool {
+	matches := make([]bool, len(files))
+
+	workers := min(searchConcurrency(), len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() == nil {
+					matches[i] = contentContains(files[i].Path, query, caseSensitive)
+				}
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return matches
+}
+
+// isExactMatch reports whether the query names the file exactly, with or without
+// its extension. Both are lowercased by the caller for case-insensitive matching.
+func isExactMatch(filename, query string) bool {
+	return filename == query ||
+		strings.TrimSuffix(filename, filepath.Ext(filename)) == query
 }
 
 func extractQueryParam(arguments any) string {
+	return extractStringParam(arguments, "query")
+}
+
+func extractStringParam(arguments any, name string) string {
 	argsMap, ok := arguments.(map[string]any)
 	if !ok {
 		return ""
 	}
 
-	queryParam, exists := argsMap["query"]
+	param, exists := argsMap[name]
 	if !exists {
 		return ""
 	}
 
-	queryStr, ok := queryParam.(string)
+	str, ok := param.(string)
 	if !ok {
 		return ""
 	}
 
-	return queryStr
+	return str
+}
+
+func extractBoolParam(arguments any, name string) bool {
+	argsMap, ok := arguments.(map[string]any)
+	if !ok {
+		return false
+	}
+
+	switch value := argsMap[name].(type) {
+	case bool:
+		return value
+	case string:
+		parsed, err := strconv.ParseBool(value)
+		return err == nil && parsed
+	default:
+		return false
+	}
+}
+
+// maxPageSize returns the configured max_page_size, DefaultMaxPageSize when unset
+func maxPageSize() int {
+	if config.MaxPageSize > 0 {
+		return config.MaxPageSize
+	}
+	return DefaultMaxPageSize
 }
 
+// defaultPageSize returns the page size used when none is requested, the
+// configured default_page_size capped at max_page_size
+func defaultPageSize() int {
+	pageSize := DefaultPageSize
+	if config.DefaultPageSize > 0 {
+		pageSize = config.DefaultPageSize
+	}
+	if config.MaxPageSize > 0 && pageSize > config.MaxPageSize {
+		pageSize = config.MaxPageSize
+	}
+	return pageSize
+}
+
+// extractPageSizeParam returns the requested page size, or the default page size
+// when it is absent or invalid
 func extractPageSizeParam(arguments any) int {
-	defaultPageSize := DefaultPageSize
+	pageSize, err := parsePageSizeParam(arguments)
+	if err != nil {
+		return defaultPageSize()
+	}
+	return pageSize
+}
 
+// parsePageSizeParam returns the requested page size, the default page size when
+// it is absent, or an error when it isn't a positive integer. Strings and JSON
+// numbers are accepted.
+func parsePageSizeParam(arguments any) (int, error) {
 	argsMap, ok := arguments.(map[string]any)
 	if !ok {
-		return defaultPageSize
+		return defaultPageSize(), nil
 	}
 
 	pageSizeParam, exists := argsMap["page_size"]
-	if !exists {
-		return defaultPageSize
+	if !exists || pageSizeParam == nil {
+		return defaultPageSize(), nil
+	}
+
+	pageSize := 0
+	switch value := pageSizeParam.(type) {
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid page_size %q: must be a positive integer", value)
+		}
+		pageSize = parsed
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid page_size %v: must be a positive integer", value)
+		}
+		pageSize = int(value)
+	case int:
+		pageSize = value
+	case int64:
+		pageSize = int(value)
+	case json.Number:
+		parsed, err := strconv.Atoi(value.String())
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid page_size %s: must be a positive integer", value)
+		}
+		pageSize = parsed
+	default:
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid page_size %v: must be a positive integer", value)
+	}
+
+	if pageSize <= 0 {
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid page_size %d: must be a positive integer", pageSize)
+	}
+	return pageSize, nil
+}
+
+// parseSizeParam returns a size in bytes requested by a tool parameter, 0 when
+// it is absent, or an error when it isn't a non-negative integer. Strings and
+// JSON numbers are accepted.
+func parseSizeParam(arguments any, name string) (int64, error) {
+	argsMap, ok := arguments.(map[string]any)
+	if !ok {
+		return 0, nil
+	}
+
+	param, exists := argsMap[name]
+	if !exists || param == nil {
+		return 0, nil
 	}
 
-	if pageSizeStr, ok := pageSizeParam.(string); ok {
-		if parsedSize, err := strconv.Atoi(pageSizeStr); err == nil {
-			return parsedSize
+	var size int64
+	switch value := param.(type) {
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s %q: must be a non-negative integer", name, value)
+		}
+		size = parsed
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s %v: must be a non-negative integer", name, value)
 		}
+		size = int64(value)
+	case int:
+		size = int64(value)
+	case int64:
+		size = value
+	case json.Number:
+		parsed, err := strconv.ParseInt(value.String(), 10, 64)
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s %s: must be a non-negative integer", name, value)
+		}
+		size = parsed
+	default:
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s %v: must be a non-negative integer", name, value)
 	}
 
-	if pageSizeFloat, ok := pageSizeParam.(float64); ok {
-		return int(pageSizeFloat)
+	if size < 0 {
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s %d: must be a non-negative integer", name, size)
 	}
+	return size, nil
+}
 
-	return defaultPageSize
+// collectMarkdownFilesFromDir returns the markdown files in a configured
+// directory, reporting whether the context was done before the walk finished
+func collectMarkdownFilesFromDir(ctx context.Context, dir string) ([]string, bool) {
+	var files []string
+	status := walkMarkdownFilesContext(ctx, &config, dir, func(path string, d fs.DirEntry) error {
+		files = append(files, path)
+		return nil
+	})
+	return files, status.Truncated
+}
+
+// walkStatus reports how a walk of configured directories went
+type walkStatus struct {
+	// Truncated is set when the context was done before the walk finished
+	Truncated bool
+
+	// Warnings describe the directories and files that couldn't be read and
+	// were skipped, e.g. a subdirectory without read permission
+	Warnings []string
+}
+
+// walkMarkdownFiles walks a configured directory calling fn with the path of each
+// markdown file, skipping ignored directories and files. fn may return
+// filepath.SkipAll to stop the walk.
+func walkMarkdownFiles(dir string, fn func(path string, d fs.DirEntry) error) {
+	walkMarkdownFilesContext(context.Background(), &config, dir, fn)
 }
 
-func collectMarkdownFilesFromDir(dir string) []string {
+// walkMarkdownFilesContext is walkMarkdownFiles stopping early when the context
+// is done, e.g. the client cancelled the request, finding the files with the
+// settings of cfg. It reports whether it did, and the paths that couldn't be read.
+func walkMarkdownFilesContext(ctx context.Context, cfg *Config, dir string, fn func(path string, d fs.DirEntry) error) walkStatus {
+	var status walkStatus
+
+	// Warnings name the directory as configured, before a leading ~ was expanded
+	name := cfg.configuredDirectoryName(dir)
+
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
 		logger.Warn("Could not resolve absolute path", "directory", dir, "error", err)
-		return nil
+		status.Warnings = append(status.Warnings, fmt.Sprintf("%s: could not resolve path", name))
+		return status
 	}
 
 	if _, err := os.Stat(absDir); os.IsNotExist(err) {
 		logger.Warn("Directory does not exist", "directory", absDir)
-		return nil
+		status.Warnings = append(status.Warnings, fmt.Sprintf("%s: directory does not exist", name))
+		return status
 	}
 
-	var files []string
-	err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+	var gitignore *gitignoreRules
+	if cfg.RespectGitignore {
+		gitignore = loadGitignore(absDir)
+	}
+
+	// Ignore patterns configured for this directory only, on top of the global ones
+	dirIgnorePatterns := cfg.DirectoryIgnoreDirs[dir]
+
+	err = walkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
+			// Skip files that can't be accessed, telling the client they were
+			logger.Debug("Skipping unreadable path", "path", path, "error", err)
+			status.Warnings = append(status.Warnings, walkWarning(name, absDir, path, err))
 			return nil
 		}
 
-		if d.IsDir() && shouldIgnoreDir(d.Name()) {
+		if d.IsDir() && (cfg.shouldIgnoreDir(d.Name()) || matchesAnyPattern(dirIgnorePatterns, d.Name())) {
 			return filepath.SkipDir
 		}
 
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
-			files = append(files, path)
+		if d.IsDir() && path != absDir && cfg.isBeyondMaxDepth(absDir, path) {
+			return filepath.SkipDir
+		}
+
+		if gitignore != nil && path != absDir {
+			if rel, err := filepath.Rel(absDir, path); err == nil && gitignore.matches(rel, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if !d.IsDir() && cfg.isMarkdownFile(d.Name()) && !cfg.shouldIgnoreFile(d.Name()) && cfg.isAllowedFile(d.Name()) {
+			if d.Type()&fs.ModeSymlink != 0 {
+				if err := cfg.checkSymlink(path); err != nil {
+					logger.Debug("Skipping symlink", "path", path, "error", err)
+					return nil
+				}
+			}
+			return fn(path, d)
 		}
 
 		return nil
 	})
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		logger.Debug("Walk stopped early", "directory", absDir, "error", err)
+		status.Truncated = true
+		return status
+	}
 	if err != nil {
 		logger.Warn("Error walking directory", "directory", absDir, "error", err)
+		status.Warnings = append(status.Warnings, walkWarning(name, absDir, absDir, err))
 	}
+	return status
+}
 
-	return files
+// walkWarning describes a path that couldn't be read relative to the configured
+// directory it is in, named as configured, so absolute paths aren't exposed to
+// clients
+func walkWarning(dirName, absDir, path string, err error) string {
+	name := dirName
+	if rel, relErr := filepath.Rel(absDir, path); relErr == nil && rel != "." {
+		name = filepath.Join(dirName, rel)
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Sprintf("%s: skipped, %v", name, err)
 }
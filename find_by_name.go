@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func handleFindFilesByName(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := extractStringParam(req.Params.Arguments, "name")
+
+	logger.Debug("find_files_by_name called", "name", name)
+
+	if name == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: name")), nil
+	}
+	if err := validateRequestedFilename(name); err != nil {
+		logger.Debug("rejected requested filename", "filename", name, "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	fileInfos := []map[string]any{}
+	for _, path := range findFilesByName(nameCandidates(name), false) {
+		dir := configuredDirectory(path)
+		fileInfos = append(fileInfos, map[string]any{
+			"name":      markdownName(path),
+			"directory": config.configuredDirectoryName(dir),
+			"path":      relativeToDirectory(dir, path),
+		})
+	}
+
+	result := map[string]any{
+		"files": fileInfos,
+		"count": len(fileInfos),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("find_files_by_name failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal file list: %w", err)), nil
+	}
+
+	logger.Debug("find_files_by_name completed successfully", "files_found", len(fileInfos))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// relativeToDirectory returns the slash separated path of a file relative to the
+// configured directory it was found in, or its name when it can't be made relative
+func relativeToDirectory(dir, path string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	rel, err := filepath.Rel(absDir, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleFindFilesByName(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/collisions"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantPaths []string
+		wantError bool
+	}{
+		{
+			name:      "all colliding files",
+			filename:  "index.md",
+			wantPaths: []string{"archive/2023/index.md", "index.md", "projects/index.md"},
+		},
+		{
+			name:      "without extension ignoring case",
+			filename:  "INDEX",
+			wantPaths: []string{"archive/2023/index.md", "index.md", "projects/index.md"},
+		},
+		{
+			name:      "single file",
+			filename:  "other",
+			wantPaths: []string{"projects/other.md"},
+		},
+		{
+			name:     "no files",
+			filename: "missing",
+		},
+		{
+			name:      "directory traversal attempt",
+			filename:  "../index.md",
+			wantError: true,
+		},
+		{
+			name:      "missing name",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"name": tt.filename}
+			result, err := handleFindFilesByName(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("Unexpected tool error: %v", result.Content)
+			}
+
+			var response struct {
+				Files []struct {
+					Name      string `json:"name"`
+					Directory string `json:"directory"`
+					Path      string `json:"path"`
+				} `json:"files"`
+				Count int `json:"count"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			var paths []string
+			for _, file := range response.Files {
+				if file.Directory != "test/collisions" {
+					t.Errorf("Expected directory test/collisions for %s, got %s", file.Path, file.Directory)
+				}
+				paths = append(paths, file.Path)
+			}
+			slices.Sort(paths)
+
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Errorf("Expected paths %v, got %v", tt.wantPaths, paths)
+			}
+			if response.Count != len(tt.wantPaths) {
+				t.Errorf("Expected count %d, got %d", len(tt.wantPaths), response.Count)
+			}
+		})
+	}
+}
@@ -3,11 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -163,9 +169,9 @@ func TestShouldIgnoreDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.dirName, func(t *testing.T) {
-			result := shouldIgnoreDir(tt.dirName)
+			result := config.shouldIgnoreDir(tt.dirName)
 			if result != tt.shouldIgnore {
-				t.Errorf("shouldIgnoreDir(%q) = %v, want %v", tt.dirName, result, tt.shouldIgnore)
+				t.Errorf("config.shouldIgnoreDir(%q) = %v, want %v", tt.dirName, result, tt.shouldIgnore)
 			}
 		})
 	}
@@ -420,3 +426,1475 @@ func TestHandleFindMarkdownFilesWithIgnoredDirs(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMarkdownFile(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	tests := []struct {
+		name       string
+		extensions []string
+		filename   string
+		want       bool
+	}{
+		{"default md", nil, "notes.md", true},
+		{"default uppercase md", nil, "NOTES.MD", true},
+		{"default rejects markdown", nil, "notes.markdown", false},
+		{"configured markdown", []string{".md", ".markdown", ".mdx"}, "notes.markdown", true},
+		{"configured mdx uppercase", []string{".md", ".markdown", ".mdx"}, "Widget.MDX", true},
+		{"configured uppercase extension", []string{".MDX"}, "widget.mdx", true},
+		{"configured rejects txt", []string{".md", ".markdown", ".mdx"}, "notes.txt", false},
+		{"md before backup extension", nil, "notes.md.bak", false},
+		{"md before txt extension", nil, "something.md.txt", false},
+		{"md in the stem", nil, "release.md.notes.md", true},
+		{"md without a dot", nil, "readmemd", false},
+		{"configured without dot", []string{"md"}, "notes.md", true},
+		{"configured without dot rejects suffix", []string{"md"}, "readmemd", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{Extensions: tt.extensions}
+			if got := config.isMarkdownFile(tt.filename); got != tt.want {
+				t.Errorf("config.isMarkdownFile(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesWithExtensions(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name       string
+		extensions []string
+		wantFiles  []string
+	}{
+		{
+			name:      "default extension",
+			wantFiles: []string{"SHOUT.MD"},
+		},
+		{
+			name:       "markdown and mdx extensions",
+			extensions: []string{".md", ".markdown", ".mdx"},
+			wantFiles:  []string{"SHOUT.MD", "guide.markdown", "widget.mdx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{
+				Directories: []string{"test/extensions"},
+				MaxPageSize: DefaultMaxPageSize,
+				Extensions:  tt.extensions,
+			}
+
+			files, err := findMarkdownFiles("", 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+		})
+	}
+}
+
+func TestShouldIgnoreFile(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		IgnoreFiles: []string{`.*\.draft\.md$`, `^TEMPLATE\.md$`, `[invalid`},
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		fileName     string
+		shouldIgnore bool
+	}{
+		{"idea.draft.md", true},
+		{"TEMPLATE.md", true},
+		{"my-TEMPLATE.md", false},
+		{"draft.md", false},
+		{"note.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			result := config.shouldIgnoreFile(tt.fileName)
+			if result != tt.shouldIgnore {
+				t.Errorf("config.shouldIgnoreFile(%q) = %v, want %v", tt.fileName, result, tt.shouldIgnore)
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesWithIgnoredFiles(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/ignore_files"},
+		MaxPageSize: DefaultMaxPageSize,
+		IgnoreFiles: []string{`.*\.draft\.md$`},
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	files, err := findMarkdownFiles("", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	slices.Sort(names)
+
+	want := []string{"TEMPLATE.md", "note.md"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+
+	if _, err := findFirstFileByName("idea.draft.md"); err == nil {
+		t.Error("Expected ignored file not to be found by name")
+	}
+}
+
+func TestFindMarkdownFilesExactMatch(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name       string
+		exactMatch string
+		query      string
+		pageSize   int
+		wantFirst  string
+		wantCount  int
+	}{
+		{
+			name:      "exact filename leads results",
+			query:     "foo.md",
+			wantFirst: "foo.md",
+			wantCount: 2,
+		},
+		{
+			name:      "exact name without extension leads results",
+			query:     "FOO",
+			wantFirst: "foo.md",
+			wantCount: 3,
+		},
+		{
+			name:      "exact match survives pagination",
+			query:     "foo",
+			pageSize:  1,
+			wantFirst: "foo.md",
+			wantCount: 1,
+		},
+		{
+			name:       "only exact matches",
+			exactMatch: ExactMatchOnly,
+			query:      "foo",
+			wantFirst:  "foo.md",
+			wantCount:  1,
+		},
+		{
+			name:       "only mode falls back to substring matches",
+			exactMatch: ExactMatchOnly,
+			query:      "notes",
+			wantFirst:  "foo-notes.md",
+			wantCount:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{
+				Directories: []string{"test/exact"},
+				MaxPageSize: DefaultMaxPageSize,
+				ExactMatch:  tt.exactMatch,
+			}
+
+			files, err := findMarkdownFiles(tt.query, tt.pageSize)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(files) != tt.wantCount {
+				t.Fatalf("Expected %d files, got %d", tt.wantCount, len(files))
+			}
+
+			if filepath.Base(files[0]) != tt.wantFirst {
+				t.Errorf("Expected %s first, got %s", tt.wantFirst, filepath.Base(files[0]))
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesWithAllowedFiles(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/allow_files"},
+		MaxPageSize: DefaultMaxPageSize,
+		AllowFiles:  []string{`^pub-`},
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	files, err := findMarkdownFiles("", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	slices.Sort(names)
+
+	wantFiles := []string{"pub-notes.md", "pub-plan.md"}
+	if !slices.Equal(names, wantFiles) {
+		t.Errorf("Expected files %v, got %v", wantFiles, names)
+	}
+
+	for _, filename := range []string{"private.md", "secret-diary"} {
+		if _, err := findFirstFileByName(filename); err == nil {
+			t.Errorf("Expected %s to be hidden by the allowlist", filename)
+		}
+	}
+	if _, err := findFirstFileByName("pub-plan"); err != nil {
+		t.Errorf("Expected pub-plan to be found, got %v", err)
+	}
+
+	// Without an allowlist all files are served
+	config.AllowFiles = nil
+	files, err = findMarkdownFiles("", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("Expected 4 files without an allowlist, got %d", len(files))
+	}
+}
+
+func TestHandleFindMarkdownFilesReportsDirectory(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1", "./test/dir2"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	for _, matchMode := range []string{MatchModeSubstring, MatchModeFuzzy} {
+		t.Run(matchMode, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"match_mode": matchMode}
+			result, err := handleFindMarkdownFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Files []struct {
+					Name      string `json:"name"`
+					Directory string `json:"directory"`
+				} `json:"files"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			wantDirectories := map[string]string{
+				"foo.md":    "test/dir1",
+				"bar.md":    "test/dir1",
+				"baz.md":    "test/dir1",
+				"README.md": "test/dir1",
+				"cat.md":    "./test/dir2",
+			}
+			if len(response.Files) != len(wantDirectories) {
+				t.Fatalf("Expected %d files, got %+v", len(wantDirectories), response.Files)
+			}
+			for _, file := range response.Files {
+				if file.Directory != wantDirectories[file.Name] {
+					t.Errorf("Expected %s in directory %q, got %q", file.Name, wantDirectories[file.Name], file.Directory)
+				}
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesWithMaxDepth(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxDepth  int
+		wantFiles []string
+	}{
+		{name: "unlimited", maxDepth: 0, wantFiles: []string{"level-one.md", "level-two.md", "top.md"}},
+		{name: "top level only", maxDepth: 1, wantFiles: []string{"top.md"}},
+		{name: "one level down", maxDepth: 2, wantFiles: []string{"level-one.md", "top.md"}},
+		{name: "deeper than the tree", maxDepth: 5, wantFiles: []string{"level-one.md", "level-two.md", "top.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{
+				Directories: []string{"test/depth"},
+				MaxPageSize: DefaultMaxPageSize,
+				MaxDepth:    tt.maxDepth,
+			})
+
+			files, err := findMarkdownFiles("", 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesCaseSensitive(t *testing.T) {
+	// Created at test time as the names would collide in a checkout on a
+	// case-insensitive filesystem
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "API.md"), []byte("# API\n\nThe Endpoint reference\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "api.md"), []byte("# api\n\nan endpoint walkthrough\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 2 {
+		t.Skip("Filesystem is not case-sensitive")
+	}
+
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	tests := []struct {
+		name      string
+		opts      findOptions
+		wantFiles []string
+	}{
+		{name: "insensitive name", opts: findOptions{Query: "API"}, wantFiles: []string{"API.md", "api.md"}},
+		{name: "sensitive upper case name", opts: findOptions{Query: "API", CaseSensitive: true}, wantFiles: []string{"API.md"}},
+		{name: "sensitive lower case name", opts: findOptions{Query: "api.md", CaseSensitive: true}, wantFiles: []string{"api.md"}},
+		{name: "insensitive content", opts: findOptions{Query: "Endpoint", SearchContent: true}, wantFiles: []string{"API.md", "api.md"}},
+		{name: "sensitive content", opts: findOptions{Query: "Endpoint", SearchContent: true, CaseSensitive: true}, wantFiles: []string{"API.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findMarkdownFilesWithOptions(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+		})
+	}
+}
+
+func TestHandleFindMarkdownFilesMatchLocation(t *testing.T) {
+	// Written at test time so the line endings aren't normalised in a checkout
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lf.md"), []byte("# LF\n\nfirst\nthe Needle here\nneedle again\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "crlf.md"), []byte("# CRLF\r\n\r\n\r\nsecond\r\nfind the needle\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "needle.md"), []byte("# Named after it\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	findLines := func(arguments map[string]any) map[string]any {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = arguments
+		result, err := handleFindMarkdownFiles(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var response struct {
+			Files []map[string]any `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		lines := map[string]any{}
+		for _, file := range response.Files {
+			lines[file["name"].(string)] = file["match_line"]
+		}
+		return lines
+	}
+
+	lines := findLines(map[string]any{"query": "needle", "search_content": true, "include_match_location": true})
+	want := map[string]any{"lf.md": 4.0, "crlf.md": 5.0, "needle.md": nil}
+	for name, wantLine := range want {
+		if gotLine, ok := lines[name]; !ok || gotLine != wantLine {
+			t.Errorf("Expected %s to report match_line %v, got %v", name, wantLine, lines[name])
+		}
+	}
+
+	lines = findLines(map[string]any{"query": "needle", "search_content": true, "include_match_location": true, "case_sensitive": true})
+	if lines["lf.md"] != 5.0 {
+		t.Errorf("Expected a case-sensitive match on line 5 of lf.md, got %v", lines["lf.md"])
+	}
+
+	lines = findLines(map[string]any{"query": "needle", "search_content": true})
+	for name, line := range lines {
+		if line != nil {
+			t.Errorf("Expected no match_line for %s without include_match_location, got %v", name, line)
+		}
+	}
+}
+
+func TestFindMarkdownFilesWithGlob(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/glob"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	tests := []struct {
+		name      string
+		opts      findOptions
+		wantFiles []string
+		wantError bool
+	}{
+		{
+			name:      "all markdown files",
+			opts:      findOptions{Glob: "*.md"},
+			wantFiles: []string{"2024-01-standup.md", "2024-02-retro.md", "2024-02-standup.md", "foo.md", "foobar.md", "notes.md"},
+		},
+		{
+			name:      "prefix",
+			opts:      findOptions{Glob: "foo*"},
+			wantFiles: []string{"foo.md", "foobar.md"},
+		},
+		{
+			name:      "middle wildcard",
+			opts:      findOptions{Glob: "2024-*-standup.md"},
+			wantFiles: []string{"2024-01-standup.md", "2024-02-standup.md"},
+		},
+		{
+			name:      "ignores case",
+			opts:      findOptions{Glob: "FOO*"},
+			wantFiles: []string{"foo.md", "foobar.md"},
+		},
+		{
+			name: "case sensitive",
+			opts: findOptions{Glob: "FOO*", CaseSensitive: true},
+		},
+		{
+			name:      "combined with query",
+			opts:      findOptions{Glob: "2024-02-*", Query: "retro"},
+			wantFiles: []string{"2024-02-retro.md"},
+		},
+		{
+			name:      "invalid pattern",
+			opts:      findOptions{Glob: "[2024"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findMarkdownFilesWithOptions(tt.opts)
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+		})
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"glob": "[2024"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "invalid glob pattern") {
+		t.Errorf("Expected an invalid glob pattern tool error, got %v", result.Content)
+	}
+}
+
+func TestHandleFindMarkdownFilesIncludeContent(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/dir1"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	findFiles := func(arguments map[string]any) map[string]map[string]any {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = arguments
+		result, err := handleFindMarkdownFiles(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var response struct {
+			Files []map[string]any `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		files := map[string]map[string]any{}
+		for _, file := range response.Files {
+			files[file["name"].(string)] = file
+		}
+		return files
+	}
+
+	files := findFiles(map[string]any{"include_content": true})
+	wantContent := map[string]string{
+		"foo.md": "# Foo\n\nFoo markdown document\n",
+		"bar.md": "# Bar\n\nBar markdown document\n",
+	}
+	for name, want := range wantContent {
+		if got := files[name]["content"]; got != want {
+			t.Errorf("Expected content of %s to be %q, got %v", name, want, got)
+		}
+	}
+
+	for name, file := range findFiles(map[string]any{}) {
+		if _, ok := file["content"]; ok {
+			t.Errorf("Expected no content for %s by default", name)
+		}
+	}
+
+	// Files over the size limit report an error instead
+	limit := int64(10)
+	config.MaxFileSize = &limit
+	files = findFiles(map[string]any{"query": "foo", "include_content": "true"})
+	if _, ok := files["foo.md"]["content"]; ok {
+		t.Error("Expected no content for a file over max_file_size")
+	}
+	if errText, _ := files["foo.md"]["error"].(string); !strings.Contains(errText, "too large") {
+		t.Errorf("Expected a too large error, got %v", files["foo.md"]["error"])
+	}
+}
+
+func TestHandleFindMarkdownFilesIncludeContentPageCap(t *testing.T) {
+	dir := t.TempDir()
+	for i := range InlineContentMaxPageSize + 5 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note%02d.md", i)), []byte("# Note\n"), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"include_content": true, "page_size": "100"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != InlineContentMaxPageSize {
+		t.Errorf("Expected the page to be capped at %d files, got %d", InlineContentMaxPageSize, response.Count)
+	}
+}
+
+func TestHandleFindMarkdownFilesGroupByDir(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/group_by_dir"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"group_by_dir": true}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	var response struct {
+		Groups []struct {
+			Directory string           `json:"directory"`
+			Files     []map[string]any `json:"files"`
+		} `json:"groups"`
+		Files []map[string]any `json:"files"`
+		Count int              `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response.Files != nil {
+		t.Errorf("Expected no flat files list, got %v", response.Files)
+	}
+	if response.Count != 5 {
+		t.Errorf("Expected count 5, got %d", response.Count)
+	}
+
+	got := map[string][]string{}
+	for _, group := range response.Groups {
+		if _, ok := got[group.Directory]; ok {
+			t.Errorf("Expected one group for %s", group.Directory)
+		}
+		for _, file := range group.Files {
+			got[group.Directory] = append(got[group.Directory], file["name"].(string))
+			if file["directory"] != "test/group_by_dir" {
+				t.Errorf("Expected files to keep their configured directory, got %v", file["directory"])
+			}
+		}
+		sort.Strings(got[group.Directory])
+	}
+	want := map[string][]string{
+		".":             {"inbox.md"},
+		"journal/2024":  {"january.md"},
+		"projects":      {"acme.md", "beta.md"},
+		"projects/acme": {"notes.md"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected groups %v, got %v", want, got)
+	}
+}
+
+func TestHandleFindMarkdownFilesGroupByDirJSONL(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/group_by_dir"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"group_by_dir": true, "output_format": OutputFormatJSONL}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected tool error combining group_by_dir with jsonl output")
+	}
+}
+
+func TestHandleFindMarkdownFilesTildeDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, "notes"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "notes", "note.md"), []byte("# Note\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "markdown-reader-mcp.json")
+	if err := os.WriteFile(configPath, []byte(`{"directories": ["~/notes"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	setupFileIndexTest(t, *cfg)
+
+	result, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if strings.Contains(text, home) {
+		t.Errorf("Expected no home directory path in the result, got %s", text)
+	}
+
+	var response struct {
+		Files []map[string]any `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response.Files) != 1 || response.Files[0]["directory"] != "~/notes" {
+		t.Errorf("Expected note.md in ~/notes, got %v", response.Files)
+	}
+}
+
+func TestHandleFindMarkdownFilesIncludeChecksum(t *testing.T) {
+	dir := t.TempDir()
+	notePath := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(notePath, []byte("# Note\n\nFirst draft\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	findChecksum := func(arguments map[string]any) any {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = arguments
+		result, err := handleFindMarkdownFiles(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var response struct {
+			Files []map[string]any `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if len(response.Files) != 1 {
+			t.Fatalf("Expected 1 file, got %d", len(response.Files))
+		}
+		return response.Files[0]["checksum"]
+	}
+
+	if checksum, ok := findChecksum(map[string]any{}).(string); ok {
+		t.Errorf("Expected no checksum by default, got %s", checksum)
+	}
+
+	before, _ := findChecksum(map[string]any{"include_checksum": true}).(string)
+	if len(before) != 64 {
+		t.Fatalf("Expected a sha256 hex checksum, got %q", before)
+	}
+	if again, _ := findChecksum(map[string]any{"include_checksum": true}).(string); again != before {
+		t.Errorf("Expected the checksum of an unchanged file to be stable, got %s then %s", before, again)
+	}
+
+	if err := os.WriteFile(notePath, []byte("# Note\n\nSecond draft\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit fixture: %v", err)
+	}
+	if after, _ := findChecksum(map[string]any{"include_checksum": true}).(string); after == before {
+		t.Errorf("Expected the checksum to change after editing the file, still %s", after)
+	}
+}
+
+func TestHandleFindMarkdownFilesIncludeChecksumPageCap(t *testing.T) {
+	dir := t.TempDir()
+	for i := range ChecksumMaxPageSize + 5 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note%03d.md", i)), []byte("# Note\n"), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"include_checksum": true, "page_size": "200"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != ChecksumMaxPageSize {
+		t.Errorf("Expected the page to be capped at %d files, got %d", ChecksumMaxPageSize, response.Count)
+	}
+}
+
+func TestFindMarkdownFilesSortByFrontmatterDate(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/frontmatter_date"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	files, err := findMarkdownFilesWithOptions(findOptions{Sort: SortFrontmatterDate})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+
+	want := []string{"charlie.md", "delta.md", "alpha.md", "bravo.md"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+}
+
+func TestFindMarkdownFilesSortByFrontmatterDateFallsBackToModTime(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := []struct {
+		name    string
+		content string
+		modTime time.Time
+	}{
+		{"dated.md", "---\ndate: 2024-01-15\n---\n# Dated\n", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"undated.md", "# Undated\n", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"unparseable.md", "---\ndate: someday\n---\n# Unparseable\n", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, fixture := range fixtures {
+		path := filepath.Join(dir, fixture.name)
+		if err := os.WriteFile(path, []byte(fixture.content), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+		if err := os.Chtimes(path, fixture.modTime, fixture.modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+	setupFileIndexTest(t, Config{
+		Directories: []string{dir},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	files, err := findMarkdownFilesWithOptions(findOptions{Sort: SortFrontmatterDate})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+
+	want := []string{"undated.md", "dated.md", "unparseable.md"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"sort": "name"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error for an unknown sort")
+	}
+}
+
+func TestHandleFindMarkdownFilesCursorPagination(t *testing.T) {
+	dir := t.TempDir()
+	writeNote := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Note\n"), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+	for _, name := range []string{"delta.md", "alpha.md", "echo.md", "charlie.md", "bravo.md"} {
+		writeNote(name)
+	}
+	setupFileIndexTest(t, Config{
+		Directories:     []string{dir},
+		MaxPageSize:     DefaultMaxPageSize,
+		IndexTTLSeconds: -1,
+	})
+
+	findPage := func(arguments map[string]any) ([]string, string) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = arguments
+		result, err := handleFindMarkdownFiles(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Unexpected tool error: %v", result.Content)
+		}
+
+		var response struct {
+			Files []struct {
+				Name string `json:"name"`
+			} `json:"files"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		var names []string
+		for _, file := range response.Files {
+			names = append(names, file.Name)
+		}
+		return names, response.NextCursor
+	}
+
+	first, cursor := findPage(map[string]any{"page_size": "3"})
+	if want := []string{"alpha.md", "bravo.md", "charlie.md"}; !slices.Equal(first, want) {
+		t.Errorf("Expected first page %v, got %v", want, first)
+	}
+	if cursor == "" {
+		t.Fatal("Expected a next_cursor on the first page")
+	}
+	if key, err := decodeCursor(cursor); err != nil || strings.Contains(key, dir) {
+		t.Errorf("Expected a cursor without the directory path, got %q (%v)", key, err)
+	}
+
+	// A file added before the cursor doesn't shift the next page
+	writeNote("aardvark.md")
+
+	second, cursor := findPage(map[string]any{"page_size": "3", "cursor": cursor})
+	if want := []string{"delta.md", "echo.md"}; !slices.Equal(second, want) {
+		t.Errorf("Expected second page %v, got %v", want, second)
+	}
+	if cursor != "" {
+		t.Errorf("Expected no next_cursor on the last page, got %q", cursor)
+	}
+
+	// Files at the same path in directories sharing a label each get a page
+	dirA := filepath.Join(t.TempDir(), "notes")
+	dirB := filepath.Join(t.TempDir(), "notes")
+	for _, sameLabelDir := range []string{dirA, dirB} {
+		if err := os.MkdirAll(sameLabelDir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(sameLabelDir, "todo.md"), []byte("# Todo\n"), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+	config.Directories = []string{dirA, dirB}
+	first, cursor = findPage(map[string]any{"page_size": "1"})
+	second, cursor = findPage(map[string]any{"page_size": "1", "cursor": cursor})
+	if len(first) != 1 || len(second) != 1 || cursor != "" {
+		t.Errorf("Expected todo.md from each directory on its own page, got %v then %v", first, second)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"cursor": "not a cursor"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "invalid cursor") {
+		t.Errorf("Expected an invalid cursor error, got %v", result.Content)
+	}
+	var payload errorPayload
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &payload); err != nil || payload.Error.Code != ErrorCodeInvalidArgument {
+		t.Errorf("Expected an %s error payload, got %v", ErrorCodeInvalidArgument, result.Content)
+	}
+}
+
+func TestHandleFindMarkdownFilesPageSizeParam(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/glob"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	tests := []struct {
+		name      string
+		pageSize  any
+		wantCount int
+		wantError bool
+	}{
+		{name: "absent uses default", wantCount: 6},
+		{name: "valid string", pageSize: "2", wantCount: 2},
+		{name: "JSON number", pageSize: float64(3), wantCount: 3},
+		{name: "integer", pageSize: 4, wantCount: 4},
+		{name: "junk string", pageSize: "abc", wantError: true},
+		{name: "zero", pageSize: 0, wantError: true},
+		{name: "negative string", pageSize: "-1", wantError: true},
+		{name: "fraction", pageSize: 2.5, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arguments := map[string]any{}
+			if tt.pageSize != nil {
+				arguments["page_size"] = tt.pageSize
+			}
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = arguments
+			result, err := handleFindMarkdownFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.wantError {
+				if !result.IsError || !strings.Contains(text, "must be a positive integer") {
+					t.Errorf("Expected a page_size error, got %q", text)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("Unexpected tool error: %s", text)
+			}
+
+			var response struct {
+				Count int `json:"count"`
+			}
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Count != tt.wantCount {
+				t.Errorf("Expected %d files, got %d", tt.wantCount, response.Count)
+			}
+		})
+	}
+}
+
+func TestHandleFindMarkdownFilesUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions aren't enforced for root")
+	}
+
+	tests := []struct {
+		name        string
+		tilde       bool
+		wantWarning string
+	}{
+		{name: "directory configured by path"},
+		{name: "directory configured under ~", tilde: true, wantWarning: "~/vault/locked: skipped, permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			dir := filepath.Join(home, "vault")
+			locked := filepath.Join(dir, "locked")
+			if err := os.MkdirAll(locked, 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "readable.md"), []byte("# Readable"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(locked, "hidden.md"), []byte("# Hidden"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			if err := os.Chmod(locked, 0); err != nil {
+				t.Fatalf("Failed to chmod directory: %v", err)
+			}
+			t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+			cfg := Config{Directories: []string{dir}, Extensions: DefaultExtensions, MaxPageSize: DefaultMaxPageSize}
+			if tt.tilde {
+				t.Setenv("HOME", home)
+				cfg.DirectoryNames = map[string]string{dir: "~/vault"}
+			}
+			setupFileIndexTest(t, cfg)
+
+			result, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Files    []map[string]any `json:"files"`
+				Warnings []string         `json:"warnings"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			if len(response.Files) != 1 {
+				t.Errorf("Expected the readable file to be found, got %v", response.Files)
+			}
+			if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "locked") || !strings.Contains(response.Warnings[0], "permission denied") {
+				t.Fatalf("Expected a permission denied warning for the locked directory, got %v", response.Warnings)
+			}
+			if tt.wantWarning != "" && response.Warnings[0] != tt.wantWarning {
+				t.Errorf("Expected warning %q, got %q", tt.wantWarning, response.Warnings[0])
+			}
+		})
+	}
+}
+
+func TestHandleFindMarkdownFilesMissingTildeDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "missing")
+	setupFileIndexTest(t, Config{
+		Directories:    []string{dir},
+		DirectoryNames: map[string]string{dir: "~/missing"},
+		Extensions:     DefaultExtensions,
+		MaxPageSize:    DefaultMaxPageSize,
+	})
+
+	result, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if want := []string{"~/missing: directory does not exist"}; !slices.Equal(response.Warnings, want) {
+		t.Errorf("Expected warnings %v, got %v", want, response.Warnings)
+	}
+}
+
+func TestHandleFindMarkdownFilesNoWarnings(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/dir1"}, Extensions: DefaultExtensions, MaxPageSize: DefaultMaxPageSize})
+
+	result, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "warnings") {
+		t.Errorf("Expected no warnings when every directory is readable, got %s", text)
+	}
+}
+
+func TestFindMarkdownFilesMatchPath(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/match_path"}, MaxPageSize: DefaultMaxPageSize})
+
+	tests := []struct {
+		name      string
+		opts      findOptions
+		wantFiles []string
+	}{
+		{
+			name:      "name only by default",
+			opts:      findOptions{Query: "acme"},
+			wantFiles: []string{"acme-summary.md"},
+		},
+		{
+			name:      "folder name matches with match_path",
+			opts:      findOptions{Query: "acme", MatchPath: true},
+			wantFiles: []string{"acme-summary.md", "clients/acme/2024/notes.md"},
+		},
+		{
+			name:      "query spanning folders",
+			opts:      findOptions{Query: "acme/2024", MatchPath: true},
+			wantFiles: []string{"clients/acme/2024/notes.md"},
+		},
+		{
+			name:      "ignores case",
+			opts:      findOptions{Query: "GLOBEX", MatchPath: true},
+			wantFiles: []string{"clients/globex/notes.md"},
+		},
+		{
+			name: "case sensitive",
+			opts: findOptions{Query: "GLOBEX", MatchPath: true, CaseSensitive: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findMarkdownFileEntries(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, file := range files {
+				paths = append(paths, relativeToDirectory(file.Dir, file.Path))
+			}
+			slices.Sort(paths)
+
+			if !slices.Equal(paths, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, paths)
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesSuggestions(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		sort            string
+		maxSuggestions  int
+		wantCount       int
+		wantSuggestions []string
+	}{
+		{name: "near miss", query: "meetng-notes", wantSuggestions: []string{"meeting-notes.md"}},
+		{name: "near miss sorted by date", query: "meetng-notes", sort: SortFrontmatterDate, wantSuggestions: []string{"meeting-notes.md"}},
+		{name: "near miss with extension", query: "budgte-2024.md", wantSuggestions: []string{"budget-2024.md"}},
+		{name: "no plausible name", query: "zebra"},
+		{name: "matching query", query: "meeting", wantCount: 1},
+		{name: "disabled", query: "meetng-notes", maxSuggestions: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{Directories: []string{"test/fuzzy_names"}, MaxPageSize: DefaultMaxPageSize, MaxSuggestions: tt.maxSuggestions})
+
+			arguments := map[string]any{"query": tt.query}
+			if tt.sort != "" {
+				arguments["sort"] = tt.sort
+			}
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}}
+			result, err := handleFindMarkdownFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Count       int      `json:"count"`
+				Suggestions []string `json:"suggestions"`
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+
+			if response.Count != tt.wantCount {
+				t.Errorf("Expected %d files, got %d", tt.wantCount, response.Count)
+			}
+			if !reflect.DeepEqual(response.Suggestions, tt.wantSuggestions) {
+				t.Errorf("Expected suggestions %q, got %q", tt.wantSuggestions, response.Suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggestNamesLimit(t *testing.T) {
+	var files []indexedFile
+	for _, name := range []string{"note1.md", "note2.md", "note3.md", "note4.md", "note5.md", "note6.md", "notes.md"} {
+		files = append(files, indexedFile{Path: filepath.Join("notes", name)})
+	}
+
+	want := []string{"notes.md", "note1.md", "note2.md", "note3.md", "note4.md"}
+	if got := suggestNames("notess", files, DefaultMaxSuggestions); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected suggestions %q, got %q", want, got)
+	}
+}
+
+func TestHandleFindMarkdownFilesSize(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/file_size"}, MaxPageSize: DefaultMaxPageSize})
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		wantFiles []string
+		wantError bool
+	}{
+		{name: "no bounds", args: map[string]any{}, wantFiles: []string{"long.md", "medium.md", "stub.md"}},
+		{name: "minimum leaves out stubs", args: map[string]any{"min_size": float64(100)}, wantFiles: []string{"long.md", "medium.md"}},
+		{name: "maximum", args: map[string]any{"max_size": "1000"}, wantFiles: []string{"medium.md", "stub.md"}},
+		{name: "both bounds", args: map[string]any{"min_size": 100, "max_size": 1000}, wantFiles: []string{"medium.md"}},
+		{name: "bounds are inclusive", args: map[string]any{"min_size": 7, "max_size": 7}, wantFiles: []string{"stub.md"}},
+		{name: "combined with query", args: map[string]any{"query": "stub", "min_size": 100}, wantFiles: []string{}},
+		{name: "fuzzy mode", args: map[string]any{"query": "medum", "match_mode": "fuzzy", "max_size": 100}, wantFiles: []string{}},
+		{name: "negative size", args: map[string]any{"min_size": -1}, wantError: true},
+		{name: "minimum above maximum", args: map[string]any{"min_size": 1000, "max_size": 100}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+			result, err := handleFindMarkdownFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Fatalf("Expected tool error %v, got %v", tt.wantError, result.Content)
+			}
+			if tt.wantError {
+				return
+			}
+
+			var response struct {
+				Files []struct {
+					Name string `json:"name"`
+				} `json:"files"`
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+
+			names := []string{}
+			for _, file := range response.Files {
+				names = append(names, file.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantFiles) {
+				t.Errorf("Expected files %q, got %q", tt.wantFiles, names)
+			}
+		})
+	}
+}
+
+func TestHandleFindMarkdownFilesJSONLines(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/dir1", "test/dir2"}, MaxPageSize: DefaultMaxPageSize})
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"output_format": "jsonl", "page_size": 2}}}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasSuffix(text, "\n") {
+		t.Errorf("Expected every line to end with a newline, got %q", text)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for _, line := range lines {
+		var file map[string]any
+		if err := json.Unmarshal([]byte(line), &file); err != nil {
+			t.Fatalf("Expected line to be valid JSON, got %q: %v", line, err)
+		}
+		if name, _ := file["name"].(string); name == "" {
+			t.Errorf("Expected file object with a name, got %q", line)
+		}
+	}
+
+	if result.Meta == nil {
+		t.Fatal("Expected count in _meta")
+	}
+	count, _ := result.Meta.AdditionalFields["count"].(int)
+	if count != 2 || len(lines) != count {
+		t.Errorf("Expected 2 lines matching the count, got %d lines and count %v", len(lines), result.Meta.AdditionalFields["count"])
+	}
+	if result.Meta.AdditionalFields["next_cursor"] == nil {
+		t.Errorf("Expected next_cursor in _meta, got %v", result.Meta.AdditionalFields)
+	}
+}
+
+func TestHandleFindMarkdownFilesInvalidOutputFormat(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize})
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"output_format": "csv"}}}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected tool error for unknown output_format")
+	}
+}
+
+func TestHandleFindMarkdownFilesTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]any
+		walkDelay    time.Duration
+		wantTimedOut bool
+	}{
+		{name: "slow walk times out", walkDelay: 300 * time.Millisecond, wantTimedOut: true},
+		{name: "fast walk completes", wantTimedOut: false},
+		{name: "slow fuzzy walk times out", args: map[string]any{"query": "foo", "match_mode": MatchModeFuzzy}, walkDelay: 300 * time.Millisecond, wantTimedOut: true},
+		{name: "fast fuzzy walk completes", args: map[string]any{"query": "foo", "match_mode": MatchModeFuzzy}, wantTimedOut: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize, IndexTTLSeconds: -1, FindTimeoutSeconds: 1})
+
+			// Simulate a slow network filesystem taking a while for every entry
+			oldWalkDir := walkDir
+			t.Cleanup(func() { walkDir = oldWalkDir })
+			walkDir = func(root string, fn fs.WalkDirFunc) error {
+				return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+					time.Sleep(tt.walkDelay)
+					return fn(path, d, err)
+				})
+			}
+
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			result, err := handleFindMarkdownFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Count     int  `json:"count"`
+				Truncated bool `json:"truncated"`
+				TimedOut  bool `json:"timed_out"`
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+
+			if response.TimedOut != tt.wantTimedOut || response.Truncated != tt.wantTimedOut {
+				t.Errorf("Expected timed_out and truncated %v, got %+v", tt.wantTimedOut, response)
+			}
+			if tt.args != nil {
+				return
+			}
+			// test/dir1 holds 4 markdown files, a timed out walk returns only those found in time
+			if tt.wantTimedOut && response.Count >= 4 {
+				t.Errorf("Expected fewer than 4 files from a timed out walk, got %d", response.Count)
+			}
+			if !tt.wantTimedOut && response.Count != 4 {
+				t.Errorf("Expected 4 files, got %d", response.Count)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+func handleDumpFrontmatter(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := extractQueryParam(req.Params.Arguments)
+	pageSize := extractPageSizeParam(req.Params.Arguments)
+
+	logger.Debug("dump_frontmatter called", "query", query, "page_size", pageSize)
+
+	files, err := findMarkdownFiles(query, pageSize)
+	if err != nil {
+		logger.Debug("dump_frontmatter failed", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to find markdown files: %w", err)), nil
+	}
+
+	notes := make([]map[string]any, 0, len(files))
+	for _, file := range files {
+		note := map[string]any{
+			"name": markdownName(file),
+		}
+
+		content, err := readFileContent(file)
+		if err != nil {
+			logger.Debug("dump_frontmatter could not read file", "file", file, "error", err)
+			note["error"] = fmt.Sprintf("failed to read file: %v", err)
+			notes = append(notes, note)
+			continue
+		}
+
+		frontmatter, err := parseFrontmatter(string(content))
+		if err != nil {
+			note["error"] = err.Error()
+		} else {
+			note["frontmatter"] = flattenFrontmatter(frontmatter)
+		}
+		notes = append(notes, note)
+	}
+
+	result := map[string]any{
+		"notes": notes,
+		"count": len(notes),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("dump_frontmatter failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal frontmatter: %w", err)), nil
+	}
+
+	logger.Debug("dump_frontmatter completed successfully", "notes", len(notes))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// splitFrontmatter separates a leading YAML frontmatter block, delimited by
+// "---" lines, from the body of the document. When there is no frontmatter the
+// returned frontmatter is empty and the body is the full content. bodyLine is
+// the 1-based line number on which the body starts.
+func splitFrontmatter(content string) (frontmatter string, body string, bodyLine int) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r\n") != "---" {
+		return "", content, 1
+	}
+
+	for i := 1; i < len(lines); i++ {
+		delimiter := strings.TrimRight(lines[i], "\r\n")
+		if delimiter == "---" || delimiter == "..." {
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], ""), i + 2
+		}
+	}
+
+	// An unterminated block is not frontmatter
+	return "", content, 1
+}
+
+// parseFrontmatter parses the YAML frontmatter of a document. A document
+// without frontmatter yields an empty map.
+func parseFrontmatter(content string) (map[string]any, error) {
+	frontmatter, _, _ := splitFrontmatter(content)
+
+	data := map[string]any{}
+	if strings.TrimSpace(frontmatter) == "" {
+		return data, nil
+	}
+
+	if err := yaml.Unmarshal([]byte(frontmatter), &data); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %v", err)
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	return data, nil
+}
+
+// MaxFrontmatterHeadBytes is how much of a file is read when only its frontmatter
+// is needed
+const MaxFrontmatterHeadBytes = 64 * 1024
+
+// frontmatterDateLayouts are the formats tried when parsing a frontmatter date
+var frontmatterDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// readFrontmatterHead reads the head of a file up to the end of its frontmatter
+// block, so the frontmatter can be parsed without reading the whole file. At most
+// MaxFrontmatterHeadBytes are read.
+func readFrontmatterHead(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	markdown, err := markdownReader(file)
+	if err != nil {
+		return "", err
+	}
+
+	reader := bufio.NewReader(markdown)
+	var head strings.Builder
+	for head.Len() < MaxFrontmatterHeadBytes {
+		line, err := reader.ReadString('\n')
+		head.WriteString(line)
+		delimiter := strings.TrimRight(line, "\r\n")
+		if head.Len() == len(line) {
+			if delimiter != "---" {
+				return "", nil
+			}
+		} else if delimiter == "---" || delimiter == "..." {
+			break
+		}
+		if err != nil {
+			break
+		}
+	}
+	return head.String(), nil
+}
+
+// frontmatterDate returns the date field of the frontmatter of a file, and
+// whether it was present and parseable
+func frontmatterDate(path string) (time.Time, bool) {
+	head, err := readFrontmatterHead(path)
+	if err != nil || head == "" {
+		return time.Time{}, false
+	}
+
+	frontmatter, err := parseFrontmatter(head)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	switch date := frontmatter["date"].(type) {
+	case time.Time:
+		return date, true
+	case string:
+		return parseFrontmatterDate(date)
+	}
+	return time.Time{}, false
+}
+
+// frontmatterTitle returns the title field of the frontmatter of a file, and
+// whether it was present
+func frontmatterTitle(path string) (string, bool) {
+	head, err := readFrontmatterHead(path)
+	if err != nil || head == "" {
+		return "", false
+	}
+
+	frontmatter, err := parseFrontmatter(head)
+	if err != nil {
+		return "", false
+	}
+
+	title, ok := frontmatter["title"].(string)
+	if !ok || strings.TrimSpace(title) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(title), true
+}
+
+// parseFrontmatterDate parses a date such as 2024-01-15 or an RFC3339 timestamp
+func parseFrontmatterDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range frontmatterDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// flattenFrontmatter flattens nested maps into dot separated keys, e.g.
+// {"author": {"name": "x"}} becomes {"author.name": "x"}. Lists are kept as values.
+func flattenFrontmatter(data map[string]any) map[string]any {
+	flat := map[string]any{}
+
+	var flatten func(prefix string, value any)
+	flatten = func(prefix string, value any) {
+		nested, ok := value.(map[string]any)
+		if !ok || len(nested) == 0 {
+			flat[prefix] = value
+			return
+		}
+		for key, v := range nested {
+			flatten(prefix+"."+key, v)
+		}
+	}
+
+	for key, value := range data {
+		flatten(key, value)
+	}
+
+	return flat
+}
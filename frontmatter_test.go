@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		wantFrontmatter string
+		wantBody        string
+		wantBodyLine    int
+	}{
+		{
+			name:            "with frontmatter",
+			content:         "---\ntitle: Test\n---\n# Body\n",
+			wantFrontmatter: "title: Test\n",
+			wantBody:        "# Body\n",
+			wantBodyLine:    4,
+		},
+		{
+			name:         "without frontmatter",
+			content:      "# Body\n",
+			wantBody:     "# Body\n",
+			wantBodyLine: 1,
+		},
+		{
+			name:         "unterminated frontmatter",
+			content:      "---\ntitle: Test\n",
+			wantBody:     "---\ntitle: Test\n",
+			wantBodyLine: 1,
+		},
+		{
+			name:            "CRLF line endings",
+			content:         "---\r\ntitle: Test\r\n---\r\nBody",
+			wantFrontmatter: "title: Test\r\n",
+			wantBody:        "Body",
+			wantBodyLine:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontmatter, body, bodyLine := splitFrontmatter(tt.content)
+			if frontmatter != tt.wantFrontmatter {
+				t.Errorf("frontmatter = %q, want %q", frontmatter, tt.wantFrontmatter)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if bodyLine != tt.wantBodyLine {
+				t.Errorf("bodyLine = %d, want %d", bodyLine, tt.wantBodyLine)
+			}
+		})
+	}
+}
+
+func TestHandleDumpFrontmatter(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/frontmatter"}, MaxPageSize: DefaultMaxPageSize}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "dump_frontmatter",
+			Arguments: map[string]any{},
+		},
+	}
+
+	result, err := handleDumpFrontmatter(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool returned error: %v", result.Content)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected TextContent, got %T", result.Content[0])
+	}
+
+	var response struct {
+		Notes []struct {
+			Name        string         `json:"name"`
+			Frontmatter map[string]any `json:"frontmatter"`
+			Error       string         `json:"error"`
+		} `json:"notes"`
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	if response.Count != 4 {
+		t.Fatalf("Expected 4 notes, got %d", response.Count)
+	}
+
+	notes := make(map[string]map[string]any)
+	errors := make(map[string]string)
+	for _, note := range response.Notes {
+		notes[note.Name] = note.Frontmatter
+		errors[note.Name] = note.Error
+	}
+
+	project := notes["project.md"]
+	if project["title"] != "Project Plan" {
+		t.Errorf("Expected project title, got %v", project["title"])
+	}
+	if project["author.name"] != "Sam" || project["author.team"] != "docs" {
+		t.Errorf("Expected flattened author fields, got %v", project)
+	}
+	if tags, ok := project["tags"].([]any); !ok || len(tags) != 2 {
+		t.Errorf("Expected two tags, got %v", project["tags"])
+	}
+
+	journal := notes["journal.md"]
+	if journal["title"] != "Journal" || journal["draft"] != true {
+		t.Errorf("Expected journal fields, got %v", journal)
+	}
+	if _, ok := journal["date"]; !ok {
+		t.Errorf("Expected journal date field, got %v", journal)
+	}
+
+	if plain, ok := notes["plain.md"]; !ok || len(plain) != 0 {
+		t.Errorf("Expected empty frontmatter for plain.md, got %v", plain)
+	}
+
+	if errors["broken.md"] == "" {
+		t.Error("Expected error for broken.md frontmatter")
+	}
+}
+
+func TestParseFrontmatterDate(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Time
+		wantOK bool
+	}{
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true},
+		{"2024-01-15T09:30:00Z", time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC), true},
+		{"2024-01-15T09:30:00+02:00", time.Date(2024, 1, 15, 7, 30, 0, 0, time.UTC), true},
+		{"2024-01-15 09:30", time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC), true},
+		{" 2024-01-15 ", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true},
+		{"15/01/2024", time.Time{}, false},
+		{"someday", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseFrontmatterDate(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok %v, got %v", tt.wantOK, ok)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
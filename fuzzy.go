@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MinFuzzyScore is the lowest match quality returned by fuzzy_search
+const MinFuzzyScore = 0.75
+
+// maxFuzzyQueryTokens bounds the work done per file for long queries
+const maxFuzzyQueryTokens = 16
+
+// MinFuzzyFilenameScore is the lowest file name score returned by find_markdown_files
+// in fuzzy match mode
+const MinFuzzyFilenameScore = 0.5
+
+// scoredFile is a file matched by find_markdown_files in fuzzy match mode
+type scoredFile struct {
+	Path  string
+	Dir   string
+	Score float64
+}
+
+type fuzzyMatch struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+func handleFuzzySearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := extractQueryParam(req.Params.Arguments)
+	pageSize := extractPageSizeParam(req.Params.Arguments)
+
+	logger.Debug("fuzzy_search called", "query", query, "page_size", pageSize)
+
+	if strings.TrimSpace(query) == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: query")), nil
+	}
+
+	if pageSize <= 0 || pageSize > config.MaxPageSize {
+		pageSize = defaultPageSize()
+	}
+
+	matches := fuzzySearch(query, pageSize)
+
+	result := map[string]any{
+		"results": matches,
+		"count":   len(matches),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("fuzzy_search failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal results: %w", err)), nil
+	}
+
+	logger.Debug("fuzzy_search completed successfully", "results_found", len(matches))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// fuzzySearch scores every markdown file against the query and returns the best
+// matches, highest score first
+func fuzzySearch(query string, limit int) []fuzzyMatch {
+	queryTokens := tokenize(query)
+	if len(queryTokens) > maxFuzzyQueryTokens {
+		queryTokens = queryTokens[:maxFuzzyQueryTokens]
+	}
+	if len(queryTokens) == 0 {
+		return []fuzzyMatch{}
+	}
+
+	matches := []fuzzyMatch{}
+	for _, file := range collectAllMarkdownFiles() {
+		content, err := fileContent(file)
+		if err != nil {
+			logger.Debug("fuzzy_search could not read file", "file", file, "error", err)
+			continue
+		}
+
+		score := fuzzyScore(queryTokens, uniqueTokens(content))
+		if score >= MinFuzzyScore {
+			matches = append(matches, fuzzyMatch{Name: markdownName(file), Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// fuzzyScore averages, over the query tokens, the similarity of the closest
+// word in the document. A score of 1 means every query word appears exactly.
+func fuzzyScore(queryTokens []string, docTokens map[string]struct{}) float64 {
+	total := 0.0
+	for _, q := range queryTokens {
+		if _, ok := docTokens[q]; ok {
+			total++
+			continue
+		}
+
+		best := 0.0
+		for d := range docTokens {
+			if s := similarity(q, d, best); s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+
+	// Round to keep the JSON output readable
+	return float64(int(total/float64(len(queryTokens))*100+0.5)) / 100
+}
+
+// similarity returns 1 - editDistance/maxLength, or 0 when the words can't beat floor
+func similarity(a, b string, floor float64) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// The length difference alone is a lower bound on the edit distance
+	if 1-float64(abs(len(ra)-len(rb)))/float64(longest) <= floor {
+		return 0
+	}
+
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance is the Levenshtein distance between two words
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// tokenize lowercases text and splits it into words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTokens(text string) map[string]struct{} {
+	tokens := map[string]struct{}{}
+	for _, token := range tokenize(text) {
+		tokens[token] = struct{}{}
+	}
+	return tokens
+}
+
+// fuzzyFilenameScore scores from 0 to 1 how well a file name matches a query,
+// ignoring case, the extension and punctuation so readme matches READ-ME.md.
+// A name containing the query scores from 0.9, a name containing the query's
+// characters in order from 0.6, and otherwise the edit distance similarity to the
+// whole name or its closest word is used, so typos still match.
+func fuzzyFilenameScore(query, filename string) float64 {
+	queryKey := strings.Join(tokenize(query), "")
+	nameWords := tokenize(strings.TrimSuffix(filename, filepath.Ext(filename)))
+	nameKey := strings.Join(nameWords, "")
+	if queryKey == "" {
+		// Like substring matching, an empty query matches every file
+		return 1
+	}
+	if nameKey == "" {
+		return 0
+	}
+
+	queryLen := float64(len([]rune(queryKey)))
+	nameLen := float64(len([]rune(nameKey)))
+	coverage := min(queryLen/nameLen, 1)
+
+	var score float64
+	switch {
+	case strings.Contains(nameKey, queryKey):
+		score = 0.9 + 0.1*coverage
+	case isSubsequence(queryKey, nameKey):
+		score = 0.6 + 0.3*coverage
+	default:
+		score = similarity(queryKey, nameKey, 0)
+		for _, word := range nameWords {
+			score = max(score, similarity(queryKey, word, score))
+		}
+		// A typo is a weaker signal than containing the query
+		score = min(score, 0.85)
+	}
+
+	// Round to keep the JSON output readable
+	return float64(int(score*100+0.5)) / 100
+}
+
+// isSubsequence reports whether the characters of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	rs := []rune(s)
+	i := 0
+	for _, r := range sub {
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+		if i == len(rs) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFuzzySearch(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	config = Config{Directories: []string{"test/fuzzy"}, MaxPageSize: DefaultMaxPageSize}
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	matches := fuzzySearch("quartely roadmap to ambitous", 10)
+	if len(matches) == 0 {
+		t.Fatalf("Expected misspelled query to match, got none")
+	}
+	if matches[0].Name != "retro.md" {
+		t.Errorf("Expected retro.md to rank first, got %v", matches)
+	}
+	for _, m := range matches {
+		if m.Name == "recipes.md" {
+			t.Errorf("Expected unrelated note not to match, got %v", matches)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"kitten", "sitting", 3},
+		{"roadmap", "roadmap", 0},
+		{"", "abc", 3},
+		{"quartely", "quarterly", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestFuzzyFilenameScore(t *testing.T) {
+	tests := []struct {
+		query    string
+		filename string
+		want     float64
+	}{
+		{"readme", "READ-ME.md", 1},
+		{"read", "READ-ME.md", 0.97},
+		{"rdme", "READ-ME.md", 0.8},
+		{"reamde", "READ-ME.md", 0.67},
+		{"meetnig", "meeting-notes.md", 0.71},
+		{"budget", "project-plan.md", 0.29},
+		{"", "project-plan.md", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.filename, func(t *testing.T) {
+			if got := fuzzyFilenameScore(tt.query, tt.filename); got != tt.want {
+				t.Errorf("fuzzyFilenameScore(%q, %q) = %v, want %v", tt.query, tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMarkdownFilesFuzzyRecall(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/fuzzy_names"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		query         string
+		wantSubstring []string
+		wantFuzzy     []string
+	}{
+		{query: "readme", wantSubstring: nil, wantFuzzy: []string{"READ-ME.md"}},
+		{query: "meetnig", wantSubstring: nil, wantFuzzy: []string{"meeting-notes.md"}},
+		{query: "projplan", wantSubstring: nil, wantFuzzy: []string{"project-plan.md"}},
+		{query: "budget", wantSubstring: []string{"budget-2024.md"}, wantFuzzy: []string{"budget-2024.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			files, err := findMarkdownFiles(tt.query, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var substringNames []string
+			for _, file := range files {
+				substringNames = append(substringNames, filepath.Base(file))
+			}
+			if !slices.Equal(substringNames, tt.wantSubstring) {
+				t.Errorf("Substring: expected %v, got %v", tt.wantSubstring, substringNames)
+			}
+
+			fuzzyFiles, _, err := findMarkdownFilesFuzzy(context.Background(), findOptions{Query: tt.query})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var fuzzyNames []string
+			for _, file := range fuzzyFiles {
+				fuzzyNames = append(fuzzyNames, filepath.Base(file.Path))
+			}
+			if !slices.Equal(fuzzyNames, tt.wantFuzzy) {
+				t.Errorf("Fuzzy: expected %v, got %v", tt.wantFuzzy, fuzzyNames)
+			}
+		})
+	}
+}
+
+func TestHandleFindMarkdownFilesFuzzyMode(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/fuzzy_names"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"query": "plan", "match_mode": "fuzzy"}
+	result, err := handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response struct {
+		Files []struct {
+			Name  string   `json:"name"`
+			Score *float64 `json:"score"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(response.Files) == 0 || response.Files[0].Name != "project-plan.md" {
+		t.Fatalf("Expected project-plan.md first, got %+v", response.Files)
+	}
+	for i, file := range response.Files {
+		if file.Score == nil {
+			t.Fatalf("Expected a score for %s", file.Name)
+		}
+		if i > 0 && *file.Score > *response.Files[i-1].Score {
+			t.Errorf("Expected files ordered by score, got %+v", response.Files)
+		}
+	}
+
+	req.Params.Arguments = map[string]any{"query": "plan", "match_mode": "regex"}
+	result, err = handleFindMarkdownFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error for an unknown match mode")
+	}
+}
+
+func TestFindMarkdownFilesFuzzyGlob(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/fuzzy_names"},
+		MaxPageSize: DefaultMaxPageSize,
+	})
+
+	tests := []struct {
+		name string
+		opts findOptions
+		want []string
+	}{
+		{name: "no glob", opts: findOptions{Query: "meeting"}, want: []string{"meeting-notes.md"}},
+		{name: "glob excludes the match", opts: findOptions{Query: "meeting", Glob: "project-*"}, want: nil},
+		{name: "glob ignores case", opts: findOptions{Query: "meeting", Glob: "MEETING-*"}, want: []string{"meeting-notes.md"}},
+		{name: "case sensitive glob", opts: findOptions{Query: "meeting", Glob: "MEETING-*", CaseSensitive: true}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, _, err := findMarkdownFilesFuzzy(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file.Path))
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, names)
+			}
+		})
+	}
+
+	if _, _, err := findMarkdownFilesFuzzy(context.Background(), findOptions{Query: "meeting", Glob: "[meeting"}); err == nil {
+		t.Error("Expected an error for an invalid glob")
+	}
+}
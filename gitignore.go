@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreRules holds the patterns of a .gitignore file at the root of a configured
+// directory. Only simple glob patterns are supported; negation ("!pattern") and
+// .gitignore files in subdirectories are not.
+type gitignoreRules struct {
+	patterns []gitignorePattern
+}
+
+type gitignorePattern struct {
+	glob     string
+	dirOnly  bool
+	anchored bool
+}
+
+// loadGitignore reads the .gitignore in the directory, returning nil when there is none
+func loadGitignore(dir string) *gitignoreRules {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Debug("Could not read .gitignore", "directory", dir, "error", err)
+		}
+		return nil
+	}
+	defer file.Close()
+
+	rules := &gitignoreRules{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			logger.Debug("Negated .gitignore patterns are not supported", "pattern", line)
+			continue
+		}
+
+		pattern := gitignorePattern{}
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// A pattern containing a slash is relative to the .gitignore location
+		if strings.Contains(line, "/") {
+			pattern.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		pattern.glob = line
+
+		rules.patterns = append(rules.patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Debug("Error reading .gitignore", "directory", dir, "error", err)
+	}
+
+	return rules
+}
+
+// matches reports whether the path, relative to the .gitignore directory, is ignored
+func (r *gitignoreRules) matches(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	name := relPath[strings.LastIndex(relPath, "/")+1:]
+
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+
+		target := name
+		if pattern.anchored {
+			target = relPath
+		}
+
+		if matched, err := filepath.Match(pattern.glob, target); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
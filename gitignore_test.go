@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// createGitignoreTree creates a directory with a .gitignore excluding a build
+// directory. It is created at test time as git would honour the .gitignore and
+// drop the ignored files from a committed fixture.
+func createGitignoreTree(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":             "# Build output\nbuild/\n*.tmp.md\n/notes/private.md\n!build/keep.md\n",
+		"index.md":               "# Index\n",
+		"scratch.tmp.md":         "# Scratch\n",
+		"build/generated.md":     "# Generated\n",
+		"build/keep.md":          "# Keep build\n",
+		"notes/keep.md":          "# Keep\n",
+		"notes/private.md":       "# Private\n",
+		"notes/sub/build.md":     "# Not a directory\n",
+		"other/notes/private.md": "# Not anchored here\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	return dir
+}
+
+func TestCollectMarkdownFilesRespectGitignore(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	dir := createGitignoreTree(t)
+
+	tests := []struct {
+		name             string
+		respectGitignore bool
+		wantFiles        []string
+	}{
+		{
+			name:             "gitignore respected",
+			respectGitignore: true,
+			wantFiles:        []string{"index.md", "notes/keep.md", "notes/sub/build.md", "other/notes/private.md"},
+		},
+		{
+			name:             "gitignore not respected by default",
+			respectGitignore: false,
+			wantFiles: []string{
+				"build/generated.md", "build/keep.md", "index.md", "notes/keep.md",
+				"notes/private.md", "notes/sub/build.md", "other/notes/private.md", "scratch.tmp.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{
+				Directories:      []string{dir},
+				IgnoreDirs:       []string{`\.git$`},
+				RespectGitignore: tt.respectGitignore,
+			}
+
+			var files []string
+			for _, file := range collectMarkdownFiles(dir) {
+				rel, _ := filepath.Rel(dir, file)
+				files = append(files, filepath.ToSlash(rel))
+			}
+			slices.Sort(files)
+
+			if !slices.Equal(files, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, files)
+			}
+		})
+	}
+}
+
+func TestFindFirstFileByNameRespectGitignore(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	dir := createGitignoreTree(t)
+	config = Config{Directories: []string{dir}, RespectGitignore: true}
+
+	if _, err := findFirstFileByName("generated"); err == nil {
+		t.Error("Expected file in ignored build directory not to be found")
+	}
+}
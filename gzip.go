@@ -0,0 +1,41 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GzipExtension is the extension of gzip compressed markdown files, e.g.
+// notes.md.gz, which are served when read_gzip is set
+const GzipExtension = ".gz"
+
+// isGzipMarkdownFile reports whether the name is a gzip compressed markdown file
+// that is served, which it is only when read_gzip is set
+func (c *Config) isGzipMarkdownFile(name string) bool {
+	if !c.ReadGzip || !strings.HasSuffix(strings.ToLower(name), GzipExtension) {
+		return false
+	}
+	return c.hasMarkdownExtension(name[:len(name)-len(GzipExtension)])
+}
+
+// markdownName returns the name a markdown file is presented with, which for a
+// gzip compressed file is its name without the .gz extension
+func markdownName(path string) string {
+	name := filepath.Base(path)
+	if config.isGzipMarkdownFile(name) {
+		return name[:len(name)-len(GzipExtension)]
+	}
+	return name
+}
+
+// markdownReader reads the markdown of an open file, decompressing it when it is
+// a gzip compressed markdown file
+func markdownReader(file *os.File) (io.Reader, error) {
+	if !config.isGzipMarkdownFile(file.Name()) {
+		return file, nil
+	}
+	return gzip.NewReader(file)
+}
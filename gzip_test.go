@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleFindMarkdownFilesGzip(t *testing.T) {
+	tests := []struct {
+		name      string
+		readGzip  bool
+		wantFiles []string
+	}{
+		{
+			name:      "gzip files hidden by default",
+			wantFiles: []string{"current.md"},
+		},
+		{
+			name:      "gzip files listed with their markdown name",
+			readGzip:  true,
+			wantFiles: []string{"archive.md", "current.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{
+				Directories: []string{"test/gzip"},
+				MaxPageSize: DefaultMaxPageSize,
+				ReadGzip:    tt.readGzip,
+			})
+
+			result, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var response struct {
+				Files []struct {
+					Name string `json:"name"`
+				} `json:"files"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			var names []string
+			for _, file := range response.Files {
+				names = append(names, file.Name)
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+		})
+	}
+}
+
+func TestHandleReadMarkdownFileResourceGzip(t *testing.T) {
+	limit := func(size int64) *int64 { return &size }
+
+	tests := []struct {
+		name        string
+		config      Config
+		uri         string
+		wantCode    string
+		wantContent string
+	}{
+		{
+			name:        "read by markdown name",
+			config:      Config{Directories: []string{"test/gzip"}, ReadGzip: true},
+			uri:         "file://archive.md",
+			wantContent: "# Archive\n\nNotes from last year, kept compressed.\n",
+		},
+		{
+			name:        "read without extension",
+			config:      Config{Directories: []string{"test/gzip"}, ReadGzip: true},
+			uri:         "file://archive",
+			wantContent: "# Archive\n\nNotes from last year, kept compressed.\n",
+		},
+		{
+			name:        "tail of compressed file",
+			config:      Config{Directories: []string{"test/gzip"}, ReadGzip: true},
+			uri:         "file://archive.md?tail_lines=2",
+			wantContent: "\nNotes from last year, kept compressed.\n",
+		},
+		{
+			name:     "not found when read_gzip is not set",
+			config:   Config{Directories: []string{"test/gzip"}},
+			uri:      "file://archive.md",
+			wantCode: ErrorCodeFileNotFound,
+		},
+		{
+			name:     "limit applies to decompressed size",
+			config:   Config{Directories: []string{"test/gzip"}, ReadGzip: true, MaxFileSize: limit(20)},
+			uri:      "file://archive.md",
+			wantCode: ErrorCodeTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, tt.config)
+
+			result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}})
+
+			if tt.wantCode != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.wantCode) {
+					t.Errorf("Expected error code %s, got %v", tt.wantCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if text := result[0].(mcp.TextResourceContents).Text; text != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, text)
+			}
+		})
+	}
+}
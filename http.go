@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// HealthPath is the path of the health check endpoint in the SSE and HTTP modes
+const HealthPath = "/healthz"
+
+// startTime is when the server started, reported as uptime by the health check
+var startTime = time.Now()
+
+type healthStatus struct {
+	Status        string `json:"status"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Directories   int    `json:"directories"`
+	IndexedFiles  *int   `json:"indexed_files,omitempty"`
+}
+
+// newHTTPHandler serves the MCP server over the SSE or streamable HTTP transport
+// together with the health check endpoint, gzip compressing responses unless
+// compress_responses is disabled and requiring the auth_token when one is set.
+// Resource reads over streamable HTTP can be answered with 304 Not Modified.
+func newHTTPHandler(transport string, s *server.MCPServer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+HealthPath, handleHealthz)
+	mux.HandleFunc("GET "+MetricsPath, handleMetrics)
+
+	if transport == TransportHTTP {
+		sessions := &server.InsecureStatefulSessionIdManager{}
+		streamable := server.NewStreamableHTTPServer(s, server.WithEndpointPath(HTTPEndpointPath), server.WithSessionIdManager(sessions))
+		mux.Handle(HTTPEndpointPath, withConditionalRead(sessions, streamable))
+	} else {
+		mux.Handle("/", server.NewSSEServer(s))
+	}
+
+	var handler http.Handler = mux
+	if compressResponses() {
+		handler = withCompression(handler)
+	}
+	if config.AuthToken != "" {
+		handler = withBearerAuth(config.AuthToken, handler)
+	}
+	return handler
+}
+
+// withBearerAuth rejects requests that don't carry the token as
+// "Authorization: Bearer <token>" with 401 Unauthorized
+func withBearerAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, credentials, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(credentials)), []byte(token)) != 1 {
+			logger.Debug("rejected unauthorized request", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="markdown-reader-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleHealthz reports that the server is up without needing an MCP session
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	configMu.RLock()
+	status := healthStatus{
+		Status:        "ok",
+		UptimeSeconds: int64(time.Since(startTime).Seconds()),
+		Directories:   len(config.Directories),
+	}
+	if indexTTL() > 0 {
+		if count, built := markdownIndex.count(); built {
+			status.IndexedFiles = &count
+		}
+	}
+	configMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logger.Debug("healthz failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	linkTypeInternal = "internal"
+	linkTypeExternal = "external"
+)
+
+var (
+	// inlineLinkPattern matches [text](target) and ![alt](target) with an optional "title"
+	inlineLinkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*([^)\s]*)(?:\s+"[^"]*")?\s*\)`)
+	// autolinkPattern matches bare autolinks such as <https://example.com>
+	autolinkPattern = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	// wikilinkPattern matches [[Note Name]] and [[Note Name|alias]]
+	wikilinkPattern = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+)
+
+type wikilink struct {
+	Text     string `json:"text"`
+	Target   string `json:"target"`
+	Alias    string `json:"alias,omitempty"`
+	Line     int    `json:"line"`
+	Resolved bool   `json:"resolved"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type markdownLink struct {
+	Text   string `json:"text"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Image  bool   `json:"image,omitempty"`
+	Line   int    `json:"line"`
+}
+
+func handleExtractLinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("extract_links called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("extract_links failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	links := extractLinks(content)
+
+	result := map[string]any{
+		"links": links,
+		"count": len(links),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("extract_links failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal links: %w", err)), nil
+	}
+
+	logger.Debug("extract_links completed successfully", "links_found", len(links))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// extractLinks returns the inline links, images and autolinks in markdown
+// content, skipping fenced code blocks. Reference-style links ([text][ref] with
+// a separate [ref]: target definition) are deliberately not supported.
+func extractLinks(content string) []markdownLink {
+	links := []markdownLink{}
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, match := range inlineLinkPattern.FindAllStringSubmatch(line, -1) {
+			links = append(links, markdownLink{
+				Text:   match[2],
+				Target: match[3],
+				Type:   linkType(match[3]),
+				Image:  match[1] == "!",
+				Line:   i + 1,
+			})
+		}
+
+		for _, match := range autolinkPattern.FindAllStringSubmatch(line, -1) {
+			links = append(links, markdownLink{
+				Text:   match[1],
+				Target: match[1],
+				Type:   linkTypeExternal,
+				Line:   i + 1,
+			})
+		}
+	}
+
+	return links
+}
+
+// linkType classifies http and https targets as external, everything else
+// (relative paths, .md files and anchors) as internal
+func linkType(target string) string {
+	lower := strings.ToLower(target)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return linkTypeExternal
+	}
+	return linkTypeInternal
+}
+
+func handleResolveWikilinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("resolve_wikilinks called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("resolve_wikilinks failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	links := extractWikilinks(content)
+
+	// Resolve each distinct target once
+	resolved := make(map[string]string)
+	unresolved := 0
+	for i := range links {
+		target := links[i].Target
+		found, ok := resolved[target]
+		if !ok {
+			if path, err := resolveMarkdownFile(target); err == nil {
+				found = markdownName(path)
+			} else {
+				logger.Debug("resolve_wikilinks could not resolve target", "target", target, "error", err)
+			}
+			resolved[target] = found
+		}
+
+		links[i].Resolved = found != ""
+		links[i].Filename = found
+		if found == "" {
+			unresolved++
+		}
+	}
+
+	result := map[string]any{
+		"links":      links,
+		"count":      len(links),
+		"unresolved": unresolved,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("resolve_wikilinks failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal wikilinks: %w", err)), nil
+	}
+
+	logger.Debug("resolve_wikilinks completed successfully", "links_found", len(links), "unresolved", unresolved)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// extractWikilinks returns the [[...]] wikilinks in markdown content, skipping
+// fenced code blocks. The target has any #heading suffix removed so it names a file.
+func extractWikilinks(content string) []wikilink {
+	links := []wikilink{}
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, match := range wikilinkPattern.FindAllStringSubmatch(line, -1) {
+			text := strings.TrimSpace(match[1])
+			target, _, _ := strings.Cut(text, "#")
+			links = append(links, wikilink{
+				Text:   text,
+				Target: strings.TrimSpace(target),
+				Alias:  strings.TrimSpace(match[2]),
+				Line:   i + 1,
+			})
+		}
+	}
+
+	return links
+}
+
+func handleFindBacklinks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("find_backlinks called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	targetFile, err := resolveMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("find_backlinks failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	maxResults := config.MaxPageSize
+	if maxResults <= 0 {
+		maxResults = DefaultMaxPageSize
+	}
+
+	backlinks, truncated := findBacklinks(ctx, targetFile, maxResults)
+
+	fileInfos := make([]map[string]any, 0, len(backlinks))
+	for _, file := range backlinks {
+		fileInfos = append(fileInfos, map[string]any{
+			"name": markdownName(file),
+		})
+	}
+
+	result := map[string]any{
+		"files":     fileInfos,
+		"count":     len(fileInfos),
+		"truncated": truncated,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("find_backlinks failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal backlinks: %w", err)), nil
+	}
+
+	logger.Debug("find_backlinks completed successfully", "backlinks_found", len(backlinks), "truncated", truncated)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// findBacklinks returns up to maxResults files linking to the target file, either
+// with a [[wikilink]] or a markdown link whose path ends in the target filename.
+// This reads every markdown file in the configured directories, so its cost grows
+// with the size of the vault. The search stops early, reporting the results as
+// truncated, when the context is done.
+func findBacklinks(ctx context.Context, targetFile string, maxResults int) ([]string, bool) {
+	targetName := filepath.Base(targetFile)
+	targetStem := strings.TrimSuffix(targetName, filepath.Ext(targetName))
+
+	var backlinks []string
+	for _, dir := range config.Directories {
+		files, truncated := collectMarkdownFilesFromDir(ctx, dir)
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return backlinks, true
+			}
+			if file == targetFile {
+				continue
+			}
+
+			content, err := readFileContent(file)
+			if err != nil {
+				logger.Debug("Could not read file", "file", file, "error", err)
+				continue
+			}
+
+			if !linksTo(string(content), targetName, targetStem) {
+				continue
+			}
+
+			if len(backlinks) == maxResults {
+				return backlinks, true
+			}
+			backlinks = append(backlinks, file)
+		}
+		if truncated {
+			return backlinks, true
+		}
+	}
+
+	return backlinks, false
+}
+
+func linksTo(content, targetName, targetStem string) bool {
+	for _, link := range extractWikilinks(content) {
+		if strings.EqualFold(link.Target, targetStem) || strings.EqualFold(link.Target, targetName) {
+			return true
+		}
+	}
+
+	for _, link := range extractLinks(content) {
+		if link.Type != linkTypeInternal {
+			continue
+		}
+		target, _, _ := strings.Cut(link.Target, "#")
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		if strings.EqualFold(path.Base(target), targetName) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleExtractLinks(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/links"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantError bool
+		wantLinks []markdownLink
+	}{
+		{
+			name:     "mixed internal and external links",
+			filename: "linked",
+			wantLinks: []markdownLink{
+				{Text: "the foo note", Target: "foo.md", Type: linkTypeInternal, Line: 3},
+				{Text: "a guide", Target: "guides/setup.md", Type: linkTypeInternal, Line: 3},
+				{Text: "Example", Target: "https://example.com", Type: linkTypeExternal, Line: 5},
+				{Text: "https://go.dev/doc", Target: "https://go.dev/doc", Type: linkTypeExternal, Line: 5},
+				{Text: "Diagram", Target: "images/diagram.png", Type: linkTypeInternal, Image: true, Line: 7},
+				{Text: "usage", Target: "#usage", Type: linkTypeInternal, Line: 13},
+				{Text: "HTTP docs", Target: "HTTP://EXAMPLE.ORG/docs", Type: linkTypeExternal, Line: 13},
+			},
+		},
+		{
+			name:      "directory traversal attempt",
+			filename:  "../linked.md",
+			wantError: true,
+		},
+		{
+			name:      "missing filename",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "extract_links",
+					Arguments: map[string]any{"filename": tt.filename},
+				},
+			}
+
+			result, err := handleExtractLinks(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+
+			if result.IsError {
+				t.Fatalf("Tool returned error: %v", result.Content)
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", result.Content[0])
+			}
+
+			var response struct {
+				Links []markdownLink `json:"links"`
+				Count int            `json:"count"`
+			}
+			if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+
+			if !reflect.DeepEqual(response.Links, tt.wantLinks) {
+				t.Errorf("Expected links %+v, got %+v", tt.wantLinks, response.Links)
+			}
+
+			if response.Count != len(tt.wantLinks) {
+				t.Errorf("Expected count %d, got %d", len(tt.wantLinks), response.Count)
+			}
+		})
+	}
+}
+
+func TestHandleResolveWikilinks(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/wikilinks"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "resolve_wikilinks",
+			Arguments: map[string]any{"filename": "hub.md"},
+		},
+	}
+
+	result, err := handleResolveWikilinks(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool returned error: %v", result.Content)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected TextContent, got %T", result.Content[0])
+	}
+
+	var response struct {
+		Links      []wikilink `json:"links"`
+		Count      int        `json:"count"`
+		Unresolved int        `json:"unresolved"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	want := []wikilink{
+		{Text: "Project Ideas", Target: "Project Ideas", Line: 3, Resolved: true, Filename: "Project Ideas.md"},
+		{Text: "project ideas", Target: "project ideas", Alias: "my ideas", Line: 4, Resolved: true, Filename: "Project Ideas.md"},
+		{Text: "Reading List", Target: "Reading List", Line: 4, Resolved: true, Filename: "Reading List.md"},
+		{Text: "Missing Note", Target: "Missing Note", Line: 5, Resolved: false},
+		{Text: "Reading List#Fiction", Target: "Reading List", Alias: "fiction", Line: 6, Resolved: true, Filename: "Reading List.md"},
+	}
+
+	if !reflect.DeepEqual(response.Links, want) {
+		t.Errorf("Expected wikilinks %+v, got %+v", want, response.Links)
+	}
+
+	if response.Unresolved != 1 {
+		t.Errorf("Expected 1 unresolved link, got %d", response.Unresolved)
+	}
+}
+
+func TestHandleFindBacklinks(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name          string
+		filename      string
+		maxPageSize   int
+		wantError     bool
+		wantFiles     []string
+		wantTruncated bool
+	}{
+		{
+			name:        "wikilink and markdown link backlinks",
+			filename:    "target",
+			maxPageSize: DefaultMaxPageSize,
+			wantFiles:   []string{"alpha.md", "beta.md"},
+		},
+		{
+			name:          "backlinks capped by max page size",
+			filename:      "target.md",
+			maxPageSize:   1,
+			wantFiles:     []string{"alpha.md"},
+			wantTruncated: true,
+		},
+		{
+			name:        "no backlinks",
+			filename:    "gamma",
+			maxPageSize: DefaultMaxPageSize,
+			wantFiles:   []string{},
+		},
+		{
+			name:        "non-existent target",
+			filename:    "nonexistent",
+			maxPageSize: DefaultMaxPageSize,
+			wantError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{Directories: []string{"test/backlinks"}, MaxPageSize: tt.maxPageSize}
+
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "find_backlinks",
+					Arguments: map[string]any{"filename": tt.filename},
+				},
+			}
+
+			result, err := handleFindBacklinks(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+
+			if result.IsError {
+				t.Fatalf("Tool returned error: %v", result.Content)
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", result.Content[0])
+			}
+
+			var response struct {
+				Files []struct {
+					Name string `json:"name"`
+				} `json:"files"`
+				Count     int  `json:"count"`
+				Truncated bool `json:"truncated"`
+			}
+			if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+
+			names := []string{}
+			for _, file := range response.Files {
+				names = append(names, file.Name)
+			}
+
+			if !reflect.DeepEqual(names, tt.wantFiles) {
+				t.Errorf("Expected backlinks %v, got %v", tt.wantFiles, names)
+			}
+
+			if response.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, response.Truncated)
+			}
+		})
+	}
+}
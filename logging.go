@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 // ANSI color codes
@@ -21,15 +25,21 @@ const (
 	colorGreen  = "\033[32m"
 )
 
+// DefaultLogTimeFormat is the time-only layout used for log timestamps
+const DefaultLogTimeFormat = "15:04:05.000"
+
 type prettyHandler struct {
-	handler slog.Handler
-	writer  io.Writer
+	handler    slog.Handler
+	writer     io.Writer
+	timeFormat string
+	attrs      []slog.Attr
 }
 
 func newPrettyHandler(w io.Writer, opts *slog.HandlerOptions) *prettyHandler {
 	return &prettyHandler{
-		handler: slog.NewTextHandler(w, opts),
-		writer:  w,
+		handler:    slog.NewTextHandler(w, opts),
+		writer:     w,
+		timeFormat: DefaultLogTimeFormat,
 	}
 }
 
@@ -39,15 +49,19 @@ func (h *prettyHandler) Enabled(ctx context.Context, level slog.Level) bool {
 
 func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &prettyHandler{
-		handler: h.handler.WithAttrs(attrs),
-		writer:  h.writer,
+		handler:    h.handler.WithAttrs(attrs),
+		writer:     h.writer,
+		timeFormat: h.timeFormat,
+		attrs:      append(slices.Clip(h.attrs), attrs...),
 	}
 }
 
 func (h *prettyHandler) WithGroup(name string) slog.Handler {
 	return &prettyHandler{
-		handler: h.handler.WithGroup(name),
-		writer:  h.writer,
+		handler:    h.handler.WithGroup(name),
+		writer:     h.writer,
+		timeFormat: h.timeFormat,
+		attrs:      h.attrs,
 	}
 }
 
@@ -74,7 +88,7 @@ func (h *prettyHandler) Handle(ctx context.Context, r slog.Record) error {
 	}
 
 	// Format time
-	timeStr := r.Time.Format("15:04:05.000")
+	timeStr := r.Time.Format(h.timeFormat)
 
 	// Start building the log line
 	var sb strings.Builder
@@ -88,8 +102,8 @@ func (h *prettyHandler) Handle(ctx context.Context, r slog.Record) error {
 	sb.WriteString(" ")
 	sb.WriteString(r.Message)
 
-	// Add attributes
-	r.Attrs(func(a slog.Attr) bool {
+	// Add attributes, starting with those attached by logger.With
+	writeAttr := func(a slog.Attr) bool {
 		sb.WriteString(" ")
 		sb.WriteString(colorCyan)
 		sb.WriteString(a.Key)
@@ -108,7 +122,11 @@ func (h *prettyHandler) Handle(ctx context.Context, r slog.Record) error {
 		}
 		sb.WriteString(colorReset)
 		return true
-	})
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(writeAttr)
 
 	sb.WriteString("\n")
 
@@ -116,18 +134,22 @@ func (h *prettyHandler) Handle(ctx context.Context, r slog.Record) error {
 	return err
 }
 
-func configureLogger() {
-	logLevel := slog.LevelInfo // Default to info, warnings and errors
-
-	// Determine debug logging setting with command line flags taking precedence
-	debugLogging := config.DebugLogging
+// debugLoggingEnabled reports whether debug logging is on, with the command line
+// flags taking precedence over the config
+func debugLoggingEnabled() bool {
 	if *debugFlag {
-		debugLogging = true // Command line --debug overrides config
-	} else if *quietFlag {
-		debugLogging = false // Command line --quiet overrides config
+		return true // Command line --debug overrides config
 	}
+	if *quietFlag {
+		return false // Command line --quiet overrides config
+	}
+	return config.DebugLogging
+}
+
+func configureLogger() {
+	logLevel := slog.LevelInfo // Default to info, warnings and errors
 
-	if debugLogging {
+	if debugLoggingEnabled() {
 		logLevel = slog.LevelDebug // Show debug messages when enabled
 	}
 
@@ -162,5 +184,36 @@ func configureLogger() {
 		logOutput = os.Stderr
 	}
 
-	logger = slog.New(newPrettyHandler(logOutput, &slog.HandlerOptions{Level: logLevel}))
+	handler := newPrettyHandler(logOutput, &slog.HandlerOptions{Level: logLevel})
+	logger = slog.New(handler)
+
+	if config.LogTimeFormat != "" {
+		if isValidTimeLayout(config.LogTimeFormat) {
+			handler.timeFormat = config.LogTimeFormat
+		} else {
+			logger.Warn("log_time_format does not look like a Go time layout, using the default", "log_time_format", config.LogTimeFormat, "default", DefaultLogTimeFormat)
+		}
+	}
+}
+
+// requestLogger returns a logger tagging every line with a new random request ID,
+// so the lines logged for one tool call or resource read can be told apart
+func requestLogger() *slog.Logger {
+	return logger.With("request_id", newRequestID())
+}
+
+func newRequestID() string {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// isValidTimeLayout reports whether a layout formats times at all. A layout without
+// any reference time elements, such as "HH:mm:ss", formats every time the same.
+func isValidTimeLayout(layout string) bool {
+	a := time.Date(2001, 2, 3, 4, 5, 6, 7000000, time.UTC)
+	b := time.Date(2011, 12, 13, 14, 15, 16, 17000000, time.UTC)
+	return a.Format(layout) != b.Format(layout)
 }
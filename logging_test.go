@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPrettyHandlerTimeFormat(t *testing.T) {
+	recordTime := time.Date(2025, 3, 14, 9, 26, 53, 589000000, time.Local)
+
+	tests := []struct {
+		name       string
+		timeFormat string
+		wantPrefix string
+	}{
+		{
+			name:       "default time only",
+			timeFormat: DefaultLogTimeFormat,
+			wantPrefix: colorGray + "09:26:53.589" + colorReset + " ",
+		},
+		{
+			name:       "custom layout with date",
+			timeFormat: "2006-01-02 15:04:05",
+			wantPrefix: colorGray + "2025-03-14 09:26:53" + colorReset + " ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := newPrettyHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+			handler.timeFormat = tt.timeFormat
+
+			record := slog.NewRecord(recordTime, slog.LevelInfo, "hello", 0)
+			if err := handler.Handle(context.Background(), record); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.HasPrefix(buf.String(), tt.wantPrefix) {
+				t.Errorf("Expected line to start with %q, got %q", tt.wantPrefix, buf.String())
+			}
+		})
+	}
+}
+
+func TestConfigureLoggerTimeFormat(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name          string
+		logTimeFormat string
+		wantLayout    string
+	}{
+		{name: "empty keeps the default", wantLayout: DefaultLogTimeFormat},
+		{name: "custom layout", logTimeFormat: "2006-01-02 15:04:05", wantLayout: "2006-01-02 15:04:05"},
+		{name: "layout without time elements falls back", logTimeFormat: "YYYY-MM-DD", wantLayout: DefaultLogTimeFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "server.log")
+			config = Config{LogFile: logPath, LogTimeFormat: tt.logTimeFormat}
+
+			configureLogger()
+			logger.Error("marker")
+
+			data, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+
+			// The last line is the marker, any warning about the layout comes first
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			line := strings.TrimPrefix(lines[len(lines)-1], colorGray)
+			timeStr, _, found := strings.Cut(line, colorReset)
+			if !found {
+				t.Fatalf("Expected a timestamp in %q", line)
+			}
+
+			if _, err := time.Parse(tt.wantLayout, timeStr); err != nil {
+				t.Errorf("Expected timestamp %q in layout %q: %v", timeStr, tt.wantLayout, err)
+			}
+		})
+	}
+}
+
+func TestRequestIDSharedWithinHandlerCall(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	var buf bytes.Buffer
+	logger = slog.New(newPrettyHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	config = Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize}
+
+	ansiPattern := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	requestIDPattern := regexp.MustCompile(`request_id="([0-9a-f]{8})"`)
+
+	callIDs := func() []string {
+		buf.Reset()
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "find_markdown_files",
+				Arguments: map[string]any{"query": "foo"},
+			},
+		}
+		if _, err := handleFindMarkdownFiles(context.Background(), req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var ids []string
+		for _, line := range strings.Split(ansiPattern.ReplaceAllString(buf.String(), ""), "\n") {
+			if !strings.Contains(line, "find_markdown_files") {
+				continue
+			}
+			match := requestIDPattern.FindStringSubmatch(line)
+			if match == nil {
+				t.Errorf("Expected request_id on line %q", line)
+				continue
+			}
+			ids = append(ids, match[1])
+		}
+		return ids
+	}
+
+	first := callIDs()
+	if len(first) < 2 {
+		t.Fatalf("Expected at least 2 log lines for the call, got %d", len(first))
+	}
+	for _, id := range first[1:] {
+		if id != first[0] {
+			t.Errorf("Expected all lines of one call to share request_id %s, got %s", first[0], id)
+		}
+	}
+
+	second := callIDs()
+	if len(second) == 0 || second[0] == first[0] {
+		t.Errorf("Expected a new request_id for the next call, got %v after %v", second, first)
+	}
+}
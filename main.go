@@ -1,43 +1,195 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 type Config struct {
-	Directories  []string `json:"directories"`
-	MaxPageSize  int      `json:"max_page_size,omitempty"`
-	DebugLogging bool     `json:"debug_logging,omitempty"`
-	IgnoreDirs   []string `json:"ignore_dirs,omitempty"`
-	SSEMode      bool     `json:"sse_mode,omitempty"`
-	SSEPort      int      `json:"sse_port,omitempty"`
-	LogFile      string   `json:"log_file,omitempty"`
+	Directories        []string `json:"directories"`
+	MaxPageSize        int      `json:"max_page_size,omitempty"`
+	DefaultPageSize    int      `json:"default_page_size,omitempty"`
+	DebugLogging       bool     `json:"debug_logging,omitempty"`
+	IgnoreDirs         []string `json:"ignore_dirs,omitempty"`
+	IgnoreFiles        []string `json:"ignore_files,omitempty"`
+	AllowFiles         []string `json:"allow_files,omitempty"`
+	SSEMode            bool     `json:"sse_mode,omitempty"`
+	SSEPort            int      `json:"sse_port,omitempty"`
+	SSEHost            string   `json:"sse_host,omitempty"`
+	LogFile            string   `json:"log_file,omitempty"`
+	AmbiguousRead      string   `json:"ambiguous_read,omitempty"`
+	Extensions         []string `json:"extensions,omitempty"`
+	ExactMatch         string   `json:"exact_match,omitempty"`
+	RespectGitignore   bool     `json:"respect_gitignore,omitempty"`
+	MaxFileSize        *int64   `json:"max_file_size,omitempty"`
+	PrewarmContent     bool     `json:"prewarm_content,omitempty"`
+	PrewarmMaxMB       int      `json:"prewarm_max_mb,omitempty"`
+	WatchConfig        bool     `json:"watch_config,omitempty"`
+	LogTimeFormat      string   `json:"log_time_format,omitempty"`
+	IndexTTLSeconds    int      `json:"index_ttl_seconds,omitempty"`
+	WatchFiles         bool     `json:"watch_files,omitempty"`
+	ContentCacheMB     int      `json:"content_cache_mb,omitempty"`
+	Transport          string   `json:"transport,omitempty"`
+	FollowSymlinks     bool     `json:"follow_symlinks,omitempty"`
+	MaxDepth           int      `json:"max_depth,omitempty"`
+	TitleLookup        bool     `json:"title_lookup,omitempty"`
+	CompressResponses  *bool    `json:"compress_responses,omitempty"`
+	RateLimitPerSec    float64  `json:"rate_limit_per_sec,omitempty"`
+	RateLimitBurst     int      `json:"rate_limit_burst,omitempty"`
+	AuthToken          string   `json:"auth_token,omitempty"`
+	SearchConcurrency  int      `json:"search_concurrency,omitempty"`
+	ReadGzip           bool     `json:"read_gzip,omitempty"`
+	DetectEncoding     bool     `json:"detect_encoding,omitempty"`
+	ResourceScheme     string   `json:"resource_scheme,omitempty"`
+	AllowFilesPaths    []string `json:"allow_files_paths,omitempty"`
+	MaxSuggestions     int      `json:"max_suggestions,omitempty"`
+	FindTimeoutSeconds int      `json:"find_timeout_seconds,omitempty"`
+	IndexFilenames     []string `json:"index_filenames,omitempty"`
+
+	DirectoryIgnoreDirs map[string][]string `json:"-"`
+
+	// DirectoryNames maps the configured directories whose leading ~ was
+	// expanded to the directory as written, to report them without the home path
+	DirectoryNames map[string]string `json:"-"`
+}
+
+// directoryEntry is an entry of the directories config, either a plain path or
+// an object with the path and ignore patterns that only apply to that directory
+type directoryEntry struct {
+	Path       string   `json:"path"`
+	IgnoreDirs []string `json:"ignore_dirs,omitempty"`
+}
+
+func (e *directoryEntry) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*e = directoryEntry{Path: path}
+		return nil
+	}
+
+	type plainEntry directoryEntry
+	var entry plainEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("directory entry must be a path or an object with a path: %w", err)
+	}
+	if entry.Path == "" {
+		return fmt.Errorf("directory entry is missing a path")
+	}
+
+	*e = directoryEntry(entry)
+	return nil
+}
+
+// MarshalJSON writes an entry as a plain path unless it has ignore patterns
+func (e directoryEntry) MarshalJSON() ([]byte, error) {
+	if len(e.IgnoreDirs) == 0 {
+		return json.Marshal(e.Path)
+	}
+	type plainEntry directoryEntry
+	return json.Marshal(plainEntry(e))
+}
+
+// MarshalJSON writes the config as it is read from a config file, with the
+// directories that have their own ignore patterns written as objects
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plainConfig Config
+	directories := make([]directoryEntry, len(c.Directories))
+	for i, dir := range c.Directories {
+		directories[i] = directoryEntry{Path: dir, IgnoreDirs: c.DirectoryIgnoreDirs[dir]}
+	}
+
+	return json.Marshal(struct {
+		Directories []directoryEntry `json:"directories"`
+		plainConfig
+	}{directories, plainConfig(c)})
+}
+
+// UnmarshalJSON accepts directories given as plain paths or as objects with
+// per-directory ignore patterns
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plainConfig Config
+	aux := struct {
+		*plainConfig
+		Directories []directoryEntry `json:"directories"`
+	}{plainConfig: (*plainConfig)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Directories = nil
+	c.DirectoryIgnoreDirs = nil
+	for _, entry := range aux.Directories {
+		c.Directories = append(c.Directories, entry.Path)
+		if len(entry.IgnoreDirs) > 0 {
+			if c.DirectoryIgnoreDirs == nil {
+				c.DirectoryIgnoreDirs = map[string][]string{}
+			}
+			c.DirectoryIgnoreDirs[entry.Path] = append(c.DirectoryIgnoreDirs[entry.Path], entry.IgnoreDirs...)
+		}
+	}
+
+	return nil
 }
 
+// Modes for resolving a read when a filename matches more than one file
+const (
+	AmbiguousReadFirst  = "first"
+	AmbiguousReadError  = "error"
+	AmbiguousReadNewest = "newest"
+)
+
+// Transports the server can be served over
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+// HTTPEndpointPath is the path the streamable HTTP transport is served on
+const HTTPEndpointPath = "/mcp"
+
+// Modes for handling find results whose filename exactly equals the query
+const (
+	ExactMatchFirst = "first"
+	ExactMatchOnly  = "only"
+)
+
 var (
-	config     Config
-	logger     *slog.Logger
-	helpFlag   = flag.Bool("help", false, "Show usage information")
-	debugFlag  = flag.Bool("debug", false, "Enable debug logging (overrides config)")
-	quietFlag  = flag.Bool("quiet", false, "Disable debug logging (overrides config)")
-	sseFlag    = flag.Bool("sse", false, "Enable SSE mode (overrides config)")
-	stdoutFlag = flag.Bool("stdout", false, "Output logs to stdout (overrides log_file config)")
+	config          Config
+	configMu        sync.RWMutex
+	logger          *slog.Logger
+	helpFlag        = flag.Bool("help", false, "Show usage information")
+	debugFlag       = flag.Bool("debug", false, "Enable debug logging (overrides config)")
+	quietFlag       = flag.Bool("quiet", false, "Disable debug logging (overrides config)")
+	sseFlag         = flag.Bool("sse", false, "Enable SSE mode (overrides config)")
+	stdoutFlag      = flag.Bool("stdout", false, "Output logs to stdout (overrides log_file config)")
+	configFlag      = flag.String("config", "", "Path of the config file to load (overrides config file search)")
+	scanFlag        = flag.Bool("scan", false, "Scan the configured directories, print a summary and exit")
+	printConfigFlag = flag.Bool("print-config", false, "Print the effective config as JSON and exit")
 )
 
 func showUsage() {
 	fmt.Printf(`Markdown Reader MCP Server
 
 A Model Context Protocol (MCP) server that provides read-only access to Markdown files
-in configured directories. The server discovers and reads .md files only.
+in configured directories. The server discovers and reads markdown files only
+(.md by default, see the extensions option).
 
 This server uses stdio transport and is designed to work with MCP clients like Claude.
 
@@ -51,6 +203,15 @@ OPTIONS:
   -quiet   Disable debug logging (overrides config file setting)
   -sse     Enable SSE mode (overrides config file setting)
   -stdout  Output logs to stdout (overrides log_file config setting)
+  -config <path>
+           Load this config file instead of searching the default locations
+  -scan    Scan the configured directories once, print the markdown files
+           found in each and exit, without starting the server. Exits with
+           an error if no files are found
+  -print-config
+           Print the effective config as JSON, after merging the config file,
+           environment variables and command line, and exit without starting
+           the server
 
 CONFIGURATION:
   The server can be configured in two ways:
@@ -59,26 +220,142 @@ CONFIGURATION:
      %s ~/documents/notes ~/projects/docs /absolute/path
 
   2. Configuration file (recommended):
-     Create ~/.config/markdown-reader-mcp/markdown-reader-mcp.json:
+     Create ~/.config/markdown-reader-mcp/markdown-reader-mcp.json (see CONFIG FILE
+     LOCATIONS for other places it is looked for):
      {
        "directories": ["~/my/notes", "~/projects/docs", "."],
        "max_page_size": 100,
+       "default_page_size": 50,
        "debug_logging": false,
        "ignore_dirs": ["\\.git$", "node_modules$", "vendor$"],
+       "ignore_files": ["\\.draft\\.md$", "^TEMPLATE\\.md$"],
+       "allow_files": [],
        "sse_mode": false,
        "sse_port": 8080,
-       "log_file": "~/logs/markdown-reader-mcp.log"
+       "sse_host": "127.0.0.1",
+       "log_file": "~/logs/markdown-reader-mcp.log",
+       "ambiguous_read": "first",
+       "extensions": [".md", ".markdown"],
+       "exact_match": "first",
+       "respect_gitignore": false,
+       "max_file_size": 10485760,
+       "prewarm_content": false,
+       "prewarm_max_mb": 64,
+       "watch_config": false,
+       "log_time_format": "2006-01-02 15:04:05",
+       "index_ttl_seconds": 30,
+       "watch_files": false,
+       "content_cache_mb": 0,
+       "transport": "stdio",
+       "follow_symlinks": false,
+       "max_depth": 0,
+       "title_lookup": false,
+       "compress_responses": true,
+       "rate_limit_per_sec": 0,
+       "rate_limit_burst": 0,
+       "auth_token": "",
+       "search_concurrency": 8,
+       "read_gzip": false,
+       "detect_encoding": false,
+       "resource_scheme": "markdown",
+       "allow_files_paths": ["~/.config/app/README.md"],
+       "max_suggestions": 5,
+       "find_timeout_seconds": 0,
+       "index_filenames": ["README.md", "index.md"]
      }
 
 CONFIGURATION OPTIONS:
-  directories    - Array of directory paths to scan for markdown files
+  directories    - Array of directory paths to scan for markdown files. An entry
+                   can also be {"path": "...", "ignore_dirs": [...]} to add
+                   ignore patterns for that directory only
   max_page_size  - Maximum results per page (default: %d)
+  default_page_size - Results per page when no page_size is requested, at most
+                   max_page_size (default: %d)
   debug_logging  - Enable detailed debug logging (default: false)
   ignore_dirs    - Regex patterns for directories to ignore
                    (default: ["\\.git$", "node_modules$"])
-  sse_mode       - Enable SSE transport mode (default: false)
-  sse_port       - Port for SSE server (default: 8080)
+  ignore_files   - Regex patterns for file names to ignore (default: none)
+  allow_files    - Regex patterns for file names that may be served; when set,
+                   other files are hidden (default: none, all allowed)
+  transport      - Transport to serve: "stdio", "sse" or "http" for streamable
+                   HTTP on the /mcp endpoint (default: "stdio")
+  sse_mode       - Enable SSE transport mode, same as transport "sse"
+                   (default: false)
+  sse_port       - Port for SSE and HTTP servers (default: 8080)
+  sse_host       - Host or IP address the SSE and HTTP servers bind to
+                   (default: all interfaces)
   log_file       - Path to log file (default: stderr)
+  ambiguous_read - How to read a filename matching several files: "first",
+                   "error" or "newest" (default: "first")
+  extensions     - File extensions treated as markdown, ignoring case
+                   (default: [".md"])
+  exact_match    - Files named exactly as the find query are listed "first" or
+                   returned "only" when there are any (default: "first")
+  respect_gitignore - Skip paths matching the .gitignore at the root of each
+                   directory (default: false)
+  max_file_size  - Largest file in bytes that will be read, 0 for unlimited
+                   (default: 10485760)
+  prewarm_content - Read file contents at startup so the first content search
+                   is fast (default: false)
+  prewarm_max_mb - Memory cap for pre-warmed content in MB (default: %d)
+  watch_config   - Reload the config file when it changes (default: false)
+  log_time_format - Go time layout for log timestamps, e.g. "2006-01-02 15:04:05"
+                   to include the date (default: "15:04:05.000")
+  index_ttl_seconds - Seconds the file index is used before directories are
+                   walked again, -1 to always walk (default: 30)
+  watch_files    - Watch directories so new, deleted and renamed files are seen
+                   immediately (default: false)
+  content_cache_mb - Memory in MB for caching read file contents, 0 to
+                   disable (default: 0)
+  follow_symlinks - Serve symlinked markdown files whose target is within a
+                   configured directory (default: false)
+  max_depth      - Directory levels scanned in each directory, 1 for only its
+                   top level, 0 for unlimited (default: 0)
+  title_lookup   - Read a file by its frontmatter title when no file name
+                   matches (default: false)
+  compress_responses - Gzip compress SSE and HTTP responses for clients that
+                   accept it (default: true)
+  rate_limit_per_sec - Requests per second allowed on each SSE or HTTP
+                   connection, 0 for unlimited (default: 0)
+  rate_limit_burst - Requests a connection may make at once before being
+                   limited (default: rate_limit_per_sec rounded up)
+  auth_token     - Token SSE and HTTP requests must send as
+                   "Authorization: Bearer <token>" (default: none, no auth)
+  search_concurrency - Files read at once when searching content (default: %d)
+  read_gzip      - Also serve gzip compressed markdown files such as notes.md.gz,
+                   listed and read as notes.md (default: false)
+  detect_encoding - Convert files that aren't UTF-8, such as Latin-1 notes, to
+                   UTF-8 when read, and drop UTF-8 byte order marks (default: false)
+  resource_scheme - URI scheme of the resource reading a file by directory label,
+                   to avoid clashing with other servers (default: "markdown")
+  allow_files_paths - Paths of individual files outside the directories that may
+                   also be read, matched exactly without globbing (default: none)
+  max_suggestions - File names suggested when a find query matches no files, at
+                   most %d, -1 to never suggest (default: %d)
+  find_timeout_seconds - Seconds a find may scan before returning the files
+                   found so far, 0 for no timeout (default: 0)
+  index_filenames - Files read for a directory by read_directory_index, first
+                   match first (default: ["README.md", "index.md"])
+
+ENVIRONMENT VARIABLES:
+  These override the config file, and are overridden by command line options
+  and directories (flags > environment > config file > defaults):
+    MARKDOWN_READER_DIRECTORIES    - Directories to scan, separated by colons
+    MARKDOWN_READER_MAX_PAGE_SIZE  - Maximum results per page
+    MARKDOWN_READER_SSE_PORT       - Port for SSE server
+    MARKDOWN_READER_SSE_HOST       - Host the SSE and HTTP servers bind to
+    MARKDOWN_READER_LOG_FILE       - Path to log file
+    MARKDOWN_READER_AUTH_TOKEN     - Bearer token required in SSE and HTTP modes
+  When MARKDOWN_READER_DIRECTORIES is set no config file is required.
+
+CONFIG FILE LOCATIONS:
+  The first config file found is used, in this order:
+    1. The path in the MARKDOWN_READER_MCP_CONFIG environment variable
+    2. .markdown-reader-mcp.json in the current directory
+    3. $XDG_CONFIG_HOME/markdown-reader-mcp/markdown-reader-mcp.json
+    4. ~/.config/markdown-reader-mcp/markdown-reader-mcp.json
+  Config files may also be YAML or TOML. Each location is tried as .json, then
+  .yaml, .yml and .toml, and a file is parsed by its extension.
 
 INTEGRATION:
   This server is designed to work with MCP clients like Claude Code:
@@ -86,7 +363,46 @@ INTEGRATION:
 
 CAPABILITIES PROVIDED:
   find_markdown_files  - Tool: Find markdown files with optional filtering and pagination
+  find_files_by_name   - Tool: Find every markdown file with a given name
+  file_exists          - Tool: Check a markdown file exists without reading it
+  recent_files         - Tool: List the markdown files modified in the last few days
+  get_file_outline     - Tool: Get the heading outline of a markdown file
+  generate_toc         - Tool: Generate a linked table of contents of a markdown file
+  read_section         - Tool: Read a single heading section of a markdown file
+  list_tasks           - Tool: List the task items of a markdown file
+  extract_tags         - Tool: List the inline #hashtags of a markdown file
+  open_tasks           - Tool: List the open task items across all markdown files
+  get_slides           - Tool: Split a markdown file into slides on '---' separators
+  extract_links        - Tool: List the links in a markdown file
+  check_links          - Tool: Check the local files linked from a markdown file exist
+  resolve_wikilinks    - Tool: Resolve the [[wikilinks]] in a markdown file
+  find_backlinks       - Tool: Find markdown files linking to a markdown file
+  dump_frontmatter     - Tool: Dump the frontmatter of markdown files
+  tag_cloud            - Tool: Count the tags used across all markdown files
+  link_density         - Tool: Rank markdown files by their ratio of links to words
+  heavy_notes          - Tool: Find markdown files embedding large local images
+  fuzzy_search         - Tool: Find markdown files by approximate content match
+  search_markdown      - Tool: Search markdown content, returning matching snippets
+  file_stats           - Tool: Get word count and reading time of a markdown file
+  vault_stats          - Tool: Summarise the size and layout of the markdown files
+  read_directory_index - Tool: Read the README.md or index.md of a folder
+  rebuild_index        - Tool: Rescan directories to refresh the file index
+  server_info          - Tool: Report the effective configuration, for bug reports
+  read_files           - Tool: Read several markdown files in one call
   file://{filename}    - Resource: Read content of specific markdown file by filename
+                         (options: ?trim_content=true&trim_trailing_whitespace=true)
+                         (options: ?strip_comments=true to remove <!-- --> comments)
+                         (options: ?start_line=10&end_line=20 to read a range of lines)
+                         (options: ?tail_lines=20 to read the last lines)
+                         (options: ?format=html or ?format=text to render as HTML or plain text)
+                         (options: ?encoding=base64 to return the content as a base64 blob)
+  markdown://{label}/{filename}
+                       - Resource: Read a markdown file from the configured directory
+                         with the label, the last element of its path, e.g.
+                         markdown://notes/foo.md reads foo.md from ~/notes
+                         (the scheme is set by resource_scheme)
+  markdown-dir://{dir} - Resource: List markdown files of a configured directory,
+                         given as configured or by label, e.g. markdown-dir://notes
 
 EXAMPLES:
   %s ~/documents/notes                    # Scan single directory
@@ -96,9 +412,11 @@ EXAMPLES:
   %s -quiet                               # Disable debug logging via command line
   %s -sse ~/docs                          # Enable SSE mode via command line
   %s -stdout ~/docs                       # Output logs to stdout via command line
+  %s -config ~/vaults/work.json           # Use a specific config file
+  %s -scan                                # Check which files the config finds
 
 For more information, see the README.md file.
-`, os.Args[0], os.Args[0], os.Args[0], DefaultMaxPageSize, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], DefaultMaxPageSize, DefaultPageSize, DefaultPrewarmMaxMB, DefaultSearchConcurrency, DefaultMaxSuggestions, DefaultMaxSuggestions, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
 func expandTilde(path string) (string, error) {
@@ -122,21 +440,336 @@ func expandTilde(path string) (string, error) {
 	return path, nil
 }
 
-func loadConfigFromFile() (*Config, error) {
+// defaultConfig returns the settings used when there is no config file
+func defaultConfig() Config {
+	return Config{
+		MaxPageSize: DefaultMaxPageSize,
+		// Debug logging is disabled by default for command-line usage
+		DebugLogging: false,
+		IgnoreDirs:   []string{`\.git$`, `node_modules$`},
+		Extensions:   DefaultExtensions,
+	}
+}
+
+// Environment variables overriding config file settings
+const (
+	EnvDirectories = "MARKDOWN_READER_DIRECTORIES"
+	EnvMaxPageSize = "MARKDOWN_READER_MAX_PAGE_SIZE"
+	EnvSSEPort     = "MARKDOWN_READER_SSE_PORT"
+	EnvSSEHost     = "MARKDOWN_READER_SSE_HOST"
+	EnvLogFile     = "MARKDOWN_READER_LOG_FILE"
+	EnvAuthToken   = "MARKDOWN_READER_AUTH_TOKEN"
+)
+
+// applyEnvOverrides replaces config settings with those set in the environment
+func applyEnvOverrides(cfg *Config) error {
+	if value := os.Getenv(EnvDirectories); value != "" {
+		var directories []string
+		for _, dir := range filepath.SplitList(value) {
+			if dir == "" {
+				continue
+			}
+			expandedDir, err := expandTilde(dir)
+			if err != nil {
+				return err
+			}
+			directories = append(directories, expandedDir)
+			if expandedDir != dir {
+				if cfg.DirectoryNames == nil {
+					cfg.DirectoryNames = map[string]string{}
+				}
+				cfg.DirectoryNames[expandedDir] = dir
+			}
+		}
+		cfg.Directories = directories
+	}
+
+	if value := os.Getenv(EnvMaxPageSize); value != "" {
+		maxPageSize, err := strconv.Atoi(value)
+		if err != nil || maxPageSize <= 0 {
+			return fmt.Errorf("%s must be a positive integer, got %q", EnvMaxPageSize, value)
+		}
+		cfg.MaxPageSize = maxPageSize
+	}
+
+	if value := os.Getenv(EnvSSEPort); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a port number, got %q", EnvSSEPort, value)
+		}
+		cfg.SSEPort = port
+	}
+
+	if value := os.Getenv(EnvSSEHost); value != "" {
+		cfg.SSEHost = value
+	}
+
+	if value := os.Getenv(EnvLogFile); value != "" {
+		cfg.LogFile = value
+	}
+
+	if value := os.Getenv(EnvAuthToken); value != "" {
+		cfg.AuthToken = value
+	}
+
+	return nil
+}
+
+// validateConfig checks the config for invalid settings and returns all of the
+// problems found as one error. Directories that don't exist are only warned about.
+func validateConfig(cfg *Config) error {
+	var errs []error
+
+	if cfg.SSEPort < 0 || cfg.SSEPort > 65535 {
+		errs = append(errs, fmt.Errorf("sse_port %d is out of range, must be between 1 and 65535", cfg.SSEPort))
+	}
+
+	if cfg.SSEHost != "" && !isValidHost(cfg.SSEHost) {
+		errs = append(errs, fmt.Errorf("sse_host %q is not a valid IP address or host name", cfg.SSEHost))
+	}
+
+	if cfg.MaxPageSize < 0 {
+		errs = append(errs, fmt.Errorf("max_page_size %d must not be negative", cfg.MaxPageSize))
+	}
+
+	if cfg.DefaultPageSize < 0 {
+		errs = append(errs, fmt.Errorf("default_page_size %d must not be negative", cfg.DefaultPageSize))
+	} else if cfg.MaxPageSize > 0 && cfg.DefaultPageSize > cfg.MaxPageSize {
+		errs = append(errs, fmt.Errorf("default_page_size %d must not exceed max_page_size %d", cfg.DefaultPageSize, cfg.MaxPageSize))
+	}
+
+	if cfg.RateLimitPerSec < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_per_sec %v must not be negative", cfg.RateLimitPerSec))
+	}
+
+	if cfg.RateLimitBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_burst %d must not be negative", cfg.RateLimitBurst))
+	}
+
+	if cfg.SearchConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("search_concurrency %d must not be negative", cfg.SearchConcurrency))
+	}
+
+	if cfg.ResourceScheme != "" && !isValidScheme(cfg.ResourceScheme) {
+		errs = append(errs, fmt.Errorf("resource_scheme %q is not a valid URI scheme, e.g. \"notes\"", cfg.ResourceScheme))
+	} else if cfg.ResourceScheme == "file" || cfg.ResourceScheme+"://" == DirectoryResourceScheme {
+		errs = append(errs, fmt.Errorf("resource_scheme %q is used by another resource of the server", cfg.ResourceScheme))
+	}
+
+	if cfg.MaxSuggestions < -1 || cfg.MaxSuggestions > DefaultMaxSuggestions {
+		errs = append(errs, fmt.Errorf("max_suggestions %d is out of range, must be between -1 and %d", cfg.MaxSuggestions, DefaultMaxSuggestions))
+	}
+
+	if cfg.FindTimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("find_timeout_seconds %d must not be negative", cfg.FindTimeoutSeconds))
+	}
+
+	for _, name := range cfg.IndexFilenames {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			errs = append(errs, fmt.Errorf("index_filenames entry %q must be a file name, e.g. \"README.md\"", name))
+		}
+	}
+
+	if cfg.MaxDepth < 0 {
+		errs = append(errs, fmt.Errorf("max_depth %d must not be negative", cfg.MaxDepth))
+	}
+
+	for _, pattern := range cfg.IgnoreDirs {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("ignore_dirs pattern %q is not a valid regex: %v", pattern, err))
+		}
+	}
+
+	for dir, patterns := range cfg.DirectoryIgnoreDirs {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("ignore_dirs pattern %q of directory %s is not a valid regex: %v", pattern, dir, err))
+			}
+		}
+	}
+
+	for _, pattern := range cfg.IgnoreFiles {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("ignore_files pattern %q is not a valid regex: %v", pattern, err))
+		}
+	}
+
+	for _, pattern := range cfg.AllowFiles {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("allow_files pattern %q is not a valid regex: %v", pattern, err))
+		}
+	}
+
+	switch cfg.AmbiguousRead {
+	case "", AmbiguousReadFirst, AmbiguousReadError, AmbiguousReadNewest:
+	default:
+		errs = append(errs, fmt.Errorf("ambiguous_read %q must be %q, %q or %q", cfg.AmbiguousRead, AmbiguousReadFirst, AmbiguousReadError, AmbiguousReadNewest))
+	}
+
+	switch cfg.Transport {
+	case "", TransportStdio, TransportSSE, TransportHTTP:
+	default:
+		errs = append(errs, fmt.Errorf("transport %q must be %q, %q or %q", cfg.Transport, TransportStdio, TransportSSE, TransportHTTP))
+	}
+
+	switch cfg.ExactMatch {
+	case "", ExactMatchFirst, ExactMatchOnly:
+	default:
+		errs = append(errs, fmt.Errorf("exact_match %q must be %q or %q", cfg.ExactMatch, ExactMatchFirst, ExactMatchOnly))
+	}
+
+	for _, path := range cfg.AllowFilesPaths {
+		if !filepath.IsAbs(path) {
+			errs = append(errs, fmt.Errorf("allow_files_paths entry %q must be an absolute path", path))
+		}
+	}
+
+	for _, dir := range cfg.Directories {
+		if info, err := os.Stat(dir); err != nil {
+			logger.Warn("Configured directory does not exist", "directory", dir)
+		} else if !info.IsDir() {
+			logger.Warn("Configured directory is not a directory", "directory", dir)
+		}
+	}
+
+	for _, path := range cfg.AllowFilesPaths {
+		if info, err := os.Stat(path); err != nil {
+			logger.Warn("Allowed file does not exist", "path", path)
+		} else if !info.Mode().IsRegular() {
+			logger.Warn("Allowed file is not a regular file", "path", path)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hostNamePattern matches host names such as "localhost" or "notes.internal"
+var hostNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// isValidHost reports whether host is an IP address or a host name
+func isValidHost(host string) bool {
+	return net.ParseIP(host) != nil || hostNamePattern.MatchString(host)
+}
+
+// schemePattern matches a URI scheme as defined in RFC 3986
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+// isValidScheme reports whether scheme can be used as a URI scheme
+func isValidScheme(scheme string) bool {
+	return schemePattern.MatchString(scheme)
+}
+
+// ConfigEnvVar names an environment variable holding the path of the config file
+const ConfigEnvVar = "MARKDOWN_READER_MCP_CONFIG"
+
+// configSearchPaths returns the locations checked for a config file, highest
+// precedence first: the file named by the environment, a file in the working
+// directory, the XDG config directory and finally the home config directory.
+// Each location is tried with every extension in ConfigExtensions in turn.
+func configSearchPaths() ([]string, error) {
+	var paths []string
+
+	if envPath := os.Getenv(ConfigEnvVar); envPath != "" {
+		expandedPath, err := expandTilde(envPath)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expandedPath)
+	}
+
+	locations := []string{".markdown-reader-mcp"}
+
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		locations = append(locations, filepath.Join(xdgConfigHome, "markdown-reader-mcp", "markdown-reader-mcp"))
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
+	locations = append(locations, filepath.Join(homeDir, ".config", "markdown-reader-mcp", "markdown-reader-mcp"))
 
-	configPath := filepath.Join(homeDir, ".config", "markdown-reader-mcp", "markdown-reader-mcp.json")
+	for _, location := range locations {
+		for _, ext := range ConfigExtensions {
+			paths = append(paths, location+ext)
+		}
+	}
 
+	return paths, nil
+}
+
+// findConfigFile returns the first config file that exists in the search order
+func findConfigFile() (string, error) {
+	paths, err := configSearchPaths()
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("no config file found, looked in: %s", strings.Join(paths, ", "))
+}
+
+// resolveConfigPath returns the config file named by the -config flag, which must
+// exist, or otherwise the first config file found in the search order
+func resolveConfigPath(flagPath string) (string, error) {
+	if flagPath == "" {
+		return findConfigFile()
+	}
+
+	configPath, err := expandTilde(flagPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return "", fmt.Errorf("config file %s could not be read: %w", configPath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("config file %s is a directory", configPath)
+	}
+
+	return configPath, nil
+}
+
+// printConfig writes the effective config as indented JSON, in the form of a
+// config file, so a deployment's config can be checked without serving it. The
+// -sse, -debug and -quiet flags are applied to the transport and debug_logging.
+func printConfig(w io.Writer) error {
+	cfg := config
+	cfg.Transport = resolveTransport()
+	cfg.DebugLogging = debugLoggingEnabled()
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func loadConfigFromFile() (*Config, error) {
+	configPath, err := findConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return loadConfig(configPath)
+}
+
+func loadConfig(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, err
 	}
 
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := decodeConfig(configPath, data, &cfg); err != nil {
 		return nil, err
 	}
 
@@ -147,6 +780,25 @@ func loadConfigFromFile() (*Config, error) {
 			return nil, err
 		}
 		cfg.Directories[i] = expandedDir
+		if expandedDir == dir {
+			continue
+		}
+		if cfg.DirectoryNames == nil {
+			cfg.DirectoryNames = map[string]string{}
+		}
+		cfg.DirectoryNames[expandedDir] = dir
+		if patterns, ok := cfg.DirectoryIgnoreDirs[dir]; ok {
+			delete(cfg.DirectoryIgnoreDirs, dir)
+			cfg.DirectoryIgnoreDirs[expandedDir] = patterns
+		}
+	}
+
+	for i, path := range cfg.AllowFilesPaths {
+		expandedPath, err := expandTilde(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg.AllowFilesPaths[i] = expandedPath
 	}
 
 	if cfg.MaxPageSize == 0 {
@@ -157,6 +809,10 @@ func loadConfigFromFile() (*Config, error) {
 		cfg.IgnoreDirs = []string{`\.git$`, `node_modules$`}
 	}
 
+	if len(cfg.Extensions) == 0 {
+		cfg.Extensions = DefaultExtensions
+	}
+
 	return &cfg, nil
 }
 
@@ -197,22 +853,53 @@ func main() {
 
 	// Get directories from positional arguments or config file
 	args := flag.Args()
-	if len(args) == 0 {
+	var configPath string
+	if len(args) == 0 || *configFlag != "" {
 		// Try to load from config file
-		cfg, err := loadConfigFromFile()
-		if err != nil {
-			logger.Error("No command arguments provided and could not load config file", "error", err)
+		var err error
+		configPath, err = resolveConfigPath(*configFlag)
+		switch {
+		case err == nil:
+			logger.Info("Loading config file", "path", configPath)
+			cfg, err := loadConfig(configPath)
+			if err != nil {
+				logger.Error("Could not load config file", "path", configPath, "error", err)
+				os.Exit(1)
+			}
+			config = *cfg
+		case *configFlag == "" && os.Getenv(EnvDirectories) != "":
+			// Directories can come from the environment alone, e.g. in a container
+			logger.Info("No config file found, using directories from the environment", "variable", EnvDirectories)
+			config = defaultConfig()
+			configPath = ""
+		default:
+			logger.Error("Could not find config file", "error", err)
 			os.Exit(1)
 		}
-		config = *cfg
 	} else {
+		config = defaultConfig()
+	}
+
+	// Environment variables override the config file, command line arguments override both
+	if err := applyEnvOverrides(&config); err != nil {
+		logger.Error("Invalid environment variable", "error", err)
+		os.Exit(1)
+	}
+	if len(args) > 0 {
 		config.Directories = args
-		// Set default max page size for command-line usage
-		config.MaxPageSize = DefaultMaxPageSize
-		// Debug logging is disabled by default for command-line usage
-		config.DebugLogging = false
-		// Set default ignore directories for command-line usage
-		config.IgnoreDirs = []string{`\.git$`, `node_modules$`}
+	}
+
+	if err := validateConfig(&config); err != nil {
+		logger.Error("Invalid config", "error", err)
+		os.Exit(1)
+	}
+
+	if *printConfigFlag {
+		if err := printConfig(os.Stdout); err != nil {
+			logger.Error("Could not print config", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	// Configure logger based on the loaded config
@@ -220,13 +907,81 @@ func main() {
 
 	logger.Info("Scanning directories", "directories", config.Directories)
 	logger.Info("Ignoring directories matching patterns", "patterns", config.IgnoreDirs)
+	if len(config.IgnoreFiles) > 0 {
+		logger.Info("Ignoring files matching patterns", "patterns", config.IgnoreFiles)
+	}
+	if len(config.AllowFiles) > 0 {
+		logger.Info("Only serving files matching patterns", "patterns", config.AllowFiles)
+	}
+
+	if *scanFlag {
+		os.Exit(runScan(os.Stdout))
+	}
+
+	if config.WatchFiles {
+		if _, err := watchFiles(context.Background(), FileWatchDebounce); err != nil {
+			logger.Warn("Could not watch directories for changes, relying on index rescans", "error", err)
+		} else {
+			logger.Info("Watching directories for changes")
+		}
+	}
+
+	if count, ok := buildStartupIndex(); ok {
+		logger.Info("Indexed markdown files", "files", count)
+	}
 
-	// Create MCP server
+	if config.PrewarmContent {
+		prewarmContentCache()
+	}
+
+	if config.WatchConfig {
+		if configPath == "" {
+			logger.Warn("watch_config is set but no config file was loaded, not watching")
+		} else {
+			logger.Info("Watching config file for changes", "path", configPath)
+			watchConfig(context.Background(), configPath, args, ConfigPollInterval)
+		}
+	}
+
+	s := newServer()
+
+	// Start the server
+	transport := resolveTransport()
+	switch transport {
+	case TransportSSE, TransportHTTP:
+		address := bindAddress()
+		if transport == TransportSSE {
+			logger.Info("Starting Markdown Reader MCP server in SSE mode", "address", address, "health", HealthPath, "metrics", MetricsPath)
+		} else {
+			logger.Info("Starting Markdown Reader MCP server in streamable HTTP mode", "address", address, "endpoint", HTTPEndpointPath, "health", HealthPath, "metrics", MetricsPath)
+		}
+		if err := newHTTPServer(address, newHTTPHandler(transport, s)).ListenAndServe(); err != nil {
+			logger.Error("HTTP server error", "error", err)
+			os.Exit(1)
+		}
+	default:
+		logger.Info("Starting Markdown Reader MCP server in stdio mode")
+		if err := server.ServeStdio(s); err != nil {
+			logger.Error("Server error", "error", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// Name and version the server reports to clients
+const (
+	ServerName    = "Markdown Reader"
+	ServerVersion = "0.0.1"
+)
+
+// newServer creates the MCP server with all tools and resources registered
+func newServer() *server.MCPServer {
 	s := server.NewMCPServer(
-		"Markdown Reader",
-		"0.0.1",
+		ServerName,
+		ServerVersion,
 		server.WithResourceCapabilities(true, true),
 		server.WithToolCapabilities(true),
+		server.WithToolHandlerMiddleware(withConfigReadLock),
 	)
 
 	// Add tool for finding markdown files
@@ -236,46 +991,438 @@ func main() {
 			mcp.WithString("query",
 				mcp.Description("Query to find matching files. If not set, then it matches all files. If a string is sent then files containing that text is returned."),
 			),
-			mcp.WithString("page_size",
-				mcp.Description("Number of results in a page"),
+			mcp.WithString("glob",
+				mcp.Description("Shell glob the file name must match, e.g. '2024-*-standup.md'. Combined with query, both must match."),
+			),
+			mcp.WithNumber("min_size",
+				mcp.Description("Only include files of at least this many bytes, e.g. 200 to leave out stub notes"),
+				integerProperty(),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("max_size",
+				mcp.Description("Only include files of at most this many bytes"),
+				integerProperty(),
+				mcp.Min(0),
+			),
+			withPageSize(),
+			mcp.WithBoolean("search_content",
+				mcp.Description("Also match the query against the content of files"),
+			),
+			mcp.WithString("match_mode",
+				mcp.Description("How the query is matched against file names: 'substring' (default) or 'fuzzy', which tolerates typos and punctuation and orders files by score"),
+				mcp.Enum(MatchModeSubstring, MatchModeFuzzy),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Match the query against names and content with exact case"),
+			),
+			mcp.WithBoolean("match_path",
+				mcp.Description("Match the query against the path relative to the configured directory, e.g. 'clients/acme/notes.md', rather than just the file name"),
+			),
+			mcp.WithBoolean("include_content",
+				mcp.Description("Include the content of each file, at most 20 files per page"),
+			),
+			mcp.WithBoolean("include_checksum",
+				mcp.Description("Include the sha256 checksum of each file, to tell whether a cached copy has changed without reading it again, at most 100 files per page"),
+			),
+			mcp.WithBoolean("group_by_dir",
+				mcp.Description("Return the files in groups by their subdirectory within the configured directory, as groups of {directory, files}, rather than as a flat files list"),
+			),
+			mcp.WithBoolean("include_match_location",
+				mcp.Description("With search_content, include the first line matching the query in each file as match_line"),
+			),
+			mcp.WithString("sort",
+				mcp.Description("Order of the files: 'frontmatter_date' orders by the frontmatter date field, newest first, falling back to the modification time. By default files named exactly as the query come first, then files are ordered by name."),
+				mcp.Enum(SortFrontmatterDate),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("The next_cursor of the previous page, to resume after the last file it returned. Omit to start from the beginning."),
+			),
+			mcp.WithString("output_format",
+				mcp.Description("Format of the result: 'json' (default) returns one pretty-printed object, 'jsonl' returns one file object per line, cheaper for large result sets, with the count and paging fields in _meta"),
+				mcp.Enum(OutputFormatJSON, OutputFormatJSONL),
 			),
 		),
 		handleFindMarkdownFiles,
 	)
 
+	// Add tool for finding every markdown file with a given name
+	s.AddTool(
+		mcp.NewTool("find_files_by_name",
+			mcp.WithDescription("Find every markdown file with the given name across the configured directories, each with its path relative to its directory, to tell apart files sharing a name"),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, with or without extension, e.g. 'index' or 'index.md'"),
+			),
+		),
+		handleFindFilesByName,
+	)
+
+	// Add tool for checking a markdown file exists without reading it
+	s.AddTool(
+		mcp.NewTool("file_exists",
+			mcp.WithDescription("Check whether a markdown file exists, resolving the name the same way as reading it but without reading the content"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleFileExists,
+	)
+
+	// Add tool for listing recently modified markdown files
+	s.AddTool(
+		mcp.NewTool("recent_files",
+			mcp.WithDescription("List the markdown files modified within the last few days, newest first, with their modification time"),
+			mcp.WithNumber("days",
+				mcp.Description(fmt.Sprintf("How many days back to look, fractions allowed (default: %d)", DefaultRecentDays)),
+			),
+		),
+		handleRecentFiles,
+	)
+
+	// Add tool for reading several markdown files in one call
+	s.AddTool(
+		mcp.NewTool("read_files",
+			mcp.WithDescription("Read the content of several markdown files in one call. Files that can't be read report an error without failing the others."),
+			mcp.WithArray("filenames",
+				mcp.Required(),
+				mcp.Description("Names of the markdown files, e.g. ['README', 'notes.md']"),
+				mcp.WithStringItems(),
+			),
+		),
+		handleReadFiles,
+	)
+
+	// Add tool for extracting the heading outline of a markdown file
+	s.AddTool(
+		mcp.NewTool("get_file_outline",
+			mcp.WithDescription("Get the heading outline of a markdown file"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleGetFileOutline,
+	)
+
+	// Add tool for generating a table of contents of a markdown file
+	s.AddTool(
+		mcp.NewTool("generate_toc",
+			mcp.WithDescription("Generate a markdown table of contents of a markdown file, linking each heading by its GitHub-style anchor"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleGenerateTOC,
+	)
+
+	// Add tool for reading a single heading section of a markdown file
+	s.AddTool(
+		mcp.NewTool("read_section",
+			mcp.WithDescription("Read one section of a markdown file, from a heading up to the next heading of the same or higher level, including its subsections"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+			mcp.WithString("heading",
+				mcp.Required(),
+				mcp.Description("Text of the heading, matched ignoring case, e.g. 'Installation'"),
+			),
+		),
+		handleReadSection,
+	)
+
+	// Add tool for listing the task list items of a markdown file
+	s.AddTool(
+		mcp.NewTool("list_tasks",
+			mcp.WithDescription("List the task items ('- [ ]' and '- [x]') of a markdown file with whether they are completed"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleListTasks,
+	)
+
+	// Add tool for extracting the inline hashtags of a markdown file
+	s.AddTool(
+		mcp.NewTool("extract_tags",
+			mcp.WithDescription("List the distinct inline #hashtags of a markdown file, including nested tags like #project/subproject, with the lines they are used on"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleExtractTags,
+	)
+
+	// Add tool for collecting the open tasks of all markdown files
+	s.AddTool(
+		mcp.NewTool("open_tasks",
+			mcp.WithDescription("List the incomplete task items ('- [ ]') of all markdown files, grouped by file. Reads every file, so it is slower than other tools on large vaults."),
+			mcp.WithString("query",
+				mcp.Description("Only include files whose name contains this string"),
+			),
+		),
+		handleOpenTasks,
+	)
+
+	// Add tool for splitting a presentation-style markdown file into slides
+	s.AddTool(
+		mcp.NewTool("get_slides",
+			mcp.WithDescription("Split a markdown file into slides separated by '---' lines"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'deck' or 'deck.md'"),
+			),
+		),
+		handleGetSlides,
+	)
+
+	// Add tool for listing the links in a markdown file
+	s.AddTool(
+		mcp.NewTool("extract_links",
+			mcp.WithDescription("List the links in a markdown file, classified as internal or external"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleExtractLinks,
+	)
+
+	// Add tool for checking the local files linked from a markdown file exist
+	s.AddTool(
+		mcp.NewTool("check_links",
+			mcp.WithDescription("Check the local files linked from a markdown file, such as images and PDFs, exist and report their MIME type. File content is not returned."),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleCheckLinks,
+	)
+
+	// Add tool for resolving wikilinks in a markdown file
+	s.AddTool(
+		mcp.NewTool("resolve_wikilinks",
+			mcp.WithDescription("Resolve the [[wikilinks]] in a markdown file to markdown files, reporting broken links"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleResolveWikilinks,
+	)
+
+	// Add tool for finding the markdown files linking to a file
+	s.AddTool(
+		mcp.NewTool("find_backlinks",
+			mcp.WithDescription("Find markdown files that link to a markdown file. Reads every file so can be slow on large directories."),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file being linked to, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleFindBacklinks,
+	)
+
+	// Add tool for dumping the frontmatter of all markdown files
+	s.AddTool(
+		mcp.NewTool("dump_frontmatter",
+			mcp.WithDescription("Dump the frontmatter of markdown files as flat key/value maps"),
+			mcp.WithString("query",
+				mcp.Description("Only include files whose name contains this text"),
+			),
+			withPageSize(),
+		),
+		handleDumpFrontmatter,
+	)
+
+	// Add tool for counting the tags used across all markdown files
+	s.AddTool(
+		mcp.NewTool("tag_cloud",
+			mcp.WithDescription("Count the tags used across all markdown files, from frontmatter 'tags' and inline #hashtags, most used first. Reads every file."),
+			mcp.WithBoolean("frontmatter_only",
+				mcp.Description("Only count frontmatter tags, skipping the slower scan of file bodies for #hashtags"),
+			),
+		),
+		handleTagCloud,
+	)
+
+	// Add tool for ranking markdown files by their ratio of links to words
+	s.AddTool(
+		mcp.NewTool("link_density",
+			mcp.WithDescription("Rank markdown files by their ratio of links to words, highest first, to find index and map-of-content notes"),
+			mcp.WithString("query",
+				mcp.Description("Only include files whose name contains this text"),
+			),
+			withPageSize(),
+		),
+		handleLinkDensity,
+	)
+
+	// Add tool for finding markdown files embedding large local images
+	s.AddTool(
+		mcp.NewTool("heavy_notes",
+			mcp.WithDescription("Find markdown files whose embedded local images total more than a size threshold, heaviest first"),
+			mcp.WithNumber("threshold_bytes",
+				mcp.Description(fmt.Sprintf("Total image size in bytes above which a file is reported (default: %d)", DefaultHeavyNoteThreshold)),
+			),
+			withPageSize(),
+		),
+		handleHeavyNotes,
+	)
+
+	// Add tool for fuzzy full-text search
+	s.AddTool(
+		mcp.NewTool("fuzzy_search",
+			mcp.WithDescription("Find markdown files whose content approximately matches a query, tolerating typos, best match first"),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Words or phrase to look for"),
+			),
+			withPageSize(),
+		),
+		handleFuzzySearch,
+	)
+
+	// Add tool for searching content with snippets of the matches
+	s.AddTool(
+		mcp.NewTool("search_markdown",
+			mcp.WithDescription("Search the content of markdown files, returning the line number and a snippet of each match"),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Text to search for, case-insensitively"),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Match the query with exact case"),
+			),
+		),
+		handleSearchMarkdown,
+	)
+
+	// Add tool for document statistics
+	s.AddTool(
+		mcp.NewTool("file_stats",
+			mcp.WithDescription("Get word, line and character counts and an estimated reading time for a markdown file"),
+			mcp.WithString("filename",
+				mcp.Required(),
+				mcp.Description("Name of the markdown file, e.g. 'README' or 'README.md'"),
+			),
+		),
+		handleFileStats,
+	)
+
+	// Add tool for summarising the markdown files of the configured directories
+	s.AddTool(
+		mcp.NewTool("vault_stats",
+			mcp.WithDescription("Summarise the markdown files of the configured directories: total file count and bytes, average and largest file size, and files per top-level subdirectory"),
+		),
+		handleVaultStats,
+	)
+
+	// Add tool for reading the index file of a directory
+	s.AddTool(
+		mcp.NewTool("read_directory_index",
+			mcp.WithDescription("Read the index file of a folder, its README.md or index.md, the way static site generators resolve directory URLs"),
+			mcp.WithString("directory",
+				mcp.Required(),
+				mcp.Description("Label of a configured directory, the last element of its path, optionally followed by a folder within it, e.g. 'notes' or 'notes/projects/acme'"),
+			),
+		),
+		handleReadDirectoryIndex,
+	)
+
+	// Add tool for rebuilding the file index
+	s.AddTool(
+		mcp.NewTool("rebuild_index",
+			mcp.WithDescription("Rescan the configured directories so new, moved and deleted markdown files are seen immediately"),
+		),
+		handleRebuildIndex,
+	)
+
+	// Add tool for reporting the configuration the server is running with
+	s.AddTool(
+		mcp.NewTool("server_info",
+			mcp.WithDescription("Report the server version and the effective configuration after merging the config file, environment variables and command line flags, with home directory paths redacted"),
+		),
+		handleServerInfo,
+	)
+
 	// Add resource for reading individual markdown files
 	s.AddResourceTemplate(
-		mcp.NewResourceTemplate("file://{filename}", "Markdown Resource"),
-		handleReadMarkdownFileResource,
+		mcp.NewResourceTemplate("file://{filename}{?trim_content,trim_trailing_whitespace,strip_comments,start_line,end_line,tail_lines,format,encoding}", "Markdown Resource"),
+		withConfigReadLockResource(handleReadMarkdownFileResource),
+	)
+
+	// Add resource for reading a markdown file from a configured directory picked by its label
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(resourceSchemePrefix()+"{+path}{?trim_content,trim_trailing_whitespace,strip_comments,start_line,end_line,tail_lines,format,encoding}", "Markdown Resource In Directory",
+			mcp.WithTemplateDescription(fmt.Sprintf("Read a markdown file, optionally from the configured directory with the label, e.g. %snotes/foo.md for foo.md in ~/notes", resourceSchemePrefix())),
+		),
+		withConfigReadLockResource(handleReadMarkdownFileResource),
+	)
+
+	// Add resource for listing the markdown files of a configured directory
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(DirectoryResourceScheme+"{+dir}", "Markdown Directory",
+			mcp.WithTemplateDescription("List the names of the markdown files in a configured directory, given as configured or by its label"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		withConfigReadLockResource(handleListDirectoryResource),
 	)
 
-	// Determine SSE mode setting with command line flag taking precedence
-	sseMode := config.SSEMode
+	return s
+}
+
+// withPageSize adds the page_size parameter of a tool, declared as an integer
+// from 1 to max_page_size so clients can validate it before calling
+func withPageSize() mcp.ToolOption {
+	return mcp.WithNumber("page_size",
+		mcp.Description("Number of results in a page"),
+		integerProperty(),
+		mcp.Min(1),
+		mcp.Max(float64(maxPageSize())),
+	)
+}
+
+// integerProperty declares a number parameter of a tool as an integer
+func integerProperty() mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["type"] = "integer"
+	}
+}
+
+// resolveTransport returns the transport to serve, with the -sse flag taking
+// precedence over the config. sse_mode is kept as an alias for "sse".
+func resolveTransport() string {
 	if *sseFlag {
-		sseMode = true
+		return TransportSSE
+	}
+	if config.Transport != "" {
+		return config.Transport
+	}
+	if config.SSEMode {
+		return TransportSSE
 	}
+	return TransportStdio
+}
 
-	// Start the server
-	if sseMode {
-		var port string
-		if config.SSEPort != 0 {
-			port = fmt.Sprintf("%d", config.SSEPort)
-		} else if envPort := os.Getenv("PORT"); envPort != "" {
-			port = envPort
-		} else {
-			port = "8080" // Default port
-		}
-		logger.Info("Starting Markdown Reader MCP server in SSE mode", "port", port)
-		sseServer := server.NewSSEServer(s)
-		if err := sseServer.Start(":" + port); err != nil {
-			logger.Error("SSE server error", "error", err)
-			os.Exit(1)
-		}
-	} else {
-		logger.Info("Starting Markdown Reader MCP server in stdio mode")
-		if err := server.ServeStdio(s); err != nil {
-			logger.Error("Server error", "error", err)
-			os.Exit(1)
-		}
+// bindAddress returns the address the SSE and HTTP servers listen on. An empty
+// host binds all interfaces.
+func bindAddress() string {
+	return net.JoinHostPort(config.SSEHost, serverPort())
+}
+
+// serverPort returns the port for the SSE and HTTP transports
+func serverPort() string {
+	if config.SSEPort != 0 {
+		return fmt.Sprintf("%d", config.SSEPort)
+	}
+	if envPort := os.Getenv("PORT"); envPort != "" {
+		return envPort
 	}
+	return "8080" // Default port
 }
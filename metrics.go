@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// MetricsPath is the path of the Prometheus metrics endpoint in the SSE and HTTP modes
+const MetricsPath = "/metrics"
+
+// counter is a Prometheus counter, only ever increasing while the server runs
+type counter struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+func (c *counter) inc() {
+	c.value.Add(1)
+}
+
+var (
+	findCallsTotal = &counter{
+		name: "markdown_reader_find_calls_total",
+		help: "Total number of find_markdown_files calls.",
+	}
+	resourceReadsTotal = &counter{
+		name: "markdown_reader_resource_reads_total",
+		help: "Total number of markdown file resource reads.",
+	}
+	readErrorsTotal = &counter{
+		name: "markdown_reader_read_errors_total",
+		help: "Total number of markdown file resource reads that failed.",
+	}
+	filesNotFoundTotal = &counter{
+		name: "markdown_reader_files_not_found_total",
+		help: "Total number of markdown file resource reads of files that were not found.",
+	}
+	directoryWalksTotal = &counter{
+		name: "markdown_reader_directory_walks_total",
+		help: "Total number of walks of the configured directories to find markdown files.",
+	}
+)
+
+// counters are the counters exposed by the metrics endpoint, in order
+var counters = []*counter{findCallsTotal, resourceReadsTotal, readErrorsTotal, filesNotFoundTotal, directoryWalksTotal}
+
+// handleMetrics writes the counters in the Prometheus text exposition format
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var sb strings.Builder
+	for _, c := range counters {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", c.name, c.help)
+		fmt.Fprintf(&sb, "# TYPE %s counter\n", c.name)
+		fmt.Fprintf(&sb, "%s %d\n", c.name, c.value.Load())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := w.Write([]byte(sb.String())); err != nil {
+		logger.Debug("metrics failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMetrics(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize})
+
+	ts := httptest.NewServer(newHTTPHandler(TransportHTTP, newServer()))
+	defer ts.Close()
+
+	scrape := func() map[string]int {
+		t.Helper()
+		resp, err := http.Get(ts.URL + MetricsPath)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", MetricsPath, err)
+		}
+		defer resp.Body.Close()
+
+		if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+			t.Errorf("Expected text/plain content type, got %q", contentType)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read metrics: %v", err)
+		}
+
+		values := map[string]int{}
+		for _, line := range strings.Split(string(body), "\n") {
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			name, value, _ := strings.Cut(line, " ")
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatalf("Invalid metric line %q", line)
+			}
+			values[name] = parsed
+		}
+		return values
+	}
+
+	before := scrape()
+
+	for range 2 {
+		if _, err := handleFindMarkdownFiles(context.Background(), mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, uri := range []string{"file://foo.md", "file://missing.md", "file://../foo.md"} {
+		handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+	}
+
+	after := scrape()
+
+	wantIncrease := map[string]int{
+		"markdown_reader_find_calls_total":      2,
+		"markdown_reader_resource_reads_total":  3,
+		"markdown_reader_read_errors_total":     2,
+		"markdown_reader_files_not_found_total": 1,
+		"markdown_reader_directory_walks_total": 1,
+	}
+	for name, want := range wantIncrease {
+		if got := after[name] - before[name]; got != want {
+			t.Errorf("Expected %s to increase by %d, got %d", name, want, got)
+		}
+	}
+}
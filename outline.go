@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+}
+
+func handleGetFileOutline(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("get_file_outline called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("get_file_outline failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	headings := parseHeadings(content)
+
+	jsonData, err := json.MarshalIndent(headings, "", "  ")
+	if err != nil {
+		logger.Debug("get_file_outline failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal outline: %w", err)), nil
+	}
+
+	logger.Debug("get_file_outline completed successfully", "headings_found", len(headings))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func handleGenerateTOC(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("generate_toc called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("generate_toc failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	// Comments in frontmatter look like headings
+	_, body, _ := splitFrontmatter(content)
+	toc := generateTOC(parseHeadings(body))
+
+	logger.Debug("generate_toc completed successfully", "bytes", len(toc))
+
+	return mcp.NewToolResultText(toc), nil
+}
+
+func handleReadSection(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+	headingText := extractStringParam(req.Params.Arguments, "heading")
+
+	logger.Debug("read_section called", "filename", filename, "heading", headingText)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+	if headingText == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: heading")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("read_section failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	section, err := extractSection(content, headingText)
+	if err != nil {
+		logger.Debug("read_section failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	logger.Debug("read_section completed successfully", "bytes", len(section))
+
+	return mcp.NewToolResultText(section), nil
+}
+
+// extractSection returns the first section whose heading text matches, ignoring
+// case, from its heading up to the next heading of the same or a higher level
+func extractSection(content string, headingText string) (string, error) {
+	// Comments in frontmatter look like headings
+	_, body, _ := splitFrontmatter(content)
+	headings := parseHeadings(body)
+
+	start := -1
+	for i, h := range headings {
+		if strings.EqualFold(h.Text, strings.TrimSpace(headingText)) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", errorWithCode(ErrorCodeHeadingNotFound, "heading not found: %s", headingText)
+	}
+
+	lines := strings.Split(body, "\n")
+	end := len(lines)
+	for _, h := range headings[start+1:] {
+		if h.Level <= headings[start].Level {
+			end = h.Line - 1
+			break
+		}
+	}
+
+	section := strings.Join(lines[headings[start].Line-1:end], "\n")
+	return strings.TrimRight(section, "\r\n") + "\n", nil
+}
+
+// generateTOC formats headings as a nested markdown list of links to their
+// GitHub-style anchors. Nesting is relative to the highest level heading.
+func generateTOC(headings []heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	minLevel := headings[0].Level
+	for _, h := range headings {
+		minLevel = min(minLevel, h.Level)
+	}
+
+	var toc strings.Builder
+	slugs := map[string]bool{}
+	for _, h := range headings {
+		slug := uniqueSlug(headingSlug(h.Text), slugs)
+		fmt.Fprintf(&toc, "%s- [%s](#%s)\n", strings.Repeat("  ", h.Level-minLevel), h.Text, slug)
+	}
+	return toc.String()
+}
+
+// headingSlug returns the anchor GitHub generates for a heading: lowercased,
+// punctuation other than hyphens and underscores stripped and spaces replaced
+// by hyphens
+func headingSlug(text string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			slug.WriteRune(r)
+		}
+	}
+	return slug.String()
+}
+
+// uniqueSlug suffixes a slug already used by an earlier heading with -1, -2 and
+// so on, as GitHub does, and records it as used
+func uniqueSlug(slug string, used map[string]bool) string {
+	unique := slug
+	for i := 1; used[unique]; i++ {
+		unique = slug + "-" + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// parseHeadings scans markdown content for ATX headings, skipping any lines
+// inside fenced code blocks. Line numbers are 1-based.
+func parseHeadings(content string) []heading {
+	headings := []heading{}
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if h, ok := parseHeadingLine(line); ok {
+			h.Line = i + 1
+			headings = append(headings, h)
+		}
+	}
+
+	return headings
+}
+
+// isFenceDelimiter reports whether the line opens or closes a fenced code block
+func isFenceDelimiter(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "```")
+}
+
+func parseHeadingLine(line string) (heading, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return heading{}, false
+	}
+
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		// "#tag" is not a heading
+		return heading{}, false
+	}
+
+	text := strings.TrimSpace(rest)
+
+	// Drop an optional closing sequence of #'s, e.g. "## Title ##"
+	if trimmed := strings.TrimRight(text, "#"); trimmed != text {
+		if trimmed == "" || strings.HasSuffix(trimmed, " ") {
+			text = strings.TrimSpace(trimmed)
+		}
+	}
+
+	return heading{Level: level, Text: text}, true
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseHeadings(t *testing.T) {
+	content := "# Title\n\n## Section\n\n```sh\n# comment\n```\n\n### Sub ###\n#tag\n####### too deep\n"
+
+	want := []heading{
+		{Level: 1, Text: "Title", Line: 1},
+		{Level: 2, Text: "Section", Line: 3},
+		{Level: 3, Text: "Sub", Line: 9},
+	}
+
+	got := parseHeadings(content)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHeadings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHandleGetFileOutline(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/outline"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name         string
+		filename     string
+		wantError    bool
+		wantHeadings []heading
+	}{
+		{
+			name:     "nested headings with code block",
+			filename: "outline.md",
+			wantHeadings: []heading{
+				{Level: 1, Text: "Guide", Line: 1},
+				{Level: 2, Text: "Install", Line: 5},
+				{Level: 3, Text: "From source", Line: 7},
+				{Level: 2, Text: "Usage", Line: 14},
+				{Level: 6, Text: "Deepest", Line: 18},
+			},
+		},
+		{
+			name:      "missing filename",
+			filename:  "",
+			wantError: true,
+		},
+		{
+			name:      "directory traversal attempt",
+			filename:  "../../etc/passwd",
+			wantError: true,
+		},
+		{
+			name:      "path-like filename",
+			filename:  "outline/outline.md",
+			wantError: true,
+		},
+		{
+			name:      "non-existent file",
+			filename:  "nonexistent.md",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "get_file_outline",
+					Arguments: map[string]any{"filename": tt.filename},
+				},
+			}
+
+			result, err := handleGetFileOutline(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+
+			if result.IsError {
+				t.Fatalf("Tool returned error: %v", result.Content)
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", result.Content[0])
+			}
+
+			var headings []heading
+			if err := json.Unmarshal([]byte(textContent.Text), &headings); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+
+			if !reflect.DeepEqual(headings, tt.wantHeadings) {
+				t.Errorf("Expected headings %+v, got %+v", tt.wantHeadings, headings)
+			}
+		})
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"My Heading!", "my-heading"},
+		{"Setup & Install", "setup--install"},
+		{"C++ vs. Go_lang", "c-vs-go_lang"},
+		{"Already-hyphenated", "already-hyphenated"},
+		{"Café Menu", "café-menu"},
+		{"100% Done?", "100-done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := headingSlug(tt.text); got != tt.want {
+				t.Errorf("headingSlug(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGenerateTOC(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/toc"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"filename": "toc"}
+	result, err := handleGenerateTOC(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	want := "- [Guide](#guide)\n" +
+		"  - [My Heading!](#my-heading)\n" +
+		"    - [Setup & Install](#setup--install)\n" +
+		"  - [FAQ](#faq)\n" +
+		"    - [Why?](#why)\n" +
+		"  - [FAQ](#faq-1)\n" +
+		"    - [Why?](#why-1)\n" +
+		"  - [C++ vs. Go_lang](#c-vs-go_lang)\n"
+	if got := result.Content[0].(mcp.TextContent).Text; got != want {
+		t.Errorf("Unexpected table of contents.\nExpected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestHandleReadSection(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/outline"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		heading   string
+		want      string
+		wantError bool
+	}{
+		{
+			name:    "section with subsection",
+			heading: "install",
+			want:    "## Install\n\n### From source\n\n```sh\n# build the binary\ngo build\n```\n",
+		},
+		{
+			name:    "nested subsection",
+			heading: "From Source",
+			want:    "### From source\n\n```sh\n# build the binary\ngo build\n```\n",
+		},
+		{
+			name:    "last section",
+			heading: "Usage",
+			want:    "## Usage ##\n\n#notaheading\n\n###### Deepest\n",
+		},
+		{
+			name:    "top level section",
+			heading: "Guide",
+			want:    "# Guide\n\nIntroduction to the guide.\n\n## Install\n\n### From source\n\n```sh\n# build the binary\ngo build\n```\n\n## Usage ##\n\n#notaheading\n\n###### Deepest\n",
+		},
+		{
+			name:      "heading inside code block",
+			heading:   "build the binary",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"filename": "outline", "heading": tt.heading}
+			result, err := handleReadSection(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.wantError {
+				if !result.IsError || !strings.Contains(text, "heading not found") {
+					t.Errorf("Expected a heading not found error, got %q", text)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("Unexpected tool error: %s", text)
+			}
+			if text != tt.want {
+				t.Errorf("Unexpected section.\nExpected:\n%q\nGot:\n%q", tt.want, text)
+			}
+		})
+	}
+}
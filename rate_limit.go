@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/time/rate"
+)
+
+type connLimiterKey struct{}
+
+// rateLimited reports whether requests are rate limited, which they are when
+// rate_limit_per_sec is set
+func rateLimited() bool {
+	return config.RateLimitPerSec > 0
+}
+
+// rateLimitBurst returns the number of requests a connection may make at once,
+// the rate rounded up when rate_limit_burst isn't set
+func rateLimitBurst() int {
+	if config.RateLimitBurst > 0 {
+		return config.RateLimitBurst
+	}
+	return max(1, int(math.Ceil(config.RateLimitPerSec)))
+}
+
+// withConnLimiter gives each connection its own token bucket, set as the
+// http.Server ConnContext so the bucket lives as long as the connection
+func withConnLimiter(ctx context.Context, _ net.Conn) context.Context {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	limit := rate.Inf // The limit was removed by a config reload
+	if rateLimited() {
+		limit = rate.Limit(config.RateLimitPerSec)
+	}
+	limiter := rate.NewLimiter(limit, rateLimitBurst())
+	return context.WithValue(ctx, connLimiterKey{}, limiter)
+}
+
+// withRateLimit rejects requests with 429 Too Many Requests once the connection
+// they arrive on has used up its tokens
+func withRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter, ok := r.Context().Value(connLimiterKey{}).(*rate.Limiter)
+		if ok && !limiter.Allow() {
+			logger.Debug("rate limit exceeded", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			retryAfter := max(1, int(math.Ceil(1/float64(limiter.Limit()))))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "rate limit exceeded, slow down", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newHTTPServer creates the server for the SSE and HTTP transports, limiting the
+// rate of requests on each connection when rate_limit_per_sec is set
+func newHTTPServer(address string, handler http.Handler) *http.Server {
+	srv := &http.Server{Addr: address, Handler: handler}
+	if rateLimited() {
+		srv.Handler = withRateLimit(handler)
+		srv.ConnContext = withConnLimiter
+	}
+	return srv
+}
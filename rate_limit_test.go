@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	config = Config{Directories: []string{"test/dir1"}, IndexTTLSeconds: -1, RateLimitPerSec: 0.5, RateLimitBurst: 2}
+
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config = newHTTPServer("", newHTTPHandler(TransportHTTP, newServer()))
+	ts.Start()
+	defer ts.Close()
+
+	get := func(client *http.Client) *http.Response {
+		t.Helper()
+		resp, err := client.Get(ts.URL + HealthPath)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", HealthPath, err)
+		}
+		// Read the body so the connection is reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	client := &http.Client{Transport: &http.Transport{}}
+	for i := range 2 {
+		if resp := get(client); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst to succeed, got %d", i+1, resp.StatusCode)
+		}
+	}
+
+	resp := get(client)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 once the burst is used, got %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", retryAfter)
+	}
+
+	// Each connection has its own bucket
+	other := &http.Client{Transport: &http.Transport{}}
+	if resp := get(other); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected a new connection not to be limited, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewHTTPServerWithoutRateLimit(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	config = Config{}
+	handler := http.NotFoundHandler()
+	srv := newHTTPServer(":0", handler)
+	if srv.ConnContext != nil {
+		t.Error("Expected no per connection state without a rate limit")
+	}
+	if srv.Addr != ":0" {
+		t.Errorf("Expected address :0, got %q", srv.Addr)
+	}
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	tests := []struct {
+		cfg  Config
+		want int
+	}{
+		{cfg: Config{RateLimitPerSec: 10}, want: 10},
+		{cfg: Config{RateLimitPerSec: 2.5}, want: 3},
+		{cfg: Config{RateLimitPerSec: 0.2}, want: 1},
+		{cfg: Config{RateLimitPerSec: 10, RateLimitBurst: 50}, want: 50},
+	}
+	for _, tt := range tests {
+		config = tt.cfg
+		if got := rateLimitBurst(); got != tt.want {
+			t.Errorf("Expected burst %d for %+v, got %d", tt.want, tt.cfg, got)
+		}
+	}
+}
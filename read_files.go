@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// readFileResult is the content of one file read by read_files, or why it couldn't be read
+type readFileResult struct {
+	Name      string `json:"name"`
+	Content   string `json:"content,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+func handleReadFiles(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filenames, err := extractStringSliceParam(req.Params.Arguments, "filenames")
+
+	log := requestLogger()
+	log.Debug("read_files called", "filenames", filenames)
+
+	if err != nil {
+		return toolErrorResult(err), nil
+	}
+	if len(filenames) == 0 {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filenames")), nil
+	}
+
+	maxFiles := config.MaxPageSize
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxPageSize
+	}
+	if len(filenames) > maxFiles {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "too many filenames: %d requested, at most %d can be read in one call", len(filenames), maxFiles)), nil
+	}
+
+	// Each file is resolved with the same checks as the resource, a failure only
+	// affects that file
+	files := make([]readFileResult, 0, len(filenames))
+	for _, filename := range filenames {
+		content, err := readMarkdownFile(filename)
+		if err != nil {
+			log.Debug("read_files could not read file", "filename", filename, "error", err)
+			files = append(files, readFileResult{Name: filename, Error: err.Error(), ErrorCode: errorCode(err)})
+			continue
+		}
+		files = append(files, readFileResult{Name: filename, Content: content})
+	}
+
+	result := map[string]any{
+		"files": files,
+		"count": len(files),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("read_files failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal files: %w", err)), nil
+	}
+
+	log.Debug("read_files completed successfully", "files", len(files))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// extractStringSliceParam returns an optional array of strings parameter, nil when absent
+func extractStringSliceParam(arguments any, name string) ([]string, error) {
+	argsMap, ok := arguments.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+
+	param, exists := argsMap[name]
+	if !exists || param == nil {
+		return nil, nil
+	}
+
+	items, ok := param.([]any)
+	if !ok {
+		return nil, errorWithCode(ErrorCodeInvalidArgument, "invalid %s parameter: must be an array of strings", name)
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, errorWithCode(ErrorCodeInvalidArgument, "invalid %s parameter: must be an array of strings", name)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleReadFiles(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/dir1"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"filenames": []any{"foo.md", "bar", "nonexistent.md", "../../etc/passwd", "/etc/passwd", "test.txt"},
+	}
+	result, err := handleReadFiles(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected partial failures not to fail the call, got %v", result.Content)
+	}
+
+	var response struct {
+		Files []readFileResult `json:"files"`
+		Count int              `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != 6 {
+		t.Fatalf("Expected 6 files, got %d", response.Count)
+	}
+
+	want := []struct {
+		name      string
+		content   string
+		wantError string
+	}{
+		{name: "foo.md", content: "# Foo\n\nFoo markdown document\n"},
+		{name: "bar", content: "# Bar\n\nBar markdown document\n"},
+		{name: "nonexistent.md", wantError: "file not found"},
+		{name: "../../etc/passwd", wantError: "directory traversal"},
+		{name: "/etc/passwd", wantError: "absolute paths"},
+		{name: "test.txt", wantError: "not found"},
+	}
+	for i, w := range want {
+		file := response.Files[i]
+		if file.Name != w.name {
+			t.Errorf("File %d: expected name %q, got %q", i, w.name, file.Name)
+		}
+		if file.Content != w.content {
+			t.Errorf("File %s: expected content %q, got %q", w.name, w.content, file.Content)
+		}
+		if w.wantError == "" && file.Error != "" {
+			t.Errorf("File %s: unexpected error %q", w.name, file.Error)
+		}
+		if w.wantError != "" && !strings.Contains(file.Error, w.wantError) {
+			t.Errorf("File %s: expected error containing %q, got %q", w.name, w.wantError, file.Error)
+		}
+	}
+}
+
+func TestHandleReadFilesInvalidRequests(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/dir1"},
+		MaxPageSize: 2,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantError string
+	}{
+		{name: "missing filenames", arguments: map[string]any{}, wantError: "missing required parameter"},
+		{name: "not an array", arguments: map[string]any{"filenames": "foo.md"}, wantError: "must be an array"},
+		{name: "not strings", arguments: map[string]any{"filenames": []any{"foo.md", 3.0}}, wantError: "must be an array"},
+		{name: "too many files", arguments: map[string]any{"filenames": []any{"foo.md", "bar.md", "baz.md"}}, wantError: "too many filenames"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.arguments
+			result, err := handleReadFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("Expected a tool error")
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.wantError) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantError, text)
+			}
+		})
+	}
+}
@@ -1,139 +1,875 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// DefaultResourceScheme is the URI scheme of the resource reading a markdown
+// file that may pick the configured directory to read it from by its label,
+// e.g. markdown://notes/foo.md, when resource_scheme is not configured
+const DefaultResourceScheme = "markdown"
+
+// resourceSchemePrefix returns the start of the URIs of the resource reading a
+// markdown file by directory label, the configured resource_scheme and "://"
+func resourceSchemePrefix() string {
+	scheme := DefaultResourceScheme
+	if config.ResourceScheme != "" {
+		scheme = config.ResourceScheme
+	}
+	return scheme + "://"
+}
+
+// errFileNotFound is returned when no markdown file matches a requested name
+var errFileNotFound = errors.New("file not found")
+
 func handleReadMarkdownFileResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-	logger.Debug("reading", "uri", req.Params.URI)
+	resourceReadsTotal.inc()
 
-	// Extract filename from template parameters (file://{filename})
-	filename := ""
-	if req.Params.Arguments != nil {
-		if filenameArg, ok := req.Params.Arguments["filename"].(string); ok {
-			filename = filenameArg
+	contents, err := readMarkdownFileResource(ctx, req)
+	if err != nil {
+		readErrorsTotal.inc()
+		if errors.Is(err, errFileNotFound) {
+			filesNotFoundTotal.inc()
 		}
+		return nil, &resourceError{err: err}
 	}
+	return contents, nil
+}
 
-	// Fallback: Extract from URI path for direct URI calls
-	if filename == "" && strings.HasPrefix(req.Params.URI, "file://") {
-		filename = strings.TrimPrefix(req.Params.URI, "file://")
+func readMarkdownFileResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log := requestLogger()
+	log.Debug("reading", "uri", req.Params.URI)
+
+	filename, dirs, err := resourceFilename(req)
+	if err != nil {
+		log.Debug("read_markdown_file_resource rejected URI", "uri", req.Params.URI, "error", err)
+		return nil, err
 	}
 
 	if filename == "" {
-		logger.Debug("read_markdown_file_resource missing filename parameter")
-		return nil, fmt.Errorf("missing required parameter: filename")
+		log.Debug("read_markdown_file_resource missing filename parameter")
+		return nil, errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")
 	}
 
-	logger.Debug("read_markdown_file_resource called", "filename", filename, "uri", req.Params.URI)
+	log.Debug("read_markdown_file_resource called", "filename", filename, "uri", req.Params.URI)
 
-	// Security check: ensure the file path doesn't contain directory traversal
-	if strings.Contains(filename, "..") {
-		logger.Debug("read_markdown_file_resource blocked directory traversal attempt", "filename", filename)
-		return nil, fmt.Errorf("invalid file path: directory traversal not allowed")
+	targetFile, err := resolveResourceFile(ctx, req.Params.URI, dirs, filename)
+	if err != nil {
+		log.Debug("read_markdown_file_resource could not resolve file", "filename", filename, "error", err)
+		return nil, err
 	}
 
-	var targetFile string
+	startLine, err := resourceIntParam(req, "start_line")
+	if err != nil {
+		return nil, err
+	}
+	endLine, err := resourceIntParam(req, "end_line")
+	if err != nil {
+		return nil, err
+	}
+	tailLines, err := resourceIntParam(req, "tail_lines")
+	if err != nil {
+		return nil, err
+	}
+	if tailLines > 0 && (startLine > 0 || endLine > 0) {
+		return nil, errorWithCode(ErrorCodeInvalidArgument, "tail_lines can't be combined with start_line or end_line")
+	}
+	format, err := resourceFormat(resourceParam(req, "format"))
+	if err != nil {
+		return nil, err
+	}
+	contentEncoding, err := resourceContentEncoding(resourceParam(req, "encoding"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the whole file, or stream just the requested lines
+	var content []byte
+	switch {
+	case tailLines > 0:
+		content, err = readTailLines(ctx, targetFile, tailLines)
+	case startLine > 0 || endLine > 0:
+		content, err = readLineRange(ctx, targetFile, startLine, endLine)
+	default:
+		content, err = readFileCached(ctx, targetFile)
+	}
+	if err != nil {
+		log.Debug("read_markdown_file_resource failed to read file", "error", err)
+		return nil, err
+	}
+
+	log.Debug("read_markdown_file_resource completed successfully", "bytes_read", len(content), "file", targetFile)
+
+	text := string(content)
+	encoding := ""
+	if config.DetectEncoding {
+		text, encoding = decodeText(content)
+		log.Debug("read_markdown_file_resource detected encoding", "file", targetFile, "encoding", encoding)
+	}
+
+	stripComments, err := resourceBoolParam(req, "strip_comments")
+	if err != nil {
+		return nil, err
+	}
+	if stripComments {
+		text = stripHTMLComments(text)
+	}
 
-	// Check if this is just a filename (no path separators) - if so, search for it
-	if !strings.Contains(filename, string(filepath.Separator)) {
-		// Search for the file by name across all configured directories
-		found, err := findFirstFileByName(filename)
+	trim, err := resourceBoolParam(req, "trim_content")
+	if err != nil {
+		return nil, err
+	}
+	trimTrailingWhitespace, err := resourceBoolParam(req, "trim_trailing_whitespace")
+	if err != nil {
+		return nil, err
+	}
+	if trim || trimTrailingWhitespace {
+		text = trimContent(text, trim, trimTrailingWhitespace)
+	}
+
+	mimeType := "text/markdown"
+	switch format {
+	case FormatHTML:
+		text, err = renderHTML(text)
 		if err != nil {
-			logger.Debug("read_markdown_file_resource error searching for file", "error", err)
-			return nil, fmt.Errorf("error searching for file: %v", err)
+			log.Debug("read_markdown_file_resource failed to render HTML", "error", err)
+			return nil, err
 		}
-		if found == "" {
-			logger.Debug("read_markdown_file_resource file not found", "filename", filename)
-			return nil, fmt.Errorf("file not found: %s", filename)
+		mimeType = "text/html"
+	case FormatText:
+		text = renderPlainText(text)
+		mimeType = "text/plain"
+	}
+
+	meta := map[string]any{}
+	if version, err := fileVersion(req.Params.URI, targetFile); err == nil {
+		meta["etag"] = version.ETag
+		meta["last_modified"] = version.ModTime.UTC().Format(time.RFC3339)
+	}
+	if encoding != "" {
+		meta["encoding"] = encoding
+	}
+	var resourceMeta *mcp.Meta
+	if len(meta) > 0 {
+		resourceMeta = &mcp.Meta{AdditionalFields: meta}
+	}
+
+	// Create resource content, as a base64 blob of the text when requested
+	if contentEncoding == ContentEncodingBase64 {
+		return []mcp.ResourceContents{mcp.BlobResourceContents{
+			Meta:     resourceMeta,
+			URI:      req.Params.URI,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString([]byte(text)),
+		}}, nil
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		Meta:     resourceMeta,
+		URI:      req.Params.URI,
+		MIMEType: mimeType,
+		Text:     text,
+	}}, nil
+}
+
+// resourceFilename returns the filename a resource read requests and the
+// configured directories to search for it, nil to search all of them unless a
+// markdown:// URI starts with the label of one
+func resourceFilename(req mcp.ReadResourceRequest) (string, []string, error) {
+	if prefix := resourceSchemePrefix(); strings.HasPrefix(req.Params.URI, prefix) {
+		// Extract from template parameters (markdown://{+path}), falling back
+		// to the URI path for direct URI calls
+		path, _ := req.Params.Arguments["path"].(string)
+		if path == "" {
+			path, _, _ = strings.Cut(strings.TrimPrefix(req.Params.URI, prefix), "?")
 		}
-		targetFile = found
-		logger.Debug("read_markdown_file_resource found file", "file", targetFile)
-	} else {
-		logger.Debug("read_markdown_file_resource rejected path-like filename", "filename", filename)
-		return nil, fmt.Errorf("filename looks like a path, it should be just the name of file")
+		return labelledFilename(path)
 	}
 
-	// Check if file exists and is a markdown file
-	if !strings.HasSuffix(strings.ToLower(targetFile), ".md") {
-		logger.Debug("read_markdown_file_resource rejected non-markdown file", "file", targetFile)
-		return nil, fmt.Errorf("file is not a markdown file: %s", targetFile)
+	// Extract filename from template parameters (file://{filename})
+	filename, _ := req.Params.Arguments["filename"].(string)
+
+	// Fallback: Extract from URI path for direct URI calls
+	if filename == "" && strings.HasPrefix(req.Params.URI, "file://") {
+		filename, _, _ = strings.Cut(strings.TrimPrefix(req.Params.URI, "file://"), "?")
 	}
+	return filename, nil, nil
+}
 
-	// Read the file
-	content, err := os.ReadFile(targetFile)
+// labelledFilename splits the path of a markdown:// URI into the directory label
+// and the filename within that directory. A path without a label, e.g. foo.md,
+// is searched for in all configured directories.
+func labelledFilename(path string) (string, []string, error) {
+	label, filename, ok := strings.Cut(path, "/")
+	if !ok {
+		return path, nil, nil
+	}
+	dir, err := findDirectoryByLabel(label)
 	if err != nil {
-		logger.Debug("read_markdown_file_resource failed to read file", "error", err)
-		return nil, fmt.Errorf("failed to read file %s: %v", targetFile, err)
+		return "", nil, err
 	}
+	return filename, []string{dir}, nil
+}
 
-	logger.Debug("read_markdown_file_resource completed successfully", "bytes_read", len(content), "file", targetFile)
+// resourceParam returns an optional parameter of a resource read. Parameters are
+// taken from the matched URI template variables, falling back to the query string
+// of the URI when the template did not capture them.
+func resourceParam(req mcp.ReadResourceRequest, name string) string {
+	if value, ok := req.Params.Arguments[name].(string); ok && value != "" {
+		return value
+	}
 
-	// Create resource content
-	resourceContent := mcp.TextResourceContents{
-		URI:      req.Params.URI,
-		MIMEType: "text/markdown",
-		Text:     string(content),
+	_, rawQuery, found := strings.Cut(req.Params.URI, "?")
+	if !found {
+		return ""
 	}
 
-	return []mcp.ResourceContents{resourceContent}, nil
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		logger.Debug("could not parse resource query", "uri", req.Params.URI, "error", err)
+		return ""
+	}
+
+	return query.Get(name)
 }
 
-// findFirstFileByName searches for a markdown file by name across all configured directories
-// and returns the first match found
-func findFirstFileByName(filename string) (string, error) {
-	// Ensure the filename has .md extension if not provided
-	if !strings.HasSuffix(strings.ToLower(filename), ".md") {
-		filename = filename + ".md"
+// resourceBoolParam returns an optional boolean parameter of a resource read,
+// false when absent
+func resourceBoolParam(req mcp.ReadResourceRequest, name string) (bool, error) {
+	value := resourceParam(req, name)
+	if value == "" {
+		return false, nil
 	}
 
-	for _, dir := range config.Directories {
-		absDir, err := filepath.Abs(dir)
-		if err != nil {
-			logger.Warn("Could not resolve absolute path", "directory", dir, "error", err)
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errorWithCode(ErrorCodeInvalidArgument, "invalid %s parameter %q: must be true or false", name, value)
+	}
+
+	return parsed, nil
+}
+
+// resourceIntParam returns an optional non-negative integer parameter of a resource
+// read, 0 when absent
+func resourceIntParam(req mcp.ReadResourceRequest, name string) (int, error) {
+	value := resourceParam(req, name)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid %s parameter %q: must be a non-negative integer", name, value)
+	}
+
+	return parsed, nil
+}
+
+// trimContent strips leading and trailing blank lines and optionally the trailing
+// whitespace of every line
+func trimContent(content string, blankLines bool, trailingWhitespace bool) string {
+	lines := strings.Split(content, "\n")
+
+	if trailingWhitespace {
+		for i, line := range lines {
+			carriageReturn := strings.HasSuffix(line, "\r")
+			line = strings.TrimRight(line, " \t\r")
+			if carriageReturn {
+				line += "\r"
+			}
+			lines[i] = line
+		}
+	}
+
+	if blankLines {
+		start := 0
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		end := len(lines)
+		for end > start && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		if start == end {
+			return ""
+		}
+		if end < len(lines) {
+			// Keep a single final line ending
+			lines = append(lines[start:end], "")
+		} else {
+			lines = lines[start:end]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// stripHTMLComments removes <!-- --> comments, including those spanning lines.
+// Comments inside fenced code blocks and code spans are examples rather than
+// comments, so they are kept. Lines that only held a comment are dropped.
+func stripHTMLComments(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	inFence := false
+	inComment := false
+
+	for i, line := range lines {
+		if !inComment && isFenceDelimiter(line) {
+			inFence = !inFence
+			kept = append(kept, line)
+			continue
+		}
+		if inFence {
+			kept = append(kept, line)
 			continue
 		}
 
-		// Check if directory exists
-		if _, err := os.Stat(absDir); os.IsNotExist(err) {
-			logger.Warn("Directory does not exist", "directory", absDir)
+		wasInComment := inComment
+		var stripped string
+		stripped, inComment = stripLineComments(line, inComment)
+		// The empty line after a final newline is kept so the newline is too
+		final := i == len(lines)-1 && line == ""
+		if (wasInComment || stripped != line) && strings.TrimSpace(stripped) == "" && !final {
 			continue
 		}
+		kept = append(kept, stripped)
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// stripLineComments removes the comments from a line, which starts inside a
+// comment when inComment is set, reporting whether a comment is still open at
+// the end of the line. Code spans are copied as is.
+func stripLineComments(line string, inComment bool) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		if inComment {
+			end := strings.Index(line[i:], "-->")
+			if end < 0 {
+				return b.String(), true
+			}
+			i += end + len("-->")
+			inComment = false
+			continue
+		}
+
+		switch {
+		case line[i] == '`':
+			run := i
+			for run < len(line) && line[run] == '`' {
+				run++
+			}
+			ticks := line[i:run]
+			end := strings.Index(line[run:], ticks)
+			if end < 0 {
+				b.WriteString(ticks)
+				i = run
+				continue
+			}
+			end = run + end + len(ticks)
+			b.WriteString(line[i:end])
+			i = end
+		case strings.HasPrefix(line[i:], "<!--"):
+			inComment = true
+			i += len("<!--")
+		default:
+			b.WriteByte(line[i])
+			i++
+		}
+	}
+	return b.String(), inComment
+}
+
+// resolveMarkdownFile applies the security checks for a requested filename and
+// resolves it to the path of a markdown file in the configured directories
+func resolveMarkdownFile(filename string) (string, error) {
+	return resolveMarkdownFileIn(nil, filename)
+}
+
+// resolveMarkdownFileIn resolves a requested filename like resolveMarkdownFile,
+// searching only the given configured directories. With nil dirs all of them
+// are searched, followed by the allow_files_paths files.
+func resolveMarkdownFileIn(dirs []string, filename string) (string, error) {
+	if err := validateRequestedFilename(filename); err != nil {
+		logger.Debug("rejected requested filename", "filename", filename, "error", err)
+		return "", err
+	}
+
+	searchDirs := dirs
+	if dirs == nil {
+		searchDirs = config.Directories
+	}
+	targetFile, err := findFirstFileByNameIn(searchDirs, filename)
+	if errors.Is(err, errFileNotFound) && dirs == nil {
+		// Files listed in allow_files_paths are served as they are, having
+		// been checked to be the listed markdown files
+		if allowed, ok := findAllowedFile(filename); ok {
+			logger.Debug("found allowed file", "file", allowed)
+			return allowed, nil
+		}
+	}
+	if err != nil {
+		logger.Debug("error searching for file", "filename", filename, "error", err)
+		return "", fmt.Errorf("error searching for file: %w", err)
+	}
+	logger.Debug("found file", "file", targetFile)
+
+	// Check the file is a markdown file
+	if !config.isMarkdownFile(targetFile) {
+		logger.Debug("rejected non-markdown file", "file", targetFile)
+		return "", errorWithCode(ErrorCodeNotMarkdown, "file is not a markdown file: %s", targetFile)
+	}
+
+	// Check a symlinked file doesn't lead outside the configured directories
+	if err := config.checkSymlink(targetFile); err != nil {
+		logger.Debug("rejected symlink", "file", targetFile, "error", err)
+		return "", withErrorCode(ErrorCodeTraversalBlocked, err)
+	}
+
+	return targetFile, nil
+}
+
+// validateRequestedFilename checks a requested filename is a plain file name, or
+// a relative path suffix such as projects/index.md to tell apart files with the
+// same name. Directory traversal, absolute paths, volume names and backslash
+// separators are rejected the same way on every platform, so a Windows-style
+// name such as C:foo or \\server\share is refused on Unix too.
+func validateRequestedFilename(name string) error {
+	if strings.Contains(name, "..") {
+		return errorWithCode(ErrorCodeTraversalBlocked, "invalid file path: directory traversal not allowed")
+	}
+
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) {
+		return errorWithCode(ErrorCodeTraversalBlocked, "invalid file path: absolute paths not allowed")
+	}
+
+	if filepath.VolumeName(name) != "" || hasDriveLetter(name) {
+		return errorWithCode(ErrorCodeTraversalBlocked, "invalid file path: volume names not allowed")
+	}
+
+	// Names are searched for across all configured directories, so only plain
+	// filenames and path suffixes separated by forward slashes are accepted
+	if strings.Contains(name, `\`) {
+		return errorWithCode(ErrorCodeTraversalBlocked, "filename looks like a path, it should be just the name of file")
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part == "." {
+			return errorWithCode(ErrorCodeInvalidArgument, "invalid file path: %s is not a relative path suffix such as projects/index.md", name)
+		}
+	}
+
+	return nil
+}
+
+// hasDriveLetter reports whether a name starts with a Windows drive letter, e.g. C:
+func hasDriveLetter(name string) bool {
+	if len(name) < 2 || name[1] != ':' {
+		return false
+	}
+	letter := name[0] | 0x20 // lower case
+	return letter >= 'a' && letter <= 'z'
+}
+
+// readMarkdownFile resolves a requested filename and returns its content
+func readMarkdownFile(filename string) (string, error) {
+	targetFile, err := resolveMarkdownFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := readFileContent(targetFile)
+	if err != nil {
+		logger.Debug("failed to read file", "file", targetFile, "error", err)
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// maxFileSize returns the configured limit on the size of files read, 0 meaning unlimited
+func maxFileSize() int64 {
+	if config.MaxFileSize == nil {
+		return DefaultMaxFileSize
+	}
+	return *config.MaxFileSize
+}
+
+// readFileContent reads a file, refusing files larger than the max_file_size limit
+// before reading them into memory. Gzip compressed markdown files are
+// decompressed, the limit applying to their decompressed size too.
+func readFileContent(path string) ([]byte, error) {
+	return readFileContentContext(context.Background(), path)
+}
+
+// readFileContentContext is readFileContent reading the file in chunks, giving up
+// with the context's error when it is done, e.g. an SSE client disconnected
+// part way through reading a large file from a slow disk
+func readFileContentContext(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+	limit := maxFileSize()
+	if limit > 0 && info.Size() > limit {
+		return nil, errorWithCode(ErrorCodeTooLarge, "file %s is too large to read: %d bytes exceeds the limit of %d bytes", filepath.Base(path), info.Size(), limit)
+	}
+
+	reader, err := markdownReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+
+	content := make([]byte, 0, info.Size())
+	chunk := make([]byte, ReadChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := reader.Read(chunk)
+		content = append(content, chunk[:n]...)
+		// A compressed file can decompress to far more than its size on disk
+		if limit > 0 && int64(len(content)) > limit {
+			return nil, errorWithCode(ErrorCodeTooLarge, "file %s is too large to read: more than the limit of %d bytes", filepath.Base(path), limit)
+		}
+		if err == io.EOF {
+			return content, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+		}
+	}
+}
 
-		var foundFile string
-		err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil // Skip files that can't be accessed
+// readLineRange reads lines start to end (1-based, inclusive) without loading the
+// rest of the file, so parts of files over the size limit can still be read. A zero
+// start reads from the first line and a zero end reads to the last. Out of range
+// lines are clamped. The size limit applies to the returned lines. Reading stops
+// when the context is done.
+func readLineRange(ctx context.Context, path string, start, end int) ([]byte, error) {
+	if start < 1 {
+		start = 1
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	markdown, err := markdownReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+
+	limit := maxFileSize()
+	reader := bufio.NewReader(markdown)
+	var content []byte
+	for lineNumber := 1; end == 0 || lineNumber <= end; lineNumber++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line, err := reader.ReadBytes('\n')
+		if lineNumber >= start {
+			content = append(content, line...)
+			if limit > 0 && int64(len(content)) > limit {
+				return nil, errorWithCode(ErrorCodeTooLarge, "requested lines of file %s are too large to read: more than the limit of %d bytes", filepath.Base(path), limit)
 			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return content, nil
+}
+
+// readTailLines reads the last n lines of a file. The file is read backwards from
+// its end a chunk at a time, so the tail of a large file is read without loading
+// the rest of it, and a file with fewer lines is returned whole. A newline ending
+// the file doesn't start another line. The size limit applies to the returned
+// lines. Gzip compressed files can't be read backwards and are streamed instead.
+// Reading stops when the context is done.
+func readTailLines(ctx context.Context, path string, n int) ([]byte, error) {
+	if config.isGzipMarkdownFile(path) {
+		return readTailLinesStream(ctx, path, n)
+	}
 
-			// Skip directories that match ignore patterns
-			if d.IsDir() && shouldIgnoreDir(d.Name()) {
-				return filepath.SkipDir
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+
+	limit := maxFileSize()
+	size := info.Size()
+	var tail []byte
+	newlines := 0
+	for offset := size; offset > 0; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk := make([]byte, min(ReadChunkSize, offset))
+		offset -= int64(len(chunk))
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+		}
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' || offset+int64(i) == size-1 {
+				continue
 			}
+			if newlines++; newlines == n {
+				chunk = chunk[i+1:]
+				offset = 0
+				break
+			}
+		}
+
+		tail = append(chunk, tail...)
+		if limit > 0 && int64(len(tail)) > limit {
+			return nil, errorWithCode(ErrorCodeTooLarge, "requested lines of file %s are too large to read: more than the limit of %d bytes", filepath.Base(path), limit)
+		}
+	}
+
+	return tail, nil
+}
+
+// readTailLinesStream reads the last n lines of a file from start to end,
+// keeping only the most recent n lines in memory
+func readTailLinesStream(ctx context.Context, path string, n int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	markdown, err := markdownReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+	}
 
-			if !d.IsDir() && strings.EqualFold(d.Name(), filename) {
-				foundFile = path
-				return filepath.SkipAll // Stop searching immediately after finding the first match
+	limit := maxFileSize()
+	reader := bufio.NewReader(markdown)
+	var lines [][]byte
+	size := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lines = append(lines, line)
+			size += len(line)
+			if len(lines) > n {
+				size -= len(lines[0])
+				lines = lines[1:]
+			}
+			if limit > 0 && int64(size) > limit {
+				return nil, errorWithCode(ErrorCodeTooLarge, "requested lines of file %s are too large to read: more than the limit of %d bytes", filepath.Base(path), limit)
 			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %v", filepath.Base(path), err)
+		}
+	}
 
-			return nil
-		})
+	return bytes.Join(lines, nil), nil
+}
+
+// findFirstFileByName searches for a markdown file by name across all configured directories
+// and returns the first match found. When the name matches more than one file the
+// ambiguous_read config decides whether the first match, the newest match or an
+// error listing the candidates is returned.
+func findFirstFileByName(filename string) (string, error) {
+	return findFirstFileByNameIn(config.Directories, filename)
+}
+
+// findFirstFileByNameIn searches for a markdown file by name like
+// findFirstFileByName, searching only the given configured directories
+func findFirstFileByNameIn(dirs []string, filename string) (string, error) {
+	candidates := nameCandidates(filename)
+
+	mode := config.AmbiguousRead
+	if mode == "" {
+		mode = AmbiguousReadFirst
+	}
+
+	matches := findFilesByNameIn(dirs, candidates, mode == AmbiguousReadFirst)
+	if len(matches) == 0 && config.TitleLookup {
+		if path, ok := findFileByTitle(dirs, filename); ok {
+			return path, nil
+		}
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%w: %s", errFileNotFound, strings.Join(candidates, " or "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	switch mode {
+	case AmbiguousReadError:
+		paths := make([]string, 0, len(matches))
+		for _, match := range matches {
+			paths = append(paths, configuredRelativePath(match))
+		}
+		return "", errorWithCode(ErrorCodeAmbiguous, "ambiguous filename %s matches %d files: %s", filename, len(matches), strings.Join(paths, ", "))
+	case AmbiguousReadNewest:
+		return newestFile(matches), nil
+	default:
+		return matches[0], nil
+	}
+}
+
+// nameCandidates returns the file names a requested name may refer to, trying
+// each markdown extension if the name doesn't have one
+func nameCandidates(filename string) []string {
+	if config.isMarkdownFile(filename) {
+		return []string{filename}
+	}
+	candidates := make([]string, 0, len(config.markdownExtensions()))
+	for _, ext := range config.markdownExtensions() {
+		candidates = append(candidates, filename+ext)
+	}
+	return candidates
+}
+
+// findFileByTitle returns the first file in the given configured directories, in
+// directory order, whose frontmatter title equals the title, ignoring case
+func findFileByTitle(dirs []string, title string) (string, bool) {
+	title = strings.TrimSpace(title)
+	for _, file := range markdownIndex.entries() {
+		if !slices.Contains(dirs, file.Dir) {
+			continue
+		}
+		if fileTitle, ok := frontmatterTitle(file.Path); ok && strings.EqualFold(fileTitle, title) {
+			return file.Path, true
+		}
+	}
+	return "", false
+}
+
+// findFilesByName returns the files matching any of the names (case-insensitive) across
+// all configured directories, in directory order. A name with forward slashes, e.g.
+// projects/index.md, matches the trailing path components of files within the
+// configured directory. If firstOnly is set the search stops at the first match.
+func findFilesByName(filenames []string, firstOnly bool) []string {
+	return findFilesByNameIn(config.Directories, filenames, firstOnly)
+}
+
+// findFilesByNameIn returns the files matching any of the names like
+// findFilesByName, searching only the given configured directories
+func findFilesByNameIn(dirs []string, filenames []string, firstOnly bool) []string {
+	var matches []string
+
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
 		if err != nil {
-			logger.Warn("Error walking directory", "directory", absDir, "error", err)
+			continue
 		}
+		walkMarkdownFiles(dir, func(path string, d fs.DirEntry) error {
+			if slices.ContainsFunc(filenames, func(filename string) bool {
+				if !strings.Contains(filename, "/") {
+					return strings.EqualFold(d.Name(), filename) || strings.EqualFold(markdownName(path), filename)
+				}
+				rel, err := filepath.Rel(absDir, filepath.Join(filepath.Dir(path), markdownName(path)))
+				return err == nil && hasPathSuffix(filepath.ToSlash(rel), filename)
+			}) {
+				matches = append(matches, path)
+				if firstOnly {
+					return filepath.SkipAll // Stop searching immediately after finding the first match
+				}
+			}
+			return nil
+		})
 
 		// Return immediately if we found a file in this directory
-		if foundFile != "" {
-			return foundFile, nil
+		if firstOnly && len(matches) > 0 {
+			return matches
+		}
+	}
+
+	return matches
+}
+
+// hasPathSuffix reports whether the slash separated path ends with the whole
+// path components of suffix, ignoring case
+func hasPathSuffix(path, suffix string) bool {
+	path = strings.ToLower(path)
+	suffix = strings.ToLower(suffix)
+	return path == suffix || strings.HasSuffix(path, "/"+suffix)
+}
+
+// newestFile returns the most recently modified of the given files
+func newestFile(files []string) string {
+	var newest string
+	var newestTime time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Debug("Could not stat file", "file", file, "error", err)
+			continue
 		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest = file
+			newestTime = info.ModTime()
+		}
+	}
+	if newest == "" {
+		return files[0]
 	}
+	return newest
+}
 
-	return "", fmt.Errorf("file not found: %s", filename)
+// configuredRelativePath describes a file by the label of the configured directory
+// it was found in and its path relative to that directory, e.g. notes/projects/a.md,
+// avoiding exposing absolute paths
+func configuredRelativePath(path string) string {
+	for _, dir := range config.Directories {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(absDir, path); err == nil && filepath.IsLocal(rel) {
+			return directoryLabel(dir) + "/" + filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(path)
 }
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -85,6 +93,60 @@ func TestFindFirstFileByName(t *testing.T) {
 	}
 }
 
+func TestResolveMarkdownFileFinalExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"notes.md", "notes.md.bak", "something.md.txt", "draft.md.txt.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Note\n"), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}, MaxPageSize: DefaultMaxPageSize})
+
+	tests := []struct {
+		filename  string
+		want      string
+		wantError bool
+	}{
+		{filename: "notes", want: "notes.md"},
+		{filename: "notes.md", want: "notes.md"},
+		{filename: "notes.md.bak", wantError: true},
+		{filename: "something.md.txt", wantError: true},
+		{filename: "draft.md.txt", want: "draft.md.txt.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			path, err := resolveMarkdownFileIn(nil, tt.filename)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error but resolved %s", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if filepath.Base(path) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, path)
+			}
+		})
+	}
+
+	// Only files whose final extension is markdown are found by the walk
+	files, err := findMarkdownFiles("", DefaultMaxPageSize)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	sort.Strings(names)
+	if want := []string{"draft.md.txt.md", "notes.md"}; !slices.Equal(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+}
+
 func TestHandleReadMarkdownFileResource(t *testing.T) {
 	// Setup test environment
 	oldConfig := config
@@ -187,3 +249,1150 @@ func TestHandleReadMarkdownFileResource(t *testing.T) {
 		})
 	}
 }
+
+func TestFindFirstFileByNameAmbiguousRead(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	fileA := "test/ambiguous/a/notes.md"
+	fileB := "test/ambiguous/b/notes.md"
+
+	// Make the second file the most recently modified
+	now := time.Now()
+	if err := os.Chtimes(fileA, now.Add(-time.Hour), now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+	if err := os.Chtimes(fileB, now, now); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		mode      string
+		wantFile  string
+		wantError string
+	}{
+		{
+			name:     "default mode returns first match",
+			mode:     "",
+			wantFile: fileA,
+		},
+		{
+			name:     "first mode returns first match",
+			mode:     AmbiguousReadFirst,
+			wantFile: fileA,
+		},
+		{
+			name:     "newest mode returns most recently modified",
+			mode:     AmbiguousReadNewest,
+			wantFile: fileB,
+		},
+		{
+			name:      "error mode lists candidates",
+			mode:      AmbiguousReadError,
+			wantError: "matches 2 files: ambiguous/a/notes.md, ambiguous/b/notes.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{Directories: []string{"test/ambiguous"}, AmbiguousRead: tt.mode}
+
+			result, err := findFirstFileByName("notes")
+
+			if tt.wantError != "" {
+				if err == nil {
+					t.Fatalf("Expected error but got %s", result)
+				}
+				if !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error to contain %q, got %q", tt.wantError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			wantAbs, _ := filepath.Abs(tt.wantFile)
+			if result != wantAbs {
+				t.Errorf("Expected %s, got %s", wantAbs, result)
+			}
+		})
+	}
+
+	t.Run("error mode with an absolute directory", func(t *testing.T) {
+		absDir, err := filepath.Abs("test/ambiguous")
+		if err != nil {
+			t.Fatalf("Failed to resolve directory: %v", err)
+		}
+		config = Config{Directories: []string{absDir}, AmbiguousRead: AmbiguousReadError}
+
+		_, err = findFirstFileByName("notes")
+		if err == nil {
+			t.Fatal("Expected an ambiguous filename error")
+		}
+		if strings.Contains(err.Error(), absDir) {
+			t.Errorf("Expected the candidates without absolute paths, got %q", err.Error())
+		}
+		if !strings.Contains(err.Error(), "ambiguous/a/notes.md, ambiguous/b/notes.md") {
+			t.Errorf("Expected the candidates relative to the directory label, got %q", err.Error())
+		}
+	})
+
+	t.Run("error mode with a single match", func(t *testing.T) {
+		config = Config{Directories: []string{"test/ambiguous/a"}, AmbiguousRead: AmbiguousReadError}
+		if _, err := findFirstFileByName("notes"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestFindFirstFileByNameTitleLookup(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/title_lookup"}})
+
+	if _, err := findFirstFileByName("Weekly Review"); err == nil {
+		t.Error("Expected a title not to be found when title_lookup is disabled")
+	}
+
+	config.TitleLookup = true
+	wantWeekly, _ := filepath.Abs("test/title_lookup/2024-w03.md")
+	wantBooks, _ := filepath.Abs("test/title_lookup/books.md")
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantFile  string
+		wantError bool
+	}{
+		{name: "title", filename: "Weekly Review", wantFile: wantWeekly},
+		{name: "title ignores case", filename: "weekly review", wantFile: wantWeekly},
+		{name: "file name still preferred", filename: "books", wantFile: wantBooks},
+		{name: "unknown title", filename: "Monthly Review", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := findFirstFileByName(tt.filename)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error but got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.wantFile {
+				t.Errorf("Expected %s, got %s", tt.wantFile, result)
+			}
+		})
+	}
+}
+
+func TestFindFirstFileByNamePathSuffix(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	fileA, _ := filepath.Abs("test/ambiguous/a/notes.md")
+	fileB, _ := filepath.Abs("test/ambiguous/b/notes.md")
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantFile  string
+		wantError bool
+	}{
+		{name: "first folder", filename: "a/notes.md", wantFile: fileA},
+		{name: "second folder", filename: "b/notes", wantFile: fileB},
+		{name: "ignores case", filename: "B/Notes.MD", wantFile: fileB},
+		{name: "unknown folder", filename: "c/notes.md", wantError: true},
+		{name: "suffix beyond the configured directory", filename: "ambiguous/a/notes.md", wantError: true},
+		{name: "partial folder name", filename: "ba/notes.md", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A path suffix picks one file even when reading ambiguous names fails
+			config = Config{Directories: []string{"test/ambiguous"}, AmbiguousRead: AmbiguousReadError}
+
+			result, err := findFirstFileByName(tt.filename)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Expected error but got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.wantFile {
+				t.Errorf("Expected %s, got %s", tt.wantFile, result)
+			}
+		})
+	}
+
+	t.Run("resource", func(t *testing.T) {
+		config = Config{Directories: []string{"test/ambiguous"}}
+
+		req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "file://b/notes.md"}}
+		result, err := handleReadMarkdownFileResource(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want, _ := os.ReadFile(fileB)
+		if got := result[0].(mcp.TextResourceContents).Text; got != string(want) {
+			t.Errorf("Expected content of b/notes.md %q, got %q", want, got)
+		}
+	})
+}
+
+func TestHandleReadMarkdownFileResourceTrimContent(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/trim"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	raw := "\n\n  \n# Padded   \n\nBody text\t\n\n\n"
+
+	tests := []struct {
+		name        string
+		uri         string
+		arguments   map[string]any
+		wantError   bool
+		wantContent string
+	}{
+		{
+			name:        "default preserves exact content",
+			uri:         "file://padded.md",
+			wantContent: raw,
+		},
+		{
+			name:        "trim blank lines from query",
+			uri:         "file://padded.md?trim_content=true",
+			wantContent: "# Padded   \n\nBody text\t\n",
+		},
+		{
+			name:        "trim blank lines from template arguments",
+			uri:         "file://padded.md?trim_content=true",
+			arguments:   map[string]any{"filename": "padded.md", "trim_content": "true"},
+			wantContent: "# Padded   \n\nBody text\t\n",
+		},
+		{
+			name:        "trim blank lines and trailing whitespace",
+			uri:         "file://padded.md?trim_trailing_whitespace=true&trim_content=true",
+			wantContent: "# Padded\n\nBody text\n",
+		},
+		{
+			name:        "trim disabled explicitly",
+			uri:         "file://padded.md?trim_content=false",
+			wantContent: raw,
+		},
+		{
+			name:      "invalid trim value",
+			uri:       "file://padded.md?trim_content=maybe",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.ReadResourceRequest{
+				Params: mcp.ReadResourceParams{
+					URI:       tt.uri,
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handleReadMarkdownFileResource(context.Background(), req)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			textResourceContent, ok := result[0].(mcp.TextResourceContents)
+			if !ok {
+				t.Fatalf("Expected TextResourceContents, got %T", result[0])
+			}
+
+			if textResourceContent.Text != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, textResourceContent.Text)
+			}
+		})
+	}
+}
+
+func TestTrimContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no final newline", "\n\ntext", "text"},
+		{"only blank lines", "\n \n\t\n", ""},
+		{"CRLF line endings", "\r\n# Title\r\n\r\n", "# Title\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimContent(tt.content, true, false); got != tt.want {
+				t.Errorf("trimContent(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripHTMLComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no comments", "# Title\n\nText\n", "# Title\n\nText\n"},
+		{"single-line comment", "Text <!-- private --> more\n", "Text  more\n"},
+		{"line holding only a comment", "Before\n<!-- private -->\nAfter\n", "Before\nAfter\n"},
+		{"multi-line comment", "Before\n<!--\nTODO\n-->\nAfter\n", "Before\nAfter\n"},
+		{"multi-line comment after text", "Before <!-- start\nmiddle\nend --> after\n", "Before \n after\n"},
+		{"several comments on a line", "a<!-- 1 -->b<!-- 2 -->c\n", "abc\n"},
+		{"comment in fenced code block", "```html\n<!-- example -->\n```\n", "```html\n<!-- example -->\n```\n"},
+		{"comment in code span", "Write `<!-- note -->` to hide text\n", "Write `<!-- note -->` to hide text\n"},
+		{"fence inside a comment", "<!--\n```\n-->\nText\n", "Text\n"},
+		{"unclosed comment", "Text\n<!-- never closed\nhidden\n", "Text\n"},
+		{"CRLF line endings", "Text\r\n<!-- private -->\r\nMore\r\n", "Text\r\nMore\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripHTMLComments(tt.content); got != tt.want {
+				t.Errorf("stripHTMLComments(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleReadMarkdownFileResourceStripComments(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+	config = Config{Directories: []string{"test/comments"}}
+
+	read := func(uri string) string {
+		t.Helper()
+		result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result[0].(mcp.TextResourceContents).Text
+	}
+
+	// Comments are kept by default
+	if text := read("file://private.md"); !strings.Contains(text, "call the bank") || !strings.Contains(text, "TODO") {
+		t.Errorf("Expected comments to be kept by default, got %q", text)
+	}
+
+	text := read("file://private.md?strip_comments=true")
+	for _, hidden := range []string{"call the bank", "TODO", "before sharing"} {
+		if strings.Contains(text, hidden) {
+			t.Errorf("Expected comment text %q to be stripped, got %q", hidden, text)
+		}
+	}
+	for _, visible := range []string{"Visible text.", "More visible text.", "<!-- an example comment -->"} {
+		if !strings.Contains(text, visible) {
+			t.Errorf("Expected %q to be kept, got %q", visible, text)
+		}
+	}
+}
+
+func TestHandleReadMarkdownFileResourceWithExtensions(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/extensions"},
+		Extensions:  []string{".md", ".markdown", ".mdx"},
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name        string
+		filename    string
+		wantError   bool
+		wantContent string
+	}{
+		{
+			name:        "read markdown extension",
+			filename:    "guide.markdown",
+			wantContent: "# Guide\n\nWritten with the long extension.\n",
+		},
+		{
+			name:        "read mdx without extension",
+			filename:    "widget",
+			wantContent: "# Widget\n\n<Widget />\n",
+		},
+		{
+			name:        "read uppercase extension",
+			filename:    "shout.md",
+			wantContent: "# Shout\n",
+		},
+		{
+			name:      "reject non-markdown file",
+			filename:  "notes.txt",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.ReadResourceRequest{
+				Params: mcp.ReadResourceParams{
+					URI: "file://" + tt.filename,
+				},
+			}
+
+			result, err := handleReadMarkdownFileResource(context.Background(), req)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			textResourceContent, ok := result[0].(mcp.TextResourceContents)
+			if !ok {
+				t.Fatalf("Expected TextResourceContents, got %T", result[0])
+			}
+
+			if textResourceContent.Text != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, textResourceContent.Text)
+			}
+		})
+	}
+}
+
+func TestHandleReadMarkdownFileResourceMaxFileSize(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	limit := func(size int64) *int64 { return &size }
+
+	tests := []struct {
+		name        string
+		maxFileSize *int64
+		filename    string
+		wantError   string
+	}{
+		{
+			name:        "file just under the limit",
+			maxFileSize: limit(100),
+			filename:    "under.md",
+		},
+		{
+			name:        "file just over the limit",
+			maxFileSize: limit(100),
+			filename:    "over.md",
+			wantError:   "too large",
+		},
+		{
+			name:        "zero limit is unlimited",
+			maxFileSize: limit(0),
+			filename:    "over.md",
+		},
+		{
+			name:     "default limit",
+			filename: "over.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{Directories: []string{"test/size"}, MaxFileSize: tt.maxFileSize}
+
+			req := mcp.ReadResourceRequest{
+				Params: mcp.ReadResourceParams{
+					URI: "file://" + tt.filename,
+				},
+			}
+
+			result, err := handleReadMarkdownFileResource(context.Background(), req)
+
+			if tt.wantError != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing %q, got %q", tt.wantError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(result) != 1 {
+				t.Errorf("Expected 1 resource content, got %d", len(result))
+			}
+		})
+	}
+}
+
+func TestHandleReadMarkdownFileResourceLineRange(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	limit := func(size int64) *int64 { return &size }
+
+	tests := []struct {
+		name        string
+		directory   string
+		maxFileSize *int64
+		uri         string
+		arguments   map[string]any
+		wantError   bool
+		wantContent string
+	}{
+		{
+			name:        "lines two to three",
+			uri:         "file://numbered.md?start_line=2&end_line=3",
+			wantContent: "line two\nline three\n",
+		},
+		{
+			name:        "lines from template arguments",
+			uri:         "file://numbered.md",
+			arguments:   map[string]any{"filename": "numbered.md", "start_line": "2", "end_line": "3"},
+			wantContent: "line two\nline three\n",
+		},
+		{
+			name:        "start line only reads to the end",
+			uri:         "file://numbered.md?start_line=4",
+			wantContent: "line four\nline five\n",
+		},
+		{
+			name:        "end line only reads from the start",
+			uri:         "file://numbered.md?end_line=1",
+			wantContent: "# Numbered\n",
+		},
+		{
+			name:        "end line past the end is clamped",
+			uri:         "file://numbered.md?start_line=5&end_line=100",
+			wantContent: "line five\n",
+		},
+		{
+			name:        "start line past the end is empty",
+			uri:         "file://numbered.md?start_line=50",
+			wantContent: "",
+		},
+		{
+			name:        "no range reads the whole file",
+			uri:         "file://numbered.md",
+			wantContent: "# Numbered\nline two\nline three\nline four\nline five\n",
+		},
+		{
+			name:      "invalid line number",
+			uri:       "file://numbered.md?start_line=two",
+			wantError: true,
+		},
+		{
+			name:        "last three lines",
+			uri:         "file://numbered.md?tail_lines=3",
+			wantContent: "line three\nline four\nline five\n",
+		},
+		{
+			name:        "tail longer than the file reads the whole file",
+			uri:         "file://numbered.md?tail_lines=100",
+			wantContent: "# Numbered\nline two\nline three\nline four\nline five\n",
+		},
+		{
+			name:      "tail combined with a range",
+			uri:       "file://numbered.md?tail_lines=2&start_line=1",
+			wantError: true,
+		},
+		{
+			name:        "tail of a file over the size limit",
+			directory:   "test/size",
+			maxFileSize: limit(100),
+			uri:         "file://over.md?tail_lines=1",
+			wantContent: strings.Repeat("o", 92) + "\n",
+		},
+		{
+			name:        "range of a file over the size limit",
+			directory:   "test/size",
+			maxFileSize: limit(100),
+			uri:         "file://over.md?end_line=1",
+			wantContent: "# Over\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := tt.directory
+			if directory == "" {
+				directory = "test/lines"
+			}
+			config = Config{Directories: []string{directory}, MaxFileSize: tt.maxFileSize}
+
+			req := mcp.ReadResourceRequest{
+				Params: mcp.ReadResourceParams{
+					URI:       tt.uri,
+					Arguments: tt.arguments,
+				},
+			}
+
+			result, err := handleReadMarkdownFileResource(context.Background(), req)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			textResourceContent, ok := result[0].(mcp.TextResourceContents)
+			if !ok {
+				t.Fatalf("Expected TextResourceContents, got %T", result[0])
+			}
+
+			if textResourceContent.Text != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, textResourceContent.Text)
+			}
+		})
+	}
+}
+
+func TestReadTailLines(t *testing.T) {
+	setupFileIndexTest(t, Config{})
+
+	longLine := strings.Repeat("x", ReadChunkSize) + "\n"
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    string
+	}{
+		{name: "trailing newline", content: "a\nb\nc\n", n: 2, want: "b\nc\n"},
+		{name: "no trailing newline", content: "a\nb\nc", n: 2, want: "b\nc"},
+		{name: "blank last line", content: "a\nb\n\n", n: 2, want: "b\n\n"},
+		{name: "fewer lines than requested", content: "a\nb\n", n: 5, want: "a\nb\n"},
+		{name: "empty file", content: "", n: 3, want: ""},
+		{name: "lines spanning chunks", content: "first\n" + longLine + longLine + "last\n", n: 2, want: longLine + "last\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "log.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			got, err := readTailLines(context.Background(), path, tt.n)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateRequestedFilename(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		wantError string
+	}{
+		{name: "plain filename", filename: "notes.md"},
+		{name: "filename without extension", filename: "notes"},
+		{name: "filename with colon after first character", filename: "meeting: 10:00.md"},
+		{name: "directory traversal", filename: "../secret.md", wantError: "directory traversal"},
+		{name: "unix absolute path", filename: "/etc/passwd", wantError: "absolute paths"},
+		{name: "windows absolute path", filename: `C:\Windows\win.ini`, wantError: "volume names"},
+		{name: "windows drive relative path", filename: "C:foo.md", wantError: "volume names"},
+		{name: "windows lower case drive", filename: "d:notes.md", wantError: "volume names"},
+		{name: "windows UNC path", filename: `\\server\share\notes.md`, wantError: "absolute paths"},
+		{name: "rooted backslash path", filename: `\notes.md`, wantError: "absolute paths"},
+		{name: "relative path suffix", filename: "dir/notes.md"},
+		{name: "nested relative path suffix", filename: "work/dir/notes"},
+		{name: "empty path component", filename: "dir//notes.md", wantError: "not a relative path suffix"},
+		{name: "dot path component", filename: "./notes.md", wantError: "not a relative path suffix"},
+		{name: "trailing slash", filename: "dir/", wantError: "not a relative path suffix"},
+		{name: "traversal inside suffix", filename: "dir/../notes.md", wantError: "directory traversal"},
+		{name: "relative windows path", filename: `dir\notes.md`, wantError: "looks like a path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRequestedFilename(tt.filename)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got none", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestReadMarkdownFileResourceRejectsAbsolutePaths(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	config = Config{
+		Directories: []string{"test/dir1"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+
+	for _, uri := range []string{"file:///etc/passwd", "file://C:foo.md", `file://C:\notes\foo.md`} {
+		req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}}
+		if _, err := handleReadMarkdownFileResource(context.Background(), req); err == nil {
+			t.Errorf("Expected reading %s to fail", uri)
+		}
+	}
+}
+
+func TestReadMarkdownFileResourceHTMLFormat(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	config = Config{
+		Directories: []string{"test/html"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+
+	tests := []struct {
+		name         string
+		uri          string
+		wantError    bool
+		wantMIMEType string
+		wantContains []string
+		wantMissing  []string
+	}{
+		{
+			name:         "markdown by default",
+			uri:          "file://page.md",
+			wantMIMEType: "text/markdown",
+			wantContains: []string{"title: Page", "# Page Title"},
+		},
+		{
+			name:         "explicit markdown",
+			uri:          "file://page.md?format=markdown",
+			wantMIMEType: "text/markdown",
+			wantContains: []string{"# Page Title"},
+		},
+		{
+			name:         "html",
+			uri:          "file://page.md?format=html",
+			wantMIMEType: "text/html",
+			wantContains: []string{"<h1>Page Title</h1>", "<h2>Section</h2>", "<em>emphasis</em>", `<a href="other.md">link</a>`},
+			wantMissing:  []string{"title: Page", "<hr"},
+		},
+		{
+			name:         "plain text",
+			uri:          "file://page.md?format=text",
+			wantMIMEType: "text/plain",
+			wantContains: []string{"Page Title\n\nSection\n\nSome emphasis and a link."},
+			wantMissing:  []string{"title: Page", "#", "*"},
+		},
+		{
+			name:      "unknown format",
+			uri:       "file://page.md?format=pdf",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}}
+			result, err := handleReadMarkdownFileResource(context.Background(), req)
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content := result[0].(mcp.TextResourceContents)
+			if content.MIMEType != tt.wantMIMEType {
+				t.Errorf("Expected MIME type %q, got %q", tt.wantMIMEType, content.MIMEType)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(content.Text, want) {
+					t.Errorf("Expected content to contain %q, got %q", want, content.Text)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(content.Text, missing) {
+					t.Errorf("Expected content not to contain %q, got %q", missing, content.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMarkdownFileResourceCancelled(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.md")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("lorem ipsum\n"), 4*ReadChunkSize), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	config = Config{Directories: []string{dir}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := readFileContentContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the read to abort with context.Canceled, got %v", err)
+	}
+
+	req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "file://large.md"}}
+	if _, err := handleReadMarkdownFileResource(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the resource read to abort with context.Canceled, got %v", err)
+	}
+
+	// The same read completes when not cancelled
+	content, err := readFileContentContext(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(content) != 4*ReadChunkSize*len("lorem ipsum\n") {
+		t.Errorf("Expected the whole file to be read, got %d bytes", len(content))
+	}
+}
+
+func TestReadLinesCancelled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.md")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("lorem ipsum\n"), 4*ReadChunkSize), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	setupFileIndexTest(t, Config{Directories: []string{dir}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	readers := map[string]func(context.Context) ([]byte, error){
+		"line range":  func(ctx context.Context) ([]byte, error) { return readLineRange(ctx, path, 2, 0) },
+		"tail lines":  func(ctx context.Context) ([]byte, error) { return readTailLines(ctx, path, 3) },
+		"tail stream": func(ctx context.Context) ([]byte, error) { return readTailLinesStream(ctx, path, 3) },
+	}
+	for name, read := range readers {
+		t.Run(name, func(t *testing.T) {
+			if _, err := read(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected the read to abort with context.Canceled, got %v", err)
+			}
+			// The same read completes when not cancelled
+			if _, err := read(context.Background()); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	for _, uri := range []string{"file://large.md?start_line=2", "file://large.md?tail_lines=3"} {
+		req := mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: uri}}
+		if _, err := handleReadMarkdownFileResource(ctx, req); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the resource read of %s to abort with context.Canceled, got %v", uri, err)
+		}
+	}
+}
+
+func TestReadMarkdownFileResourceErrorCodes(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	limit := int64(100)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		config   Config
+		ctx      context.Context
+		uri      string
+		wantCode string
+	}{
+		{
+			name:     "missing filename",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file://",
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "file not found",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file://missing.md",
+			wantCode: ErrorCodeFileNotFound,
+		},
+		{
+			name:     "directory traversal",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file://../README.md",
+			wantCode: ErrorCodeTraversalBlocked,
+		},
+		{
+			name:     "absolute path",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file:///etc/passwd.md",
+			wantCode: ErrorCodeTraversalBlocked,
+		},
+		{
+			name:     "backslash path",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      `file://sub\file.md`,
+			wantCode: ErrorCodeTraversalBlocked,
+		},
+		{
+			name:     "file over the size limit",
+			config:   Config{Directories: []string{"test/size"}, MaxFileSize: &limit},
+			uri:      "file://over.md",
+			wantCode: ErrorCodeTooLarge,
+		},
+		{
+			name:     "ambiguous filename",
+			config:   Config{Directories: []string{"test/collisions"}, AmbiguousRead: AmbiguousReadError},
+			uri:      "file://index.md",
+			wantCode: ErrorCodeAmbiguous,
+		},
+		{
+			name:     "invalid line parameter",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file://README.md?start_line=abc",
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "invalid format parameter",
+			config:   Config{Directories: []string{"test/dir1"}},
+			uri:      "file://README.md?format=pdf",
+			wantCode: ErrorCodeInvalidArgument,
+		},
+		{
+			name:     "cancelled request",
+			config:   Config{Directories: []string{"test/dir1"}},
+			ctx:      cancelled,
+			uri:      "file://README.md",
+			wantCode: ErrorCodeCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = tt.config
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			_, err := handleReadMarkdownFileResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}})
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+
+			var payload errorPayload
+			if err := json.Unmarshal([]byte(err.Error()), &payload); err != nil {
+				t.Fatalf("Expected a JSON error payload, got %q: %v", err.Error(), err)
+			}
+			if payload.Error.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s: %s", tt.wantCode, payload.Error.Code, payload.Error.Message)
+			}
+			if payload.Error.Message == "" {
+				t.Error("Expected a human readable message")
+			}
+		})
+	}
+}
+
+func TestHandleReadMarkdownFileResourceDirectoryLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		directories []string
+		uri         string
+		wantCode    string
+		wantContent string
+	}{
+		{
+			name:        "first directory by label",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://a/notes.md",
+			wantContent: "# Notes A\n",
+		},
+		{
+			name:        "later directory by label",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://b/notes",
+			wantContent: "# Notes B\n",
+		},
+		{
+			name:        "label with options",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://b/notes.md?format=text",
+			wantContent: "Notes B\n",
+		},
+		{
+			name:        "no label searches all directories",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://notes.md",
+			wantContent: "# Notes A\n",
+		},
+		{
+			name:        "file only searched in the labelled directory",
+			directories: []string{"test/dir1", "test/dir2"},
+			uri:         "markdown://dir2/foo.md",
+			wantCode:    ErrorCodeFileNotFound,
+		},
+		{
+			name:        "unknown label",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://c/notes.md",
+			wantCode:    ErrorCodeInvalidArgument,
+		},
+		{
+			name:        "label shared by directories",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b", "test/links/a"},
+			uri:         "markdown://a/notes.md",
+			wantCode:    ErrorCodeInvalidArgument,
+		},
+		{
+			name:        "traversal after label",
+			directories: []string{"test/ambiguous/a", "test/ambiguous/b"},
+			uri:         "markdown://a/../b/notes.md",
+			wantCode:    ErrorCodeTraversalBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{Directories: tt.directories})
+
+			result, err := handleReadMarkdownFileResource(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: tt.uri}})
+
+			if tt.wantCode != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.wantCode) {
+					t.Errorf("Expected error code %s, got %v", tt.wantCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if text := result[0].(mcp.TextResourceContents).Text; text != tt.wantContent {
+				t.Errorf("Expected content %q, got %q", tt.wantContent, text)
+			}
+		})
+	}
+}
+
+func TestReadResourceWithConfiguredScheme(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/ambiguous/a", "test/ambiguous/b"}, ResourceScheme: "notes"})
+
+	s := newServer()
+	read := func(uri string) map[string]any {
+		t.Helper()
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+		response, err := json.Marshal(s.HandleMessage(context.Background(), []byte(message)))
+		if err != nil {
+			t.Fatalf("Failed to marshal response: %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(response, &decoded); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return decoded
+	}
+
+	response := read("notes://b/notes.md")
+	if !strings.Contains(fmt.Sprint(response["result"]), "# Notes B") {
+		t.Errorf("Expected the file read through the configured scheme, got %v", response)
+	}
+
+	// The default scheme is no longer served
+	if response := read("markdown://b/notes.md"); response["error"] == nil {
+		t.Errorf("Expected an error for the default scheme, got %v", response)
+	}
+}
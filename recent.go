@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultRecentDays is how far back recent_files looks when no days are requested
+const DefaultRecentDays = 7
+
+func handleRecentFiles(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	days, err := extractDaysParam(req.Params.Arguments)
+	if err != nil {
+		return toolErrorResult(err), nil
+	}
+
+	logger.Debug("recent_files called", "days", days)
+
+	maxResults := config.MaxPageSize
+	if maxResults <= 0 {
+		maxResults = DefaultMaxPageSize
+	}
+
+	since := time.Now().Add(-time.Duration(days * float64(24*time.Hour)))
+	files, truncated := recentFiles(since, maxResults)
+
+	fileInfos := make([]map[string]any, 0, len(files))
+	for _, file := range files {
+		fileInfos = append(fileInfos, map[string]any{
+			"name":      markdownName(file.Path),
+			"directory": config.configuredDirectoryName(file.Dir),
+			"path":      relativeToDirectory(file.Dir, file.Path),
+			"modified":  file.ModTime.Format(time.RFC3339),
+		})
+	}
+
+	result := map[string]any{
+		"files":     fileInfos,
+		"count":     len(fileInfos),
+		"truncated": truncated,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("recent_files failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal file list: %w", err)), nil
+	}
+
+	logger.Debug("recent_files completed successfully", "files_found", len(fileInfos), "truncated", truncated)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// recentFiles returns up to maxResults markdown files modified since the given
+// time, newest first, and whether more were modified. Modification times are
+// those read from the directory entries when the index was built.
+func recentFiles(since time.Time, maxResults int) ([]indexedFile, bool) {
+	var recent []indexedFile
+	for _, file := range markdownIndex.entries() {
+		if !file.ModTime.Before(since) {
+			recent = append(recent, file)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].ModTime.After(recent[j].ModTime)
+	})
+
+	if len(recent) > maxResults {
+		return recent[:maxResults], true
+	}
+	return recent, false
+}
+
+// extractDaysParam returns the days parameter, DefaultRecentDays when absent.
+// Fractions of a day are allowed, e.g. 0.5 for the last 12 hours.
+func extractDaysParam(arguments any) (float64, error) {
+	argsMap, ok := arguments.(map[string]any)
+	if !ok {
+		return DefaultRecentDays, nil
+	}
+
+	var days float64
+	switch value := argsMap["days"].(type) {
+	case nil:
+		return DefaultRecentDays, nil
+	case float64:
+		days = value
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid days %q: must be a positive number", value)
+		}
+		days = parsed
+	default:
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid days %v: must be a positive number", value)
+	}
+
+	if days <= 0 {
+		return 0, errorWithCode(ErrorCodeInvalidArgument, "invalid days %v: must be a positive number", days)
+	}
+	return days, nil
+}
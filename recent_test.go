@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleRecentFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"today.md":      time.Hour,
+		"yesterday.md":  25 * time.Hour,
+		"last-week.md":  6 * 24 * time.Hour,
+		"last-month.md": 30 * 24 * time.Hour,
+		"ignored/x.md":  time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# Note"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		modTime := now.Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set file time: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		maxPageSize   int
+		wantFiles     []string
+		wantTruncated bool
+		wantError     bool
+	}{
+		{
+			name:      "default of 7 days",
+			wantFiles: []string{"today.md", "yesterday.md", "last-week.md"},
+		},
+		{
+			name:      "one day",
+			args:      map[string]any{"days": float64(1)},
+			wantFiles: []string{"today.md"},
+		},
+		{
+			name:      "days as a string",
+			args:      map[string]any{"days": "2"},
+			wantFiles: []string{"today.md", "yesterday.md"},
+		},
+		{
+			name:          "capped at max_page_size",
+			maxPageSize:   2,
+			wantFiles:     []string{"today.md", "yesterday.md"},
+			wantTruncated: true,
+		},
+		{
+			name:      "zero days",
+			args:      map[string]any{"days": float64(0)},
+			wantError: true,
+		},
+		{
+			name:      "days not a number",
+			args:      map[string]any{"days": "week"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, Config{
+				Directories: []string{dir},
+				IgnoreDirs:  []string{`ignored$`},
+				Extensions:  DefaultExtensions,
+				MaxPageSize: tt.maxPageSize,
+			})
+
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			result, err := handleRecentFiles(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Fatalf("Expected IsError %v, got %v: %v", tt.wantError, result.IsError, result.Content)
+			}
+			if tt.wantError {
+				return
+			}
+
+			var response struct {
+				Files []struct {
+					Name     string `json:"name"`
+					Modified string `json:"modified"`
+				} `json:"files"`
+				Truncated bool `json:"truncated"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			var names []string
+			for _, file := range response.Files {
+				names = append(names, file.Name)
+				if _, err := time.Parse(time.RFC3339, file.Modified); err != nil {
+					t.Errorf("Expected an RFC 3339 modified time for %s, got %q", file.Name, file.Modified)
+				}
+			}
+			if len(names) != len(tt.wantFiles) {
+				t.Fatalf("Expected files %v newest first, got %v", tt.wantFiles, names)
+			}
+			for i := range names {
+				if names[i] != tt.wantFiles[i] {
+					t.Fatalf("Expected files %v newest first, got %v", tt.wantFiles, names)
+				}
+			}
+			if response.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, response.Truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Formats the markdown resource can be read in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text"
+)
+
+// resourceFormat returns the format requested for a resource read, markdown when
+// absent
+func resourceFormat(value string) (string, error) {
+	switch value {
+	case "", FormatMarkdown:
+		return FormatMarkdown, nil
+	case FormatHTML, FormatText:
+		return value, nil
+	default:
+		return "", errorWithCode(ErrorCodeInvalidArgument, "invalid format parameter %q: must be %q, %q or %q", value, FormatMarkdown, FormatHTML, FormatText)
+	}
+}
+
+// renderHTML converts a markdown document to HTML. Frontmatter is metadata rather
+// than content, so it is stripped before conversion.
+func renderHTML(content string) (string, error) {
+	_, body, _ := splitFrontmatter(content)
+
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(body), &buf); err != nil {
+		return "", fmt.Errorf("failed to render markdown as HTML: %v", err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderPlainText strips the markdown formatting of a document, leaving its prose
+// for uses such as embeddings. Heading markers, emphasis, link targets, list
+// markers and code fence lines are dropped while the text of links and the
+// contents of code blocks are kept. Runs of blank lines are collapsed to one.
+func renderPlainText(content string) string {
+	_, body, _ := splitFrontmatter(content)
+	source := []byte(body)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	var buf strings.Builder
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			switch n.Kind() {
+			case ast.KindTextBlock, ast.KindListItem:
+				if !strings.HasSuffix(buf.String(), "\n") {
+					buf.WriteString("\n")
+				}
+			case ast.KindParagraph, ast.KindHeading, ast.KindList, ast.KindBlockquote:
+				buf.WriteString("\n\n")
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			buf.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				buf.WriteString("\n")
+			}
+		case *ast.String:
+			buf.Write(node.Value)
+		case *ast.AutoLink:
+			buf.Write(node.Label(source))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				buf.Write(line.Value(source))
+			}
+			buf.WriteString("\n\n")
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return collapseBlankLines(buf.String())
+}
+
+// collapseBlankLines trims trailing whitespace from every line, collapses runs of
+// blank lines to a single one and drops leading and trailing blank lines
+func collapseBlankLines(content string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRenderPlainText(t *testing.T) {
+	content, err := os.ReadFile("test/plaintext/rich.md")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	want, err := os.ReadFile("test/plaintext/rich.txt")
+	if err != nil {
+		t.Fatalf("Failed to read expected plain text: %v", err)
+	}
+
+	if got := renderPlainText(string(content)); got != string(want) {
+		t.Errorf("Unexpected plain text.\nExpected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestCollapseBlankLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "empty", content: "", want: ""},
+		{name: "only blank lines", content: "\n \n\t\n", want: ""},
+		{name: "leading and trailing blank lines", content: "\n\nline\n\n", want: "line\n"},
+		{name: "runs of blank lines", content: "one\n\n\n\ntwo\n \nthree", want: "one\n\ntwo\n\nthree\n"},
+		{name: "trailing whitespace", content: "one  \ntwo\t\n", want: "one\ntwo\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collapseBlankLines(tt.content); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
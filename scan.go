@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// runScan walks the configured directories once and writes a summary of the
+// markdown files found in each, for checking a config before serving it. It
+// returns the exit code, 1 when no files were found at all.
+func runScan(w io.Writer) int {
+	fmt.Fprintln(w, "Markdown files found in each directory:")
+	for _, dir := range config.Directories {
+		if problem := directoryProblem(dir); problem != "" {
+			fmt.Fprintf(w, "  %s: %s\n", dir, problem)
+			continue
+		}
+		count := 0
+		status := walkMarkdownFilesContext(context.Background(), &config, dir, func(path string, d fs.DirEntry) error {
+			count++
+			return nil
+		})
+		fmt.Fprintf(w, "  %s: %d markdown files\n", dir, count)
+		for _, warning := range status.Warnings {
+			fmt.Fprintf(w, "    %s\n", warning)
+		}
+	}
+
+	// Files reachable through overlapping directories are only counted once
+	total := len(walkAllMarkdownEntries())
+	fmt.Fprintf(w, "Total: %d markdown files\n", total)
+
+	if total == 0 {
+		fmt.Fprintln(w, "No markdown files found, check the directories and ignore patterns")
+		return 1
+	}
+	return 0
+}
+
+// directoryProblem describes why a configured directory can't be scanned, empty
+// when it can
+func directoryProblem(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Sprintf("could not resolve path: %v", err)
+	}
+
+	info, err := os.Stat(absDir)
+	switch {
+	case os.IsNotExist(err):
+		return "does not exist"
+	case err != nil:
+		return fmt.Sprintf("could not read: %v", err)
+	case !info.IsDir():
+		return "is not a directory"
+	}
+
+	f, err := os.Open(absDir)
+	if err == nil {
+		_, err = f.ReadDir(1)
+		f.Close()
+	}
+	if err != nil && err != io.EOF {
+		return fmt.Sprintf("is not readable: %v", err)
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunScan(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name        string
+		directories []string
+		wantCode    int
+		wantOutput  []string
+	}{
+		{
+			name:        "files found",
+			directories: []string{"test/dir1"},
+			wantCode:    0,
+			wantOutput:  []string{"test/dir1: 4 markdown files", "Total: 4 markdown files"},
+		},
+		{
+			name:        "missing directory is reported",
+			directories: []string{"test/dir1", "test/nonexistent"},
+			wantCode:    0,
+			wantOutput:  []string{"test/dir1: 4 markdown files", "test/nonexistent: does not exist", "Total: 4 markdown files"},
+		},
+		{
+			name:        "no files found",
+			directories: []string{"test/nonexistent"},
+			wantCode:    1,
+			wantOutput:  []string{"Total: 0 markdown files", "No markdown files found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{Directories: tt.directories, IgnoreDirs: []string{`\.git$`, `node_modules$`}}
+
+			var out bytes.Buffer
+			if code := runScan(&out); code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
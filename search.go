@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxSearchMatchesPerFile caps the matches reported for a single file by search_markdown
+const MaxSearchMatchesPerFile = 5
+
+// SearchContextLines is the number of lines shown before and after a matching line
+const SearchContextLines = 1
+
+type searchMatch struct {
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"`
+}
+
+type searchResult struct {
+	Name    string        `json:"name"`
+	Matches []searchMatch `json:"matches"`
+}
+
+func handleSearchMarkdown(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := extractQueryParam(req.Params.Arguments)
+	caseSensitive := extractBoolParam(req.Params.Arguments, "case_sensitive")
+
+	log := requestLogger()
+	log.Debug("search_markdown called", "query", query, "case_sensitive", caseSensitive)
+
+	if strings.TrimSpace(query) == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: query")), nil
+	}
+
+	maxMatches := config.MaxPageSize
+	if maxMatches <= 0 {
+		maxMatches = DefaultMaxPageSize
+	}
+
+	results, total, truncated := searchMarkdown(query, caseSensitive, maxMatches)
+
+	result := map[string]any{
+		"files":         results,
+		"count":         len(results),
+		"total_matches": total,
+		"truncated":     truncated,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("search_markdown failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal results: %w", err)), nil
+	}
+
+	log.Debug("search_markdown completed successfully", "files_found", len(results), "matches", total)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// searchMarkdown finds the lines of every markdown file containing the query,
+// case-insensitively unless caseSensitive is set. At most MaxSearchMatchesPerFile
+// matches are reported per file and maxMatches overall, truncated reporting
+// whether matches were left out.
+func searchMarkdown(query string, caseSensitive bool, maxMatches int) (results []searchResult, total int, truncated bool) {
+	expr := regexp.QuoteMeta(query)
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern := regexp.MustCompile(expr)
+
+	results = []searchResult{}
+	for _, file := range collectAllMarkdownFiles() {
+		content, err := fileContent(file)
+		if err != nil {
+			logger.Debug("search_markdown could not read file", "file", file, "error", err)
+			continue
+		}
+
+		lines := strings.Split(content, "\n")
+		var matches []searchMatch
+		for i, line := range lines {
+			if !pattern.MatchString(line) {
+				continue
+			}
+			if len(matches) == MaxSearchMatchesPerFile || total == maxMatches {
+				truncated = true
+				break
+			}
+			matches = append(matches, searchMatch{
+				Line:    i + 1,
+				Snippet: searchSnippet(lines, i, pattern),
+			})
+			total++
+		}
+
+		if len(matches) > 0 {
+			results = append(results, searchResult{Name: markdownName(file), Matches: matches})
+		}
+		if truncated && total == maxMatches {
+			break
+		}
+	}
+
+	return results, total, truncated
+}
+
+// searchSnippet returns the matching line with SearchContextLines lines either
+// side, the matched text in the matching line marked with **...**
+func searchSnippet(lines []string, index int, pattern *regexp.Regexp) string {
+	start := max(index-SearchContextLines, 0)
+	end := min(index+SearchContextLines+1, len(lines))
+
+	snippet := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if i == index {
+			line = pattern.ReplaceAllString(line, "**$0**")
+		}
+		snippet = append(snippet, line)
+	}
+
+	return strings.Join(snippet, "\n")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSearchMarkdown(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/search"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	results, total, truncated := searchMarkdown("tomatoes", false, DefaultMaxPageSize)
+	if len(results) != 1 || results[0].Name != "garden.md" {
+		t.Fatalf("Expected matches only in garden.md, got %+v", results)
+	}
+	if total != 3 || truncated {
+		t.Errorf("Expected 3 matches and no truncation, got %d and %v", total, truncated)
+	}
+
+	want := []searchMatch{
+		{Line: 3, Snippet: "\n**Tomatoes** need sun.\nWater the tomatoes daily."},
+		{Line: 4, Snippet: "Tomatoes need sun.\nWater the **tomatoes** daily.\nPotatoes are fine."},
+		{Line: 7, Snippet: "\nLast line about **TOMATOES**\n"},
+	}
+	for i, match := range results[0].Matches {
+		if match != want[i] {
+			t.Errorf("Match %d: expected %+v, got %+v", i, want[i], match)
+		}
+	}
+}
+
+func TestSearchMarkdownLimits(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/search"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name          string
+		maxMatches    int
+		wantTotal     int
+		wantTruncated bool
+	}{
+		{name: "per file cap", maxMatches: 100, wantTotal: 3 + MaxSearchMatchesPerFile, wantTruncated: true},
+		{name: "overall cap", maxMatches: 2, wantTotal: 2, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, total, truncated := searchMarkdown("tomato", false, tt.maxMatches)
+			if total != tt.wantTotal {
+				t.Errorf("Expected %d matches, got %d", tt.wantTotal, total)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, truncated)
+			}
+		})
+	}
+}
+
+func TestHandleSearchMarkdown(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/search"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"query": "potatoes"}
+	result, err := handleSearchMarkdown(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	var response struct {
+		Files []searchResult `json:"files"`
+		Count int            `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Count != 1 || response.Files[0].Matches[0].Line != 5 {
+		t.Errorf("Expected one match on line 5, got %+v", response)
+	}
+
+	req.Params.Arguments = map[string]any{}
+	result, err = handleSearchMarkdown(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected an error for a missing query")
+	}
+}
+
+func TestSearchMarkdownCaseSensitive(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/search"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	results, total, _ := searchMarkdown("TOMATOES", true, DefaultMaxPageSize)
+	if total != 1 || results[0].Matches[0].Line != 7 {
+		t.Errorf("Expected a single match on line 7, got %+v", results)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func handleServerInfo(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("server_info called")
+
+	result := map[string]any{
+		"name":    ServerName,
+		"version": ServerVersion,
+		"config":  effectiveConfig(),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("server_info failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal server info: %w", err)), nil
+	}
+
+	logger.Debug("server_info completed successfully")
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// effectiveConfig returns the config the server is running with, including
+// unset options, with the command line overrides applied and paths under the
+// home directory redacted
+func effectiveConfig() map[string]any {
+	cfg := config
+	cfg.Directories = make([]string, len(config.Directories))
+	for i, dir := range config.Directories {
+		cfg.Directories[i] = redactHomePath(dir)
+	}
+	cfg.LogFile = redactHomePath(config.LogFile)
+	cfg.AllowFilesPaths = make([]string, len(config.AllowFilesPaths))
+	for i, path := range config.AllowFilesPaths {
+		cfg.AllowFilesPaths[i] = redactHomePath(path)
+	}
+
+	// Options are named as in the config file
+	effective := map[string]any{}
+	value := reflect.ValueOf(cfg)
+	for i := range value.NumField() {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := value.Field(i)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				effective[name] = nil
+				continue
+			}
+			field = field.Elem()
+		}
+		effective[name] = field.Interface()
+	}
+
+	effective["default_page_size"] = defaultPageSize()
+	effective["max_file_size"] = maxFileSize()
+	effective["compress_responses"] = compressResponses()
+	effective["search_concurrency"] = searchConcurrency()
+	if config.AuthToken != "" {
+		effective["auth_token"] = "<redacted>"
+	}
+	effective["extensions"] = config.markdownExtensions()
+	effective["transport"] = resolveTransport()
+	effective["debug_logging"] = debugLoggingEnabled()
+	effective["log_destination"] = logDestination(cfg.LogFile)
+	if len(config.DirectoryIgnoreDirs) > 0 {
+		perDirectory := make(map[string][]string, len(config.DirectoryIgnoreDirs))
+		for dir, patterns := range config.DirectoryIgnoreDirs {
+			perDirectory[redactHomePath(dir)] = patterns
+		}
+		effective["directory_ignore_dirs"] = perDirectory
+	}
+
+	return effective
+}
+
+// logDestination describes where logs are written, the -stdout flag taking
+// precedence over the log file
+func logDestination(logFile string) string {
+	switch {
+	case *stdoutFlag:
+		return "stdout"
+	case logFile != "":
+		return logFile
+	default:
+		return "stderr"
+	}
+}
+
+// redactHomePath replaces the home directory at the start of an absolute path
+// with ~ so reports don't reveal the user name
+func redactHomePath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+
+	if path == homeDir {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, homeDir+string(filepath.Separator)); ok {
+		return "~/" + filepath.ToSlash(rest)
+	}
+	return path
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleServerInfo(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	config = Config{
+		Directories: []string{"test/dir1", filepath.Join(homeDir, "notes")},
+		MaxPageSize: 100,
+		IgnoreDirs:  []string{`\.git$`},
+		LogFile:     filepath.Join(homeDir, "logs", "server.log"),
+		Transport:   TransportHTTP,
+		AuthToken:   "s3cret",
+		AllowFilesPaths: []string{
+			filepath.Join(homeDir, ".config", "app", "README.md"),
+			"/srv/docs/CHANGELOG.md",
+		},
+	}
+
+	result, err := handleServerInfo(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	var response struct {
+		Name    string         `json:"name"`
+		Version string         `json:"version"`
+		Config  map[string]any `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response.Name != ServerName || response.Version != ServerVersion {
+		t.Errorf("Expected server %s %s, got %s %s", ServerName, ServerVersion, response.Name, response.Version)
+	}
+
+	wantDirectories := []any{"test/dir1", "~/notes"}
+	if !reflect.DeepEqual(response.Config["directories"], wantDirectories) {
+		t.Errorf("Expected directories %v, got %v", wantDirectories, response.Config["directories"])
+	}
+
+	wantFields := map[string]any{
+		"max_page_size":      float64(100),
+		"default_page_size":  float64(DefaultPageSize),
+		"transport":          TransportHTTP,
+		"log_file":           "~/logs/server.log",
+		"log_destination":    "~/logs/server.log",
+		"debug_logging":      false,
+		"follow_symlinks":    false,
+		"ignore_dirs":        []any{`\.git$`},
+		"auth_token":         "<redacted>",
+		"search_concurrency": float64(DefaultSearchConcurrency),
+		"allow_files_paths":  []any{"~/.config/app/README.md", "/srv/docs/CHANGELOG.md"},
+	}
+	for name, want := range wantFields {
+		if got := response.Config[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s to be %v, got %v", name, want, got)
+		}
+	}
+}
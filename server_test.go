@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStreamableHTTPInitialize(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/dir1"}, MaxPageSize: DefaultMaxPageSize}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	ts := httptest.NewServer(newHTTPHandler(TransportHTTP, newServer()))
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`
+	req, err := http.NewRequest(http.MethodPost, ts.URL+HTTPEndpointPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send initialize: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Result struct {
+			ServerInfo struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode initialize response: %v", err)
+	}
+
+	if response.Result.ServerInfo.Name != "Markdown Reader" {
+		t.Errorf("Expected server name 'Markdown Reader', got %q", response.Result.ServerInfo.Name)
+	}
+	if resp.Header.Get("Mcp-Session-Id") == "" {
+		t.Error("Expected a session ID header in the initialize response")
+	}
+}
+
+func TestResolveTransport(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{name: "default is stdio", want: TransportStdio},
+		{name: "sse_mode alias", cfg: Config{SSEMode: true}, want: TransportSSE},
+		{name: "http transport", cfg: Config{Transport: TransportHTTP}, want: TransportHTTP},
+		{name: "transport wins over sse_mode", cfg: Config{Transport: TransportHTTP, SSEMode: true}, want: TransportHTTP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = tt.cfg
+			if got := resolveTransport(); got != tt.want {
+				t.Errorf("Expected transport %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBindAddress(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	t.Setenv("PORT", "")
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{name: "all interfaces by default", cfg: Config{SSEPort: 9090}, want: ":9090"},
+		{name: "loopback host", cfg: Config{SSEHost: "127.0.0.1", SSEPort: 9090}, want: "127.0.0.1:9090"},
+		{name: "host name with default port", cfg: Config{SSEHost: "localhost"}, want: "localhost:8080"},
+		{name: "IPv6 host", cfg: Config{SSEHost: "::1", SSEPort: 9090}, want: "[::1]:9090"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = tt.cfg
+			if got := bindAddress(); got != tt.want {
+				t.Errorf("Expected bind address %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateConfigSSEHost(t *testing.T) {
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() { logger = oldLogger }()
+
+	for _, host := range []string{"127.0.0.1", "::1", "localhost", "notes.internal"} {
+		if err := validateConfig(&Config{SSEHost: host}); err != nil {
+			t.Errorf("Expected host %q to be valid, got %v", host, err)
+		}
+	}
+
+	for _, host := range []string{"127.0.0.1:8080", "http://localhost", "bad host"} {
+		if err := validateConfig(&Config{SSEHost: host}); err == nil {
+			t.Errorf("Expected host %q to be rejected", host)
+		}
+	}
+}
+
+func TestHealthzEndpoint(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+		markdownIndex.invalidate()
+	}()
+
+	for _, transport := range []string{TransportSSE, TransportHTTP} {
+		t.Run(transport, func(t *testing.T) {
+			config = Config{Directories: []string{"test/dir1", "test/dir2"}, MaxPageSize: DefaultMaxPageSize, Extensions: DefaultExtensions}
+			indexed := markdownIndex.rebuild()
+
+			ts := httptest.NewServer(newHTTPHandler(transport, newServer()))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + HealthPath)
+			if err != nil {
+				t.Fatalf("Failed to get %s: %v", HealthPath, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Expected JSON content type, got %q", contentType)
+			}
+
+			var status map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				t.Fatalf("Failed to decode health response: %v", err)
+			}
+
+			if status["status"] != "ok" {
+				t.Errorf("Expected status ok, got %v", status["status"])
+			}
+			if _, ok := status["uptime_seconds"].(float64); !ok {
+				t.Errorf("Expected numeric uptime_seconds, got %v", status["uptime_seconds"])
+			}
+			if status["directories"] != float64(2) {
+				t.Errorf("Expected 2 directories, got %v", status["directories"])
+			}
+			if status["indexed_files"] != float64(indexed) {
+				t.Errorf("Expected %d indexed files, got %v", indexed, status["indexed_files"])
+			}
+		})
+	}
+}
+
+func TestHealthzWithoutIndex(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	config = Config{Directories: []string{"test/dir1"}, IndexTTLSeconds: -1}
+
+	recorder := httptest.NewRecorder()
+	handleHealthz(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+
+	var status map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode health response: %v", err)
+	}
+	if _, ok := status["indexed_files"]; ok {
+		t.Errorf("Expected no indexed_files with the index disabled, got %v", status["indexed_files"])
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	config = Config{Directories: []string{"test/dir1"}, IndexTTLSeconds: -1, AuthToken: "s3cret"}
+
+	ts := httptest.NewServer(newHTTPHandler(TransportHTTP, newServer()))
+	defer ts.Close()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "missing token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer guess", wantStatus: http.StatusUnauthorized},
+		{name: "token prefix", authorization: "Bearer s3cre", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", authorization: "Basic s3cret", wantStatus: http.StatusUnauthorized},
+		{name: "correct token", authorization: "Bearer s3cret", wantStatus: http.StatusOK},
+		{name: "scheme ignores case", authorization: "bearer s3cret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+HealthPath, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Bearer") {
+				t.Errorf("Expected a Bearer challenge, got %q", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("disabled without a token", func(t *testing.T) {
+		config.AuthToken = ""
+		open := httptest.NewServer(newHTTPHandler(TransportHTTP, newServer()))
+		defer open.Close()
+
+		resp, err := http.Get(open.URL + HealthPath)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", HealthPath, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200 without auth configured, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type slide struct {
+	Index   int    `json:"index"`
+	Content string `json:"content"`
+}
+
+func handleGetSlides(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("get_slides called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("get_slides failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	slides := splitSlides(content)
+
+	result := map[string]any{
+		"slides": slides,
+		"count":  len(slides),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("get_slides failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal slides: %w", err)), nil
+	}
+
+	logger.Debug("get_slides completed successfully", "slides_found", len(slides))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// splitSlides splits the body of a document on standalone "---" lines, as used
+// by reveal.js and Marp. Leading frontmatter is not treated as a slide and
+// separators inside fenced code blocks are ignored. Blank slides are dropped.
+func splitSlides(content string) []slide {
+	_, body, _ := splitFrontmatter(content)
+
+	slides := []slide{}
+	var current []string
+	inFence := false
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(current, "\n"))
+		if text != "" {
+			slides = append(slides, slide{Index: len(slides) + 1, Content: text})
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+		}
+
+		if !inFence && strings.TrimSpace(line) == "---" {
+			flush()
+			continue
+		}
+
+		current = append(current, line)
+	}
+	flush()
+
+	return slides
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleGetSlides(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/slides"}}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_slides",
+			Arguments: map[string]any{"filename": "deck.md"},
+		},
+	}
+
+	result, err := handleGetSlides(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool returned error: %v", result.Content)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected TextContent, got %T", result.Content[0])
+	}
+
+	var response struct {
+		Slides []slide `json:"slides"`
+		Count  int     `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	want := []slide{
+		{Index: 1, Content: "# Quarterly Review\n\nWelcome"},
+		{Index: 2, Content: "## Results\n\n```yaml\n---\nrevenue: up\n```"},
+		{Index: 3, Content: "## Next Steps"},
+	}
+
+	if response.Count != len(want) {
+		t.Fatalf("Expected count %d, got %d", len(want), response.Count)
+	}
+
+	for i, s := range want {
+		if response.Slides[i] != s {
+			t.Errorf("Slide %d: expected %+v, got %+v", i, s, response.Slides[i])
+		}
+	}
+}
+
+func TestSplitSlidesWithoutFrontmatter(t *testing.T) {
+	slides := splitSlides("# One\n---\n# Two\n")
+	if len(slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d", len(slides))
+	}
+	if slides[0].Content != "# One" || slides[1].Content != "# Two" {
+		t.Errorf("Unexpected slides: %+v", slides)
+	}
+}
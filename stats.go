@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// listMarkerPattern matches list bullets, numbered list markers and block quotes
+var listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)]|>)\s+`)
+
+// WordsPerMinute is the reading speed used to estimate reading time
+const WordsPerMinute = 200
+
+type fileStats struct {
+	Name           string `json:"name"`
+	WordCount      int    `json:"word_count"`
+	LineCount      int    `json:"line_count"`
+	CharCount      int    `json:"char_count"`
+	ReadingMinutes int    `json:"reading_minutes"`
+}
+
+type linkDensity struct {
+	Name    string  `json:"name"`
+	Links   int     `json:"links"`
+	Words   int     `json:"words"`
+	Density float64 `json:"density"`
+}
+
+func handleLinkDensity(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := extractQueryParam(req.Params.Arguments)
+	pageSize := extractPageSizeParam(req.Params.Arguments)
+
+	logger.Debug("link_density called", "query", query, "page_size", pageSize)
+
+	if pageSize <= 0 || pageSize > config.MaxPageSize {
+		pageSize = defaultPageSize()
+	}
+
+	queryLower := strings.ToLower(query)
+	notes := []linkDensity{}
+	for _, file := range collectAllMarkdownFiles() {
+		if query != "" && !strings.Contains(strings.ToLower(markdownName(file)), queryLower) {
+			continue
+		}
+
+		content, err := readFileContent(file)
+		if err != nil {
+			logger.Debug("link_density could not read file", "file", file, "error", err)
+			continue
+		}
+
+		notes = append(notes, measureLinkDensity(markdownName(file), string(content)))
+	}
+
+	// Highest density first, ties broken by name for a stable order
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].Density != notes[j].Density {
+			return notes[i].Density > notes[j].Density
+		}
+		return notes[i].Name < notes[j].Name
+	})
+
+	if len(notes) > pageSize {
+		notes = notes[:pageSize]
+	}
+
+	result := map[string]any{
+		"notes": notes,
+		"count": len(notes),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("link_density failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal link density: %w", err)), nil
+	}
+
+	logger.Debug("link_density completed successfully", "notes", len(notes))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func handleFileStats(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("file_stats called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	targetFile, err := resolveMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("file_stats failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	content, err := readFileContent(targetFile)
+	if err != nil {
+		logger.Debug("file_stats failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	stats := measureFileStats(filepath.Base(targetFile), string(content))
+
+	jsonData, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		logger.Debug("file_stats failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal file stats: %w", err)), nil
+	}
+
+	logger.Debug("file_stats completed successfully", "words", stats.WordCount)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// measureFileStats counts the words, lines and characters of a note and estimates
+// its reading time, rounded up to whole minutes
+func measureFileStats(name, content string) fileStats {
+	lines := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		lines++
+	}
+
+	words := countWords(content)
+
+	return fileStats{
+		Name:           name,
+		WordCount:      words,
+		LineCount:      lines,
+		CharCount:      utf8.RuneCountInString(content),
+		ReadingMinutes: (words + WordsPerMinute - 1) / WordsPerMinute,
+	}
+}
+
+// measureLinkDensity counts the links (excluding images) and words of a note.
+// The density is the ratio of links to words.
+func measureLinkDensity(name, content string) linkDensity {
+	links := len(extractWikilinks(content))
+	for _, link := range extractLinks(content) {
+		if !link.Image {
+			links++
+		}
+	}
+
+	words := countWords(content)
+
+	density := 0.0
+	if words > 0 {
+		density = float64(links) / float64(words)
+	}
+
+	return linkDensity{Name: name, Links: links, Words: words, Density: density}
+}
+
+// countWords counts the words in markdown content. Markdown syntax is stripped
+// lightly so the count feels natural: frontmatter, heading markers, list bullets,
+// link targets and code fence lines are not counted as words.
+func countWords(content string) int {
+	_, body, _ := splitFrontmatter(content)
+
+	words := 0
+	for _, line := range strings.Split(body, "\n") {
+		if isFenceDelimiter(line) {
+			continue
+		}
+		words += len(strings.Fields(stripMarkdownSyntax(line)))
+	}
+
+	return words
+}
+
+// stripMarkdownSyntax removes the heading marker and list bullet of a line and
+// replaces links with their text
+func stripMarkdownSyntax(line string) string {
+	if h, ok := parseHeadingLine(line); ok {
+		line = h.Text
+	}
+	line = listMarkerPattern.ReplaceAllString(line, "")
+	line = inlineLinkPattern.ReplaceAllString(line, "$2")
+	line = wikilinkPattern.ReplaceAllStringFunc(line, func(match string) string {
+		inner := strings.Trim(match, "[]")
+		if _, alias, ok := strings.Cut(inner, "|"); ok {
+			return alias
+		}
+		return inner
+	})
+	return line
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"plain text", "one two three", 3},
+		{"heading markers", "# Title Here\n## Sub", 3},
+		{"list bullets", "- one\n* two\n+ three\n1. four", 4},
+		{"link targets", "see [the docs](https://example.com/docs) now", 4},
+		{"wikilink alias", "read [[Some Note|this]]", 2},
+		{"frontmatter excluded", "---\ntitle: Ignored\n---\nbody words", 2},
+		{"code fence lines", "```go\nfmt.Println()\n```", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countWords(tt.content); got != tt.want {
+				t.Errorf("countWords(%q) = %d, want %d", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleLinkDensity(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/density"}, MaxPageSize: DefaultMaxPageSize}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "link_density",
+			Arguments: map[string]any{},
+		},
+	}
+
+	result, err := handleLinkDensity(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool returned error: %v", result.Content)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected TextContent, got %T", result.Content[0])
+	}
+
+	var response struct {
+		Notes []linkDensity `json:"notes"`
+		Count int           `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+
+	wantOrder := []string{"index.md", "essay.md", "scratch.md"}
+	if response.Count != len(wantOrder) {
+		t.Fatalf("Expected %d notes, got %d", len(wantOrder), response.Count)
+	}
+
+	for i, name := range wantOrder {
+		if response.Notes[i].Name != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, response.Notes[i].Name)
+		}
+	}
+
+	index := response.Notes[0]
+	if index.Links != 4 || index.Words != 5 {
+		t.Errorf("Expected index.md to have 4 links and 5 words, got %+v", index)
+	}
+
+	if response.Notes[2].Density != 0 {
+		t.Errorf("Expected zero density for scratch.md, got %v", response.Notes[2].Density)
+	}
+}
+
+func TestHandleFileStats(t *testing.T) {
+	// Setup test environment
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{Directories: []string{"test/stats"}, MaxPageSize: DefaultMaxPageSize}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantError bool
+		want      fileStats
+	}{
+		{
+			name:     "headings and lists",
+			filename: "weekly",
+			want:     fileStats{Name: "weekly.md", WordCount: 10, LineCount: 6, CharCount: 62, ReadingMinutes: 1},
+		},
+		{
+			name:      "missing file",
+			filename:  "missing",
+			wantError: true,
+		},
+		{
+			name:      "directory traversal",
+			filename:  "../dir1/foo",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "file_stats",
+					Arguments: map[string]any{"filename": tt.filename},
+				},
+			}
+
+			result, err := handleFileStats(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantError {
+				if !result.IsError {
+					t.Error("Expected tool error but got none")
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("Tool returned error: %v", result.Content)
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", result.Content[0])
+			}
+
+			var stats fileStats
+			if err := json.Unmarshal([]byte(textContent.Text), &stats); err != nil {
+				t.Fatalf("Failed to parse JSON response: %v", err)
+			}
+
+			if stats != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, stats)
+			}
+		})
+	}
+}
+
+func TestMeasureFileStatsReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  int
+	}{
+		{0, 0},
+		{1, 1},
+		{200, 1},
+		{201, 2},
+	}
+
+	for _, tt := range tests {
+		content := strings.TrimSpace(strings.Repeat("word ", tt.words))
+		if got := measureFileStats("note.md", content).ReadingMinutes; got != tt.want {
+			t.Errorf("Expected %d reading minutes for %d words, got %d", tt.want, tt.words, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxSuggestions is how many file names are suggested when a find query
+// matches no files, and the most that can be configured
+const DefaultMaxSuggestions = 5
+
+// maxSuggestions returns how many names to suggest for a query without matches,
+// 0 when suggestions are disabled
+func maxSuggestions() int {
+	switch {
+	case config.MaxSuggestions < 0:
+		return 0
+	case config.MaxSuggestions == 0:
+		return DefaultMaxSuggestions
+	default:
+		return min(config.MaxSuggestions, DefaultMaxSuggestions)
+	}
+}
+
+// suggestNames returns the names of the files closest to a query that matched
+// none of them, by edit distance ignoring case and the extension, so a client
+// can ask "did you mean". Names sharing too little with the query to be a
+// plausible misspelling, differing in more than half their characters, are left
+// out, so there may be fewer than limit suggestions or none.
+func suggestNames(query string, files []indexedFile, limit int) []string {
+	if query == "" || limit <= 0 {
+		return nil
+	}
+	queryRunes := []rune(strings.ToLower(query))
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var suggestions []suggestion
+	seen := map[string]bool{}
+	for _, file := range files {
+		name := markdownName(file.Path)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		lower := strings.ToLower(name)
+		stem := []rune(strings.TrimSuffix(lower, filepath.Ext(lower)))
+		distance := min(editDistance(queryRunes, stem), editDistance(queryRunes, []rune(lower)))
+		if distance*2 > max(len(queryRunes), len(stem)) {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{name: name, distance: distance})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+
+	names := make([]string, 0, min(len(suggestions), limit))
+	for _, suggestion := range suggestions[:min(len(suggestions), limit)] {
+		names = append(names, suggestion.name)
+	}
+	return names
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkSymlink returns an error if path is a symlink that may not be served:
+// symlinks are only followed when follow_symlinks is set, and then only to
+// regular files whose real path is within a configured directory
+func (c *Config) checkSymlink(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	if !c.FollowSymlinks {
+		return fmt.Errorf("file %s is a symlink and follow_symlinks is not enabled", filepath.Base(path))
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("could not resolve symlink %s: %v", filepath.Base(path), err)
+	}
+
+	realInfo, err := os.Stat(realPath)
+	if err != nil {
+		return fmt.Errorf("could not resolve symlink %s: %v", filepath.Base(path), err)
+	}
+	if !realInfo.Mode().IsRegular() {
+		return fmt.Errorf("symlink %s does not point to a file", filepath.Base(path))
+	}
+
+	if !c.isRealPathWithinConfiguredDirs(realPath) {
+		return fmt.Errorf("symlink %s points outside the configured directories", filepath.Base(path))
+	}
+
+	return nil
+}
+
+// isRealPathWithinConfiguredDirs reports whether a path with symlinks resolved is
+// within the real path of one of the configured directories
+func (c *Config) isRealPathWithinConfiguredDirs(realPath string) bool {
+	for _, dir := range c.Directories {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		realDir, err := filepath.EvalSymlinks(absDir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(realDir, realPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolvesWithinConfiguredDirs reports whether a path, with the symlinks along it
+// resolved, is within one of the configured directories, so a path through a
+// symlinked directory pointing elsewhere is caught though it looks local. A path
+// that doesn't exist is judged by its nearest existing parent.
+func resolvesWithinConfiguredDirs(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for {
+		if realPath, err := filepath.EvalSymlinks(absPath); err == nil {
+			return config.isRealPathWithinConfiguredDirs(realPath)
+		}
+		parent := filepath.Dir(absPath)
+		if parent == absPath {
+			return false
+		}
+		absPath = parent
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSymlinksOutsideConfiguredDirs(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+		markdownIndex.invalidate()
+	}()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(root, "note.md"), []byte("# Note\n"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.md"), []byte("# Secret\n"), 0644); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.md"), filepath.Join(root, "escape.md")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "note.md"), filepath.Join(root, "alias.md")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		wantFiles      []string
+		readable       map[string]bool
+	}{
+		{
+			name:      "symlinks skipped by default",
+			wantFiles: []string{"note.md"},
+			readable:  map[string]bool{"note.md": true, "alias.md": false, "escape.md": false},
+		},
+		{
+			// The alias is the same file as note.md so it is only listed once
+			name:           "symlinks within the root followed when enabled",
+			followSymlinks: true,
+			wantFiles:      []string{"note.md"},
+			readable:       map[string]bool{"note.md": true, "alias.md": true, "escape.md": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config = Config{
+				Directories:    []string{root},
+				MaxPageSize:    DefaultMaxPageSize,
+				Extensions:     DefaultExtensions,
+				FollowSymlinks: tt.followSymlinks,
+			}
+			markdownIndex.invalidate()
+
+			files, err := findMarkdownFiles("", DefaultMaxPageSize)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.wantFiles) {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, names)
+			}
+
+			for filename, wantReadable := range tt.readable {
+				req := mcp.ReadResourceRequest{
+					Params: mcp.ReadResourceParams{URI: "file://" + filename},
+				}
+				_, err := handleReadMarkdownFileResource(context.Background(), req)
+				if wantReadable && err != nil {
+					t.Errorf("Expected %s to be readable, got %v", filename, err)
+				}
+				if !wantReadable && err == nil {
+					t.Errorf("Expected reading %s to fail", filename)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSymlinkRejectsEscapingTarget(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.md"), []byte("# Secret\n"), 0644); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	link := filepath.Join(root, "escape.md")
+	if err := os.Symlink(filepath.Join(outside, "secret.md"), link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	config = Config{Directories: []string{root}, FollowSymlinks: true}
+	if err := config.checkSymlink(link); err == nil {
+		t.Error("Expected symlink pointing outside the root to be rejected")
+	}
+}
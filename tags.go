@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// hashtagPattern matches an inline #tag such as #project or #project/subproject.
+// The # must not follow a character that makes it part of something else, such
+// as a word or URL (page#section, /#anchor), a link target ((#setup)) or an HTML
+// entity (&#39;). A # followed by a space starts a heading rather than a tag.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#/(\[])#([\p{L}\p{N}_/-]+)`)
+
+// urlPattern matches bare URLs, whose fragments and paths may hold a # that isn't a tag
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+`)
+
+// hashtag is an inline #tag of a document, without the #
+type hashtag struct {
+	Tag  string
+	Line int
+}
+
+// fileTag is a distinct inline tag of a file with the lines it is used on
+type fileTag struct {
+	Tag   string `json:"tag"`
+	Lines []int  `json:"lines"`
+}
+
+// tagCount is how often a tag is used across the markdown files
+type tagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+	Files int    `json:"files"`
+}
+
+func handleExtractTags(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("extract_tags called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("extract_tags failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	_, body, bodyLine := splitFrontmatter(content)
+	tags := distinctHashtags(inlineHashtags(body, bodyLine))
+
+	result := map[string]any{
+		"tags":  tags,
+		"count": len(tags),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("extract_tags failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal tags: %w", err)), nil
+	}
+
+	logger.Debug("extract_tags completed successfully", "tags_found", len(tags))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func handleTagCloud(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	frontmatterOnly := extractBoolParam(req.Params.Arguments, "frontmatter_only")
+
+	log := requestLogger()
+	log.Debug("tag_cloud called", "frontmatter_only", frontmatterOnly)
+
+	tags, truncated := collectTagCloud(ctx, !frontmatterOnly, maxPageSize())
+
+	result := map[string]any{
+		"tags":      tags,
+		"count":     len(tags),
+		"truncated": truncated,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("tag_cloud failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal tags: %w", err)), nil
+	}
+
+	log.Debug("tag_cloud completed successfully", "tags", len(tags), "truncated", truncated)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// collectTagCloud counts the tags of every markdown file, from the tags field of
+// the frontmatter and, when inline is set, the #hashtags of the body. Tags are
+// compared ignoring case and returned most used first, at most maxTags of them,
+// truncated reporting whether any were left out. Counting inline tags reads
+// every file, so it is as costly as a content search of the whole vault.
+func collectTagCloud(ctx context.Context, inline bool, maxTags int) ([]tagCount, bool) {
+	counts := map[string]*tagCount{}
+	for _, file := range collectAllMarkdownFiles() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		content, err := readTagSource(file, inline)
+		if err != nil {
+			logger.Debug("tag_cloud could not read file", "file", file, "error", err)
+			continue
+		}
+
+		tags := fileTags(content, inline)
+		seen := map[string]bool{}
+		for _, tag := range tags {
+			tag = strings.ToLower(tag)
+			count, ok := counts[tag]
+			if !ok {
+				count = &tagCount{Tag: tag}
+				counts[tag] = count
+			}
+			count.Count++
+			if !seen[tag] {
+				seen[tag] = true
+				count.Files++
+			}
+		}
+	}
+
+	cloud := make([]tagCount, 0, len(counts))
+	for _, count := range counts {
+		cloud = append(cloud, *count)
+	}
+	sort.Slice(cloud, func(i, j int) bool {
+		if cloud[i].Count != cloud[j].Count {
+			return cloud[i].Count > cloud[j].Count
+		}
+		return cloud[i].Tag < cloud[j].Tag
+	})
+
+	if len(cloud) > maxTags {
+		return cloud[:maxTags], true
+	}
+	return cloud, false
+}
+
+// readTagSource reads the part of a file holding its tags, just the frontmatter
+// head when inline hashtags aren't wanted
+func readTagSource(path string, inline bool) (string, error) {
+	if !inline {
+		return readFrontmatterHead(path)
+	}
+	return fileContent(path)
+}
+
+// fileTags returns every tag used by a document, those of the frontmatter tags
+// field followed by the inline #hashtags of the body when inline is set
+func fileTags(content string, inline bool) []string {
+	frontmatter, body, bodyLine := splitFrontmatter(content)
+
+	var tags []string
+	if frontmatter != "" {
+		if data, err := parseFrontmatter(content); err == nil {
+			tags = append(tags, frontmatterTags(data["tags"])...)
+		}
+	}
+	if inline {
+		for _, tag := range inlineHashtags(body, bodyLine) {
+			tags = append(tags, tag.Tag)
+		}
+	}
+	return tags
+}
+
+// frontmatterTags returns the tags of a frontmatter tags field, either a list or
+// a string of tags separated by commas or spaces. A leading # is dropped.
+func frontmatterTags(value any) []string {
+	var raw []string
+	switch value := value.(type) {
+	case string:
+		raw = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		for _, item := range value {
+			if item != nil {
+				raw = append(raw, fmt.Sprint(item))
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// inlineHashtags returns the #hashtags of markdown content in order, with their
+// 1-based line numbers counted from firstLine. Fenced code blocks, code spans and
+// URLs are skipped, as are tags made only of digits such as issue numbers (#123).
+func inlineHashtags(content string, firstLine int) []hashtag {
+	var tags []hashtag
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		text := urlPattern.ReplaceAllString(withoutCodeSpans(line), " ")
+		for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+			tag := strings.TrimRight(match[1], "/")
+			if strings.Trim(tag, "0123456789") == "" {
+				continue
+			}
+			tags = append(tags, hashtag{Tag: tag, Line: firstLine + i})
+		}
+	}
+	return tags
+}
+
+// distinctHashtags groups hashtags by tag, compared ignoring case, in the order
+// each tag is first used
+func distinctHashtags(hashtags []hashtag) []fileTag {
+	tags := []fileTag{}
+	index := map[string]int{}
+	for _, hashtag := range hashtags {
+		tag := strings.ToLower(hashtag.Tag)
+		i, ok := index[tag]
+		if !ok {
+			i = len(tags)
+			index[tag] = i
+			tags = append(tags, fileTag{Tag: tag})
+		}
+		if lines := tags[i].Lines; len(lines) == 0 || lines[len(lines)-1] != hashtag.Line {
+			tags[i].Lines = append(lines, hashtag.Line)
+		}
+	}
+	return tags
+}
+
+// withoutCodeSpans blanks out the code spans of a line, keeping the text around them
+func withoutCodeSpans(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			b.WriteByte(line[i])
+			i++
+			continue
+		}
+
+		run := i
+		for run < len(line) && line[run] == '`' {
+			run++
+		}
+		ticks := line[i:run]
+		end := strings.Index(line[run:], ticks)
+		if end < 0 {
+			b.WriteString(ticks)
+			i = run
+			continue
+		}
+		b.WriteByte(' ')
+		i = run + end + len(ticks)
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleTagCloud(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		args          map[string]any
+		wantTags      []tagCount
+		wantTruncated bool
+	}{
+		{
+			name:   "frontmatter and inline tags",
+			config: Config{Directories: []string{"test/tags"}},
+			wantTags: []tagCount{
+				{Tag: "project", Count: 3, Files: 2},
+				{Tag: "journal", Count: 2, Files: 1},
+				{Tag: "work", Count: 2, Files: 2},
+				{Tag: "retired", Count: 1, Files: 1},
+				{Tag: "work/planning", Count: 1, Files: 1},
+			},
+		},
+		{
+			name:   "frontmatter only",
+			config: Config{Directories: []string{"test/tags"}},
+			args:   map[string]any{"frontmatter_only": true},
+			wantTags: []tagCount{
+				{Tag: "work", Count: 2, Files: 2},
+				{Tag: "journal", Count: 1, Files: 1},
+				{Tag: "project", Count: 1, Files: 1},
+			},
+		},
+		{
+			name:   "ignored directories are skipped",
+			config: Config{Directories: []string{"test/tags"}, IgnoreDirs: []string{"archive"}},
+			wantTags: []tagCount{
+				{Tag: "project", Count: 3, Files: 2},
+				{Tag: "journal", Count: 2, Files: 1},
+				{Tag: "work", Count: 2, Files: 2},
+				{Tag: "work/planning", Count: 1, Files: 1},
+			},
+		},
+		{
+			name:   "truncated at max page size",
+			config: Config{Directories: []string{"test/tags"}, MaxPageSize: 2},
+			wantTags: []tagCount{
+				{Tag: "project", Count: 3, Files: 2},
+				{Tag: "journal", Count: 2, Files: 1},
+			},
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, tt.config)
+
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+			result, err := handleTagCloud(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("Unexpected tool error: %v", result.Content)
+			}
+
+			var response struct {
+				Tags      []tagCount `json:"tags"`
+				Count     int        `json:"count"`
+				Truncated bool       `json:"truncated"`
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+
+			if !reflect.DeepEqual(response.Tags, tt.wantTags) {
+				t.Errorf("Expected tags %+v, got %+v", tt.wantTags, response.Tags)
+			}
+			if response.Count != len(tt.wantTags) {
+				t.Errorf("Expected count %d, got %d", len(tt.wantTags), response.Count)
+			}
+			if response.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, response.Truncated)
+			}
+		})
+	}
+}
+
+func TestInlineHashtags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "hashtag", content: "Filed under #project", want: []string{"project"}},
+		{name: "nested hashtag", content: "See #project/subproject/task.", want: []string{"project/subproject/task"}},
+		{name: "trailing slash dropped", content: "#project/ next", want: []string{"project"}},
+		{name: "heading", content: "# Heading\n## Subheading", want: nil},
+		{name: "hashtag in heading text", content: "## Plans #draft", want: []string{"draft"}},
+		{name: "fenced code block", content: "```\n#include <stdio.h>\n```\n#after", want: []string{"after"}},
+		{name: "code span", content: "Run `ls #all` with #shell", want: []string{"shell"}},
+		{name: "url fragment", content: "https://example.com/page#section and http://x.org/?q=#a", want: nil},
+		{name: "link fragment", content: "[setup](#setup) and [[note#heading]]", want: nil},
+		{name: "issue number", content: "Fixes #123", want: nil},
+		{name: "html entity", content: "It&#39;s fine", want: nil},
+		{name: "word with hash", content: "C# and F#", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, tag := range inlineHashtags(tt.content, 1) {
+				got = append(got, tag.Tag)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected tags %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleExtractTags(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/hashtags"}})
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"filename": "notes"}}}
+	result, err := handleExtractTags(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %v", result.Content)
+	}
+
+	var response struct {
+		Tags  []fileTag `json:"tags"`
+		Count int       `json:"count"`
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+
+	want := []fileTag{
+		{Tag: "heading-tag", Lines: []int{4}},
+		{Tag: "project/subproject", Lines: []int{6, 14}},
+		{Tag: "review", Lines: []int{14}},
+	}
+	if !reflect.DeepEqual(response.Tags, want) {
+		t.Errorf("Expected tags %+v, got %+v", want, response.Tags)
+	}
+	if response.Count != len(want) {
+		t.Errorf("Expected count %d, got %d", len(want), response.Count)
+	}
+}
+
+func TestHandleExtractTagsMissingFilename(t *testing.T) {
+	setupFileIndexTest(t, Config{Directories: []string{"test/hashtags"}})
+
+	result, err := handleExtractTags(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("Expected tool error for missing filename")
+	}
+}
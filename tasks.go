@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// taskPattern matches a task list item, e.g. "- [ ] todo" or "  * [x] done"
+var taskPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+type task struct {
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+	Line      int    `json:"line"`
+}
+
+// fileTasks are the open tasks of one file
+type fileTasks struct {
+	Name  string `json:"name"`
+	Tasks []task `json:"tasks"`
+}
+
+func handleListTasks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filename := extractStringParam(req.Params.Arguments, "filename")
+
+	logger.Debug("list_tasks called", "filename", filename)
+
+	if filename == "" {
+		return toolErrorResult(errorWithCode(ErrorCodeInvalidArgument, "missing required parameter: filename")), nil
+	}
+
+	content, err := readMarkdownFile(filename)
+	if err != nil {
+		logger.Debug("list_tasks failed", "error", err)
+		return toolErrorResult(err), nil
+	}
+
+	tasks := parseTasks(content)
+
+	result := map[string]any{
+		"tasks": tasks,
+		"count": len(tasks),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.Debug("list_tasks failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal tasks: %w", err)), nil
+	}
+
+	logger.Debug("list_tasks completed successfully", "tasks_found", len(tasks))
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func handleOpenTasks(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := extractQueryParam(req.Params.Arguments)
+
+	log := requestLogger()
+	log.Debug("open_tasks called", "query", query)
+
+	maxTasks := config.MaxPageSize
+	if maxTasks <= 0 {
+		maxTasks = DefaultMaxPageSize
+	}
+
+	files, count, truncated := collectOpenTasks(query, maxTasks)
+
+	result := map[string]any{
+		"files":     files,
+		"count":     count,
+		"truncated": truncated,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Debug("open_tasks failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal tasks: %w", err)), nil
+	}
+
+	log.Debug("open_tasks completed successfully", "files", len(files), "tasks", count)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// collectOpenTasks gathers the incomplete tasks of every markdown file whose name
+// contains the query, grouped by file. At most maxTasks are returned, truncated
+// reporting whether any were left out.
+func collectOpenTasks(query string, maxTasks int) (files []fileTasks, count int, truncated bool) {
+	queryLower := strings.ToLower(query)
+
+	files = []fileTasks{}
+	for _, file := range collectAllMarkdownFiles() {
+		if query != "" && !strings.Contains(strings.ToLower(markdownName(file)), queryLower) {
+			continue
+		}
+
+		content, err := fileContent(file)
+		if err != nil {
+			logger.Debug("open_tasks could not read file", "file", file, "error", err)
+			continue
+		}
+
+		var open []task
+		for _, t := range parseTasks(content) {
+			if t.Completed {
+				continue
+			}
+			if count == maxTasks {
+				truncated = true
+				break
+			}
+			open = append(open, t)
+			count++
+		}
+
+		if len(open) > 0 {
+			files = append(files, fileTasks{Name: markdownName(file), Tasks: open})
+		}
+		if truncated {
+			break
+		}
+	}
+
+	return files, count, truncated
+}
+
+// parseTasks scans markdown content for task list items, skipping any lines
+// inside fenced code blocks. Line numbers are 1-based.
+func parseTasks(content string) []task {
+	tasks := []task{}
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if isFenceDelimiter(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if match := taskPattern.FindStringSubmatch(line); match != nil {
+			tasks = append(tasks, task{
+				Text:      strings.TrimSpace(match[2]),
+				Completed: match[1] != " ",
+				Line:      i + 1,
+			})
+		}
+	}
+
+	return tasks
+}
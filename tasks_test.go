@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseTasks(t *testing.T) {
+	content, err := os.ReadFile("test/tasks/project.md")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	want := []task{
+		{Text: "Write the report", Completed: false, Line: 3},
+		{Text: "Book the venue", Completed: true, Line: 4},
+		{Text: "Confirm catering", Completed: true, Line: 5},
+		{Text: "Send invites", Completed: false, Line: 6},
+		{Text: "Review budget", Completed: false, Line: 15},
+	}
+
+	if got := parseTasks(string(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected tasks %+v, got %+v", want, got)
+	}
+}
+
+func TestHandleListTasks(t *testing.T) {
+	oldConfig := config
+	oldLogger := logger
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	config = Config{
+		Directories: []string{"test/tasks"},
+		MaxPageSize: DefaultMaxPageSize,
+	}
+	defer func() {
+		config = oldConfig
+		logger = oldLogger
+	}()
+
+	tests := []struct {
+		name      string
+		filename  string
+		wantError bool
+		wantCount int
+	}{
+		{name: "file with tasks", filename: "project", wantCount: 5},
+		{name: "other file with tasks", filename: "home", wantCount: 3},
+		{name: "missing filename", filename: "", wantError: true},
+		{name: "non-existent file", filename: "nonexistent.md", wantError: true},
+		{name: "directory traversal attempt", filename: "../dir1/foo.md", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"filename": tt.filename}
+			result, err := handleListTasks(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Fatalf("Expected IsError %v, got %v", tt.wantError, result.IsError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			var response struct {
+				Tasks []task `json:"tasks"`
+				Count int    `json:"count"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Count != tt.wantCount || len(response.Tasks) != tt.wantCount {
+				t.Errorf("Expected %d tasks, got %+v", tt.wantCount, response)
+			}
+		})
+	}
+}
+
+func TestCollectOpenTasks(t *testing.T) {
+	setupFileIndexTest(t, Config{
+		Directories: []string{"test/tasks"},
+		MaxPageSize: DefaultMaxPageSize,
+		IgnoreDirs:  []string{`^archive$`},
+	})
+
+	tests := []struct {
+		name          string
+		query         string
+		maxTasks      int
+		wantFiles     []fileTasks
+		wantCount     int
+		wantTruncated bool
+	}{
+		{
+			name:     "all open tasks",
+			maxTasks: DefaultMaxPageSize,
+			wantFiles: []fileTasks{
+				{Name: "home.md", Tasks: []task{{Text: "Paint the fence", Line: 4}}},
+				{Name: "project.md", Tasks: []task{
+					{Text: "Write the report", Line: 3},
+					{Text: "Send invites", Line: 6},
+					{Text: "Review budget", Line: 15},
+				}},
+			},
+			wantCount: 4,
+		},
+		{
+			name:     "files matching query",
+			query:    "PROJ",
+			maxTasks: DefaultMaxPageSize,
+			wantFiles: []fileTasks{
+				{Name: "project.md", Tasks: []task{
+					{Text: "Write the report", Line: 3},
+					{Text: "Send invites", Line: 6},
+					{Text: "Review budget", Line: 15},
+				}},
+			},
+			wantCount: 3,
+		},
+		{
+			name:     "capped",
+			maxTasks: 2,
+			wantFiles: []fileTasks{
+				{Name: "home.md", Tasks: []task{{Text: "Paint the fence", Line: 4}}},
+				{Name: "project.md", Tasks: []task{{Text: "Write the report", Line: 3}}},
+			},
+			wantCount:     2,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, count, truncated := collectOpenTasks(tt.query, tt.maxTasks)
+			slices.SortFunc(files, func(a, b fileTasks) int { return strings.Compare(a.Name, b.Name) })
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("Expected files %+v, got %+v", tt.wantFiles, files)
+			}
+			if count != tt.wantCount {
+				t.Errorf("Expected %d tasks, got %d", tt.wantCount, count)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// vaultStats describes the shape of the markdown files in the configured directories
+type vaultStats struct {
+	Files        int              `json:"files"`
+	TotalBytes   int64            `json:"total_bytes"`
+	AverageBytes int64            `json:"average_bytes"`
+	LargestFile  string           `json:"largest_file,omitempty"`
+	LargestBytes int64            `json:"largest_bytes"`
+	Directories  []directoryStats `json:"directories"`
+	Truncated    bool             `json:"truncated,omitempty"`
+}
+
+// directoryStats counts the markdown files of a configured directory, with the
+// files directly in it counted under "."
+type directoryStats struct {
+	Directory      string         `json:"directory"`
+	Files          int            `json:"files"`
+	TotalBytes     int64          `json:"total_bytes"`
+	Subdirectories map[string]int `json:"subdirectories"`
+}
+
+func handleVaultStats(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log := requestLogger()
+	log.Debug("vault_stats called")
+
+	files, status := markdownIndex.entriesContext(ctx)
+	stats := computeVaultStats(files)
+	stats.Truncated = status.Truncated
+
+	jsonData, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		log.Debug("vault_stats failed to marshal JSON", "error", err)
+		return toolErrorResult(fmt.Errorf("failed to marshal vault stats: %w", err)), nil
+	}
+
+	log.Debug("vault_stats completed successfully", "files", stats.Files, "total_bytes", stats.TotalBytes)
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// computeVaultStats totals the sizes recorded for the indexed files, so no file
+// is opened and, while the file index is fresh, no directory is walked either.
+// Files are counted under the top-level subdirectory of their configured
+// directory they are in.
+func computeVaultStats(files []indexedFile) vaultStats {
+	stats := vaultStats{Directories: []directoryStats{}}
+	byDirectory := map[string]*directoryStats{}
+	for _, dir := range config.Directories {
+		byDirectory[dir] = &directoryStats{Directory: config.configuredDirectoryName(dir), Subdirectories: map[string]int{}}
+	}
+
+	for _, file := range files {
+		stats.Files++
+		stats.TotalBytes += file.Size
+		if stats.LargestFile == "" || file.Size > stats.LargestBytes {
+			stats.LargestFile = markdownName(file.Path)
+			stats.LargestBytes = file.Size
+		}
+
+		dirStats, ok := byDirectory[file.Dir]
+		if !ok {
+			continue
+		}
+		dirStats.Files++
+		dirStats.TotalBytes += file.Size
+		subdirectory := "."
+		if top, _, nested := strings.Cut(relativeToDirectory(file.Dir, file.Path), "/"); nested {
+			subdirectory = top
+		}
+		dirStats.Subdirectories[subdirectory]++
+	}
+
+	if stats.Files > 0 {
+		stats.AverageBytes = stats.TotalBytes / int64(stats.Files)
+	}
+	for _, dir := range config.Directories {
+		if dirStats, ok := byDirectory[dir]; ok {
+			stats.Directories = append(stats.Directories, *dirStats)
+			delete(byDirectory, dir)
+		}
+	}
+	return stats
+}
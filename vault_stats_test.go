@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleVaultStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   vaultStats
+	}{
+		{
+			name:   "totals of a directory",
+			config: Config{Directories: []string{"test/dir1"}},
+			want: vaultStats{
+				Files:        4,
+				TotalBytes:   452,
+				AverageBytes: 113,
+				LargestFile:  "README.md",
+				LargestBytes: 365,
+				Directories: []directoryStats{{
+					Directory:      "test/dir1",
+					Files:          4,
+					TotalBytes:     452,
+					Subdirectories: map[string]int{".": 2, "child": 1, "nested": 1},
+				}},
+			},
+		},
+		{
+			name:   "ignored directories are left out",
+			config: Config{Directories: []string{"test/dir1"}, IgnoreDirs: []string{"^nested$"}},
+			want: vaultStats{
+				Files:        3,
+				TotalBytes:   423,
+				AverageBytes: 141,
+				LargestFile:  "README.md",
+				LargestBytes: 365,
+				Directories: []directoryStats{{
+					Directory:      "test/dir1",
+					Files:          3,
+					TotalBytes:     423,
+					Subdirectories: map[string]int{".": 2, "child": 1},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFileIndexTest(t, tt.config)
+
+			result, err := handleVaultStats(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var stats vaultStats
+			text := result.Content[0].(mcp.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &stats); err != nil {
+				t.Fatalf("Failed to parse result: %v", err)
+			}
+
+			if !reflect.DeepEqual(stats, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, stats)
+			}
+		})
+	}
+}